@@ -4,8 +4,8 @@ import "time"
 
 type Address struct {
 	ID        int64		`gorm:"primary_key;autoIncrement;column:id"`
-	UserId    string	`gorm:"column:user_id"`
-	Address   string	`gorm:"column:address"`
+	UserId    string	`gorm:"column:user_id;size:100"`
+	Address   string	`gorm:"column:address;size:100"`
 	CreatedAt time.Time	`gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt time.Time	`gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
 	User 	User		`gorm:"foreignKey:user_id;references:id"`
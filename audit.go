@@ -0,0 +1,413 @@
+package learn_golang_gorm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"learn-golang-gorm/password"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Aksi yang ditulis AuditPlugin ke UserLog.Action
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditSystemActor dipakai sebagai UserLog.UserId jika context tidak membawa user (misalnya seed atau cron job)
+const AuditSystemActor = "system"
+
+type auditActorKey struct{}
+
+// WithActor menandai ctx dengan user yang melakukan perubahan, dipakai lewat db.WithContext(ctx)
+func WithActor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, userID)
+}
+
+// ActorFromContext mengembalikan user yang disimpan oleh WithActor
+func ActorFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(auditActorKey{}).(string)
+	return userID, ok && userID != ""
+}
+
+// AuditedModels adalah model yang perubahannya dicatat oleh Open
+func AuditedModels() []interface{} {
+	return []interface{}{&User{}, &Wallet{}, &Address{}, &Product{}, &Todo{}}
+}
+
+// AuditPlugin adalah plugin GORM yang menulis satu UserLog untuk setiap baris yang dibuat, diubah
+// atau dihapus lewat Create, Save, Update(s) dan Delete pada model yang didaftarkan:
+//
+//	db.Use(NewAuditPlugin(&User{}, &Wallet{}))
+//	db.WithContext(WithActor(ctx, "1")).Model(&wallet).Update("balance_amount", 0)
+//
+// Changes berisi JSON {"kolom": {"old": ..., "new": ...}} hanya untuk kolom yang berubah.
+// Baris lama dibaca sebelum perubahan dan baris baru dibaca ulang sesudahnya, jadi perubahan lewat
+// gorm.Expr juga tercatat. Create dengan ON CONFLICT (upsert) yang mengenai baris lama dicatat sebagai
+// update, selama primary key-nya sudah diisi sebelum Create. Hash password diganti dengan [REDACTED].
+//
+// Log ditulis lewat koneksi yang sama dengan perubahannya, jadi ikut di-rollback jika dijalankan
+// di dalam db.Transaction. Di luar transaction (SkipDefaultTransaction aktif secara default), plugin
+// membuka transaction sendiri untuk Create / Update / Delete model yang didaftarkan: baris lama dibaca
+// dengan SELECT ... FOR UPDATE, lalu perubahan dan log-nya di-commit atau di-rollback bersama.
+// Exec / Raw dan tabel penghubung many2many tidak dicatat.
+type AuditPlugin struct {
+	models map[reflect.Type]bool
+}
+
+func NewAuditPlugin(models ...interface{}) *AuditPlugin {
+	p := &AuditPlugin{models: map[reflect.Type]bool{}}
+	for _, model := range models {
+		p.models[reflect.Indirect(reflect.ValueOf(model)).Type()] = true
+	}
+	return p
+}
+
+func (p *AuditPlugin) Name() string {
+	return "audit"
+}
+
+func (p *AuditPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:before_create").Register("audit:begin_transaction", p.begin),
+		callback.Create().After("audit:after_create").Register("audit:commit_or_rollback_transaction", p.commitOrRollback),
+		callback.Update().Before("gorm:before_update").Register("audit:begin_transaction", p.begin),
+		callback.Update().After("audit:after_update").Register("audit:commit_or_rollback_transaction", p.commitOrRollback),
+		callback.Delete().Before("gorm:before_delete").Register("audit:begin_transaction", p.begin),
+		callback.Delete().After("audit:after_delete").Register("audit:commit_or_rollback_transaction", p.commitOrRollback),
+		callback.Create().Before("gorm:create").Register("audit:before_create", p.beforeCreate),
+		callback.Create().After("gorm:create").Register("audit:after_create", p.afterCreate),
+		callback.Update().Before("gorm:update").Register("audit:before_update", p.before),
+		callback.Update().After("gorm:update").Register("audit:after_update", p.afterUpdate),
+		callback.Delete().Before("gorm:delete").Register("audit:before_delete", p.before),
+		callback.Delete().After("gorm:delete").Register("audit:after_delete", p.afterDelete),
+	)
+}
+
+const (
+	auditOldRowsKey            = "audit:old_rows"
+	auditStartedTransactionKey = "audit:started_transaction"
+)
+
+type auditRow = map[string]interface{}
+
+func (p *AuditPlugin) audited(db *gorm.DB) bool {
+	stmt := db.Statement
+	return db.Error == nil && !stmt.DryRun && stmt.Schema != nil &&
+		len(stmt.Schema.PrimaryFields) > 0 && p.models[stmt.Schema.ModelType]
+}
+
+// begin membuka transaction jika statement belum berada di dalam transaction (db.Transaction atau uow),
+// sama seperti default transaction GORM yang dimatikan oleh SkipDefaultTransaction
+func (p *AuditPlugin) begin(db *gorm.DB) {
+	if !p.audited(db) {
+		return
+	}
+	switch tx := db.Begin(); {
+	case tx.Error == nil:
+		db.Statement.ConnPool = tx.Statement.ConnPool
+		db.InstanceSet(auditStartedTransactionKey, true)
+	case !errors.Is(tx.Error, gorm.ErrInvalidTransaction): // ErrInvalidTransaction: sudah di dalam transaction
+		db.AddError(fmt.Errorf("audit: %w", tx.Error))
+	}
+}
+
+// commitOrRollback menutup transaction dari begin: perubahan tanpa log (atau sebaliknya) tidak pernah tersimpan
+func (p *AuditPlugin) commitOrRollback(db *gorm.DB) {
+	if _, ok := db.InstanceGet(auditStartedTransactionKey); !ok {
+		return
+	}
+	if db.Error != nil {
+		db.Rollback()
+	} else {
+		db.Commit()
+	}
+	db.Statement.ConnPool = db.ConnPool
+}
+
+// before menyimpan baris yang akan diubah / dihapus, kondisinya sama dengan statement-nya
+func (p *AuditPlugin) before(db *gorm.DB) {
+	if !p.audited(db) {
+		return
+	}
+	stmt := db.Statement
+	var conditions []clause.Expression
+	if condition := recordsCondition(stmt); condition != nil {
+		conditions = append(conditions, condition)
+	}
+	if where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+		conditions = append(conditions, clause.And(where.Exprs...))
+	}
+	if len(conditions) == 0 && !stmt.AllowGlobalUpdate {
+		return // akan ditolak GORM dengan ErrMissingWhereClause
+	}
+
+	// dikunci sampai transaction selesai, supaya nilai lama tidak diubah writer lain sebelum statement ini
+	rows, err := loadAuditRows(db, stmt.Unscoped, true, conditions...)
+	if err != nil {
+		db.AddError(fmt.Errorf("audit: %w", err))
+		return
+	}
+	db.InstanceSet(auditOldRowsKey, rows)
+}
+
+// beforeCreate menyimpan baris yang sudah ada untuk Create dengan ON CONFLICT (upsert),
+// supaya baris yang ternyata diubah dicatat sebagai update dengan nilai lamanya
+func (p *AuditPlugin) beforeCreate(db *gorm.DB) {
+	if !p.audited(db) {
+		return
+	}
+	if _, upsert := db.Statement.Clauses["ON CONFLICT"]; !upsert {
+		return
+	}
+	condition := recordsCondition(db.Statement)
+	if condition == nil {
+		return // primary key diisi database, jadi belum ada baris lama yang bisa dicari
+	}
+	rows, err := loadAuditRows(db, true, true, condition)
+	if err != nil {
+		db.AddError(fmt.Errorf("audit: %w", err))
+		return
+	}
+	db.InstanceSet(auditOldRowsKey, rows)
+}
+
+func (p *AuditPlugin) afterCreate(db *gorm.DB) {
+	if !p.audited(db) || db.Statement.RowsAffected == 0 {
+		return
+	}
+	condition := recordsCondition(db.Statement)
+	if condition == nil {
+		return
+	}
+	rows, err := loadAuditRows(db, true, false, condition)
+	if err != nil {
+		db.AddError(fmt.Errorf("audit: %w", err))
+		return
+	}
+	old := make(map[string]auditRow)
+	for _, row := range oldAuditRows(db) {
+		old[auditRecordID(db.Statement.Schema, row)] = row
+	}
+
+	var logs []UserLog
+	for _, row := range rows {
+		before, existed := old[auditRecordID(db.Statement.Schema, row)]
+		if !existed {
+			logs = append(logs, auditLog(db, AuditCreate, row, auditChanges(nil, row)))
+			continue
+		}
+		// upsert ke baris yang sudah ada, misalnya ON CONFLICT DO NOTHING tidak mengubah apa pun
+		if changes := auditChanges(before, row); !onlyUpdateTime(db.Statement.Schema, changes) {
+			logs = append(logs, auditLog(db, AuditUpdate, before, changes))
+		}
+	}
+	writeAuditLogs(db, logs)
+}
+
+func (p *AuditPlugin) afterUpdate(db *gorm.DB) {
+	old := oldAuditRows(db)
+	if !p.audited(db) || db.Statement.RowsAffected == 0 || len(old) == 0 {
+		return
+	}
+	rows, err := loadAuditRows(db, true, false, rowsCondition(db.Statement.Schema, old))
+	if err != nil {
+		db.AddError(fmt.Errorf("audit: %w", err))
+		return
+	}
+	updated := make(map[string]auditRow, len(rows))
+	for _, row := range rows {
+		updated[auditRecordID(db.Statement.Schema, row)] = row
+	}
+
+	var logs []UserLog
+	for _, before := range old {
+		after, ok := updated[auditRecordID(db.Statement.Schema, before)]
+		if !ok {
+			continue
+		}
+		if changes := auditChanges(before, after); !onlyUpdateTime(db.Statement.Schema, changes) {
+			logs = append(logs, auditLog(db, AuditUpdate, before, changes))
+		}
+	}
+	writeAuditLogs(db, logs)
+}
+
+// onlyUpdateTime bernilai true jika yang berubah hanya kolom autoUpdateTime (misalnya updated_at),
+// yaitu update dengan nilai yang sama seperti sebelumnya
+func onlyUpdateTime(s *schema.Schema, changes map[string]map[string]interface{}) bool {
+	for column := range changes {
+		if field := s.LookUpField(column); field == nil || field.AutoUpdateTime == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *AuditPlugin) afterDelete(db *gorm.DB) {
+	old := oldAuditRows(db)
+	if !p.audited(db) || db.Statement.RowsAffected == 0 {
+		return
+	}
+	var logs []UserLog
+	for _, row := range old {
+		logs = append(logs, auditLog(db, AuditDelete, row, auditChanges(row, nil)))
+	}
+	writeAuditLogs(db, logs)
+}
+
+func oldAuditRows(db *gorm.DB) []auditRow {
+	rows, _ := db.InstanceGet(auditOldRowsKey)
+	old, _ := rows.([]auditRow)
+	return old
+}
+
+// loadAuditRows membaca baris model statement ini lewat koneksi yang sama (ikut transaction-nya),
+// lock berarti SELECT ... FOR UPDATE (diabaikan oleh SQLite yang mengunci seluruh database)
+func loadAuditRows(db *gorm.DB, unscoped, lock bool, conditions ...clause.Expression) ([]auditRow, error) {
+	query := db.Session(&gorm.Session{NewDB: true}).Model(reflect.New(db.Statement.Schema.ModelType).Interface())
+	if unscoped {
+		query = query.Unscoped()
+	}
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	for _, condition := range conditions {
+		query = query.Where(condition)
+	}
+	var rows []auditRow
+	err := query.Find(&rows).Error
+	return rows, err
+}
+
+// recordsCondition membuat kondisi primary key dari struct / slice pada statement, nil jika primary key kosong
+func recordsCondition(stmt *gorm.Statement) clause.Expression {
+	var records []clause.Expression
+	add := func(value reflect.Value) {
+		var equals []clause.Expression
+		for _, field := range stmt.Schema.PrimaryFields {
+			v, zero := field.ValueOf(stmt.Context, value)
+			if zero {
+				return
+			}
+			equals = append(equals, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: v})
+		}
+		records = append(records, clause.And(equals...))
+	}
+
+	switch value := reflect.Indirect(stmt.ReflectValue); value.Kind() {
+	case reflect.Struct:
+		if value.Type() == stmt.Schema.ModelType {
+			add(value)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if item := reflect.Indirect(value.Index(i)); item.Type() == stmt.Schema.ModelType {
+				add(item)
+			}
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return clause.Or(records...)
+}
+
+// rowsCondition membuat kondisi primary key dari baris yang sudah dibaca
+func rowsCondition(s *schema.Schema, rows []auditRow) clause.Expression {
+	records := make([]clause.Expression, 0, len(rows))
+	for _, row := range rows {
+		equals := make([]clause.Expression, 0, len(s.PrimaryFields))
+		for _, field := range s.PrimaryFields {
+			equals = append(equals, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: row[field.DBName]})
+		}
+		records = append(records, clause.And(equals...))
+	}
+	return clause.Or(records...)
+}
+
+// auditRecordID adalah nilai primary key, dipisah koma untuk primary key gabungan
+func auditRecordID(s *schema.Schema, row auditRow) string {
+	ids := make([]string, 0, len(s.PrimaryFields))
+	for _, field := range s.PrimaryFields {
+		ids = append(ids, fmt.Sprint(auditValue(row[field.DBName])))
+	}
+	return strings.Join(ids, ",")
+}
+
+func auditValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return password.Redact(string(v))
+	case string:
+		return password.Redact(v)
+	}
+	return value
+}
+
+// auditChanges membandingkan dua baris, before nil untuk create dan after nil untuk delete
+func auditChanges(before, after auditRow) map[string]map[string]interface{} {
+	changes := map[string]map[string]interface{}{}
+	for column, value := range after {
+		old, existed := before[column]
+		if before != nil && existed && sameAuditValue(old, value) {
+			continue
+		}
+		change := map[string]interface{}{"new": auditValue(value)}
+		if before != nil {
+			change["old"] = auditValue(old)
+		}
+		changes[column] = change
+	}
+	if after == nil {
+		for column, value := range before {
+			changes[column] = map[string]interface{}{"old": auditValue(value)}
+		}
+	}
+	return changes
+}
+
+func sameAuditValue(a, b interface{}) bool {
+	x, errX := json.Marshal(auditValue(a))
+	y, errY := json.Marshal(auditValue(b))
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
+
+func auditLog(db *gorm.DB, action string, row auditRow, changes map[string]map[string]interface{}) UserLog {
+	actor, ok := ActorFromContext(db.Statement.Context)
+	if !ok {
+		actor = AuditSystemActor
+	}
+	log := UserLog{
+		UserId:   actor,
+		Action:   action,
+		Table:    db.Statement.Schema.Table,
+		RecordId: auditRecordID(db.Statement.Schema, row),
+	}
+	if data, err := json.Marshal(changes); err == nil {
+		text := string(data)
+		log.Changes = &text
+	} else {
+		db.AddError(fmt.Errorf("audit: %w", err))
+	}
+	return log
+}
+
+func writeAuditLogs(db *gorm.DB, logs []UserLog) {
+	if len(logs) == 0 || db.Error != nil {
+		return
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&logs).Error; err != nil {
+		db.AddError(fmt.Errorf("audit: %w", err))
+	}
+}
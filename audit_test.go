@@ -0,0 +1,173 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// auditLogs mengembalikan log milik actor, paling lama lebih dulu
+func auditLogs(t *testing.T, db *gorm.DB, actor string) []UserLog {
+	t.Helper()
+	var logs []UserLog
+	assert.Nil(t, db.Where("user_id = ?", actor).Order("id").Find(&logs).Error)
+	return logs
+}
+
+func auditChangesOf(t *testing.T, log UserLog) map[string]map[string]interface{} {
+	t.Helper()
+	var changes map[string]map[string]interface{}
+	assert.NotNil(t, log.Changes)
+	assert.Nil(t, json.Unmarshal([]byte(*log.Changes), &changes))
+	return changes
+}
+
+func TestAuditLog(t *testing.T) {
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	tx := db.WithContext(WithActor(context.Background(), "admin"))
+
+	// create: semua kolom baru, hash password disamarkan
+	user := User{ID: "50", Name: Name{FirstName: "Audit"}, Password: "rahasia"}
+	assert.Nil(t, tx.Create(&user).Error)
+	logs := auditLogs(t, db, "admin")
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, AuditCreate, logs[0].Action)
+	assert.Equal(t, "users", logs[0].Table)
+	assert.Equal(t, "50", logs[0].RecordId)
+	changes := auditChangesOf(t, logs[0])
+	assert.Equal(t, "Audit", changes["first_name"]["new"])
+	assert.Equal(t, "[REDACTED]", changes["password"]["new"])
+	assert.NotContains(t, changes["first_name"], "old")
+
+	// update lewat gorm.Expr: hanya kolom yang berubah, dengan nilai lama dan baru
+	wallet := Wallet{ID: "1"}
+	assert.Nil(t, tx.Model(&wallet).Update("balance_amount", gorm.Expr("balance_amount + ?", 500)).Error)
+	logs = auditLogs(t, db, "admin")
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, AuditUpdate, logs[1].Action)
+	assert.Equal(t, "wallets", logs[1].Table)
+	assert.Equal(t, "1", logs[1].RecordId)
+	changes = auditChangesOf(t, logs[1])
+	assert.Equal(t, float64(1000000), changes["balance_amount"]["old"])
+	assert.Equal(t, float64(1000500), changes["balance_amount"]["new"])
+	assert.NotContains(t, changes, "balance_currency")
+	assert.NotContains(t, changes, "user_id")
+
+	// update banyak baris dengan Where: satu log per baris
+	assert.Nil(t, tx.Model(&User{}).Where("id IN ?", []string{"2", "3"}).Update("last_name", "Updated").Error)
+	logs = auditLogs(t, db, "admin")
+	assert.Equal(t, 4, len(logs))
+	assert.Equal(t, []string{"2", "3"}, []string{logs[2].RecordId, logs[3].RecordId})
+	assert.Equal(t, "Updated", auditChangesOf(t, logs[3])["last_name"]["new"])
+
+	// delete: nilai terakhir baris yang dihapus
+	address := Address{UserId: "50", Address: "Jalan Audit"}
+	assert.Nil(t, tx.Create(&address).Error)
+	assert.Nil(t, tx.Delete(&address).Error)
+	logs = auditLogs(t, db, "admin")
+	assert.Equal(t, 6, len(logs))
+	assert.Equal(t, AuditDelete, logs[5].Action)
+	assert.Equal(t, "addresses", logs[5].Table)
+	assert.Equal(t, "Jalan Audit", auditChangesOf(t, logs[5])["address"]["old"])
+
+	// tanpa actor di context, perubahan dicatat sebagai system
+	assert.Nil(t, db.Model(&Wallet{ID: "2"}).Update("balance_amount", 0).Error)
+	var system UserLog
+	assert.Nil(t, db.Where("user_id = ? AND table_name = ? AND action = ?", AuditSystemActor, "wallets", AuditUpdate).Take(&system).Error)
+	assert.Equal(t, "2", system.RecordId)
+}
+
+func TestAuditLogSkipped(t *testing.T) {
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+	tx := db.WithContext(WithActor(context.Background(), "admin"))
+
+	// model yang tidak didaftarkan tidak dicatat
+	assert.Nil(t, tx.Create(&GuestBook{Name: "Guest", Email: "guest@example.com", Message: "Halo"}).Error)
+	// update yang tidak mengubah apa pun dan update tanpa baris yang cocok juga tidak dicatat
+	assert.Nil(t, tx.Model(&User{}).Where("id = ?", "1").Update("first_name", "Lev").Error)
+	assert.Nil(t, tx.Model(&User{}).Where("id = ?", "404").Update("first_name", "Nobody").Error)
+	assert.Equal(t, 0, len(auditLogs(t, db, "admin")))
+
+	// log ikut di-rollback bersama perubahannya
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&User{}).Where("id = ?", "1").Update("first_name", "Rolled").Error; err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(auditLogs(t, db, "admin")))
+}
+
+// tanpa transaction dari pemanggil, perubahan yang log-nya gagal ditulis ikut dibatalkan
+func TestAuditLogFailureRollsBackChange(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	address := Address{UserId: "1", Address: "Jalan Audit"}
+	assert.Nil(t, db.Create(&address).Error)
+	assert.Nil(t, db.Callback().Create().Before("gorm:create").Register("test:fail_user_logs", func(tx *gorm.DB) {
+		if tx.Statement.Table == "user_logs" {
+			tx.AddError(errors.New("user_logs unavailable"))
+		}
+	}))
+
+	err := db.Model(&Wallet{ID: "1"}).Update("balance_amount", 0).Error
+	assert.ErrorContains(t, err, "user_logs unavailable")
+	err = NewUserRepository(db).Create(ctx, &User{ID: "50", Name: Name{FirstName: "Audit"}, Password: "rahasia"})
+	assert.ErrorContains(t, err, "user_logs unavailable")
+	err = NewAddressRepository(db).Delete(ctx, address.ID)
+	assert.ErrorContains(t, err, "user_logs unavailable")
+
+	var wallet Wallet
+	assert.Nil(t, db.Take(&wallet, "id = ?", "1").Error)
+	assert.Equal(t, idr(1000000), wallet.Balance)
+	var users, addresses int64
+	assert.Nil(t, db.Model(&User{}).Where("id = ?", "50").Count(&users).Error)
+	assert.Equal(t, int64(0), users)
+	assert.Nil(t, db.Model(&Address{}).Where("id = ?", address.ID).Count(&addresses).Error)
+	assert.Equal(t, int64(1), addresses)
+}
+
+func TestAuditSoftDelete(t *testing.T) {
+	db := newTestDB(t)
+	tx := db.WithContext(WithActor(context.Background(), "admin"))
+
+	todo := Todo{UserId: "1", Title: "Audit"}
+	assert.Nil(t, tx.Create(&todo).Error)
+	assert.Nil(t, tx.Delete(&todo).Error)
+	// baris yang sudah dihapus (soft delete) tidak ikut terhapus lagi
+	assert.Nil(t, tx.Where("title = ?", "Audit").Delete(&Todo{}).Error)
+
+	logs := auditLogs(t, db, "admin")
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, []string{AuditCreate, AuditDelete}, []string{logs[0].Action, logs[1].Action})
+	assert.Equal(t, "todos", logs[1].Table)
+	assert.Equal(t, "Audit", auditChangesOf(t, logs[1])["title"]["old"])
+}
+
+func TestAuditUpsert(t *testing.T) {
+	db := newTestDB(t)
+	ctx := WithActor(context.Background(), "admin")
+	products := NewProductRepository(db)
+
+	// upsert baris baru dicatat sebagai create, upsert ke baris lama sebagai update dengan nilai lamanya
+	assert.Nil(t, products.Upsert(ctx, &Product{ID: "P900", Name: "Audit", Price: idr(5000)}))
+	assert.Nil(t, products.Upsert(ctx, &Product{ID: "P900", Name: "Audit", Price: idr(7500)}))
+	assert.Nil(t, products.Upsert(ctx, &Product{ID: "P900", Name: "Diabaikan", Price: idr(7500)}, "price"))
+
+	logs := auditLogs(t, db, "admin")
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, []string{AuditCreate, AuditUpdate}, []string{logs[0].Action, logs[1].Action})
+	assert.Equal(t, "P900", logs[1].RecordId)
+	changes := auditChangesOf(t, logs[1])
+	assert.Equal(t, "IDR 5000", changes["price"]["old"])
+	assert.Equal(t, "IDR 7500", changes["price"]["new"])
+	assert.NotContains(t, changes, "name")
+}
@@ -0,0 +1,523 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/migrations"
+	"learn-golang-gorm/rpc"
+	"learn-golang-gorm/server"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const usage = `usage: gormctl [-config file] <command> [arguments]
+
+commands:
+  migrate up                 jalankan semua migration yang belum dijalankan
+  migrate down [-steps n]    batalkan n migration terakhir (default 1, 0 = semua)
+  migrate status             tampilkan status migration
+  migrate redo               batalkan lalu jalankan ulang migration terakhir
+  migrate resolve <applied|pending>
+                             tandai migration yang gagal di tengah jalan (dirty) setelah schema diperbaiki
+  seed                       isi database dengan data contoh
+  reset [-seed]              hapus semua migration lalu jalankan ulang
+  dump [table ...]           tampilkan isi tabel dalam format JSON (tanpa users.password)
+  inspect <model>            tampilkan struktur model (contoh: user, wallets)
+  reconcile [-repair mode]   bandingkan saldo wallet dengan ledger (mode: none, balance, ledger)
+  statement [-format f] [-month YYYY-MM | -from date -to date] <wallet>
+                             rekening koran wallet (format: csv, json, text; default bulan ini)
+  archive-logs [-days n] [-dir d] [-batch n]
+                             arsipkan user_logs yang lebih lama dari n hari (gzip) lalu hapus
+  serve [-addr address] [-grpc-addr address] [-hold-sweep-interval d] [-trusted-proxies cidr,...]
+                             jalankan REST API (default :8080) dan API gRPC sampai dihentikan (Ctrl+C),
+                             sekaligus meng-expire hold wallet setiap d (default 1m, 0 = tidak).
+                             X-Actor-ID (dan x-actor-id gRPC) hanya diterima dari alamat di -trusted-proxies
+`
+
+var errUsage = errors.New("invalid arguments, run gormctl -h for help")
+
+// run dipisah dari main supaya bisa diuji tanpa os.Exit
+func run(ctx context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("gormctl", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	flags.Usage = func() { fmt.Fprint(stdout, usage) }
+	configFile := flags.String("config", "", "path file konfigurasi (.yaml/.yml/.json)")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	cfg, err := learn_golang_gorm.LoadConfig(*configFile)
+	if err != nil {
+		return err
+	}
+	db, err := learn_golang_gorm.Open(cfg)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	command, rest := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "migrate":
+		return migrate(ctx, db, rest, stdout)
+	case "seed":
+		if err := learn_golang_gorm.Seed(ctx, db); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "seed: done")
+		return nil
+	case "reset":
+		return reset(ctx, db, rest, stdout)
+	case "dump":
+		return dump(ctx, db, rest, stdout)
+	case "inspect":
+		return inspect(ctx, db, rest, stdout)
+	case "reconcile":
+		return reconcile(ctx, db, rest, stdout)
+	case "statement":
+		return statement(ctx, db, rest, stdout)
+	case "archive-logs":
+		return archiveLogs(ctx, db, rest, stdout)
+	case "serve":
+		return serve(ctx, db, rest, stdout)
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func migrate(ctx context.Context, db *gorm.DB, args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	m, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		if err := m.Up(ctx); err != nil {
+			return err
+		}
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		flags.SetOutput(stdout)
+		steps := flags.Int("steps", 1, "jumlah migration yang dibatalkan (0 = semua)")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if err := m.Down(ctx, *steps); err != nil {
+			return err
+		}
+	case "redo":
+		if err := m.Redo(ctx); err != nil {
+			return err
+		}
+	case "resolve":
+		if len(args) != 2 || (args[1] != "applied" && args[1] != "pending") {
+			return errUsage
+		}
+		if err := m.Resolve(ctx, args[1] == "applied"); err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+	return printStatus(ctx, m, stdout)
+}
+
+func printStatus(ctx context.Context, m *migrations.Migrator, stdout io.Writer) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", "-"
+		if status.Applied {
+			state, appliedAt = "applied", status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if status.Modified {
+			state = "modified"
+		}
+		if status.Dirty {
+			state = "dirty"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
+
+func reset(ctx context.Context, db *gorm.DB, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("reset", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	seed := flags.Bool("seed", false, "isi data contoh setelah reset")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	if err := m.Down(ctx, 0); err != nil && !errors.Is(err, migrations.ErrNoMigration) {
+		return err
+	}
+	if err := m.Up(ctx); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "reset: done")
+
+	if *seed {
+		if err := learn_golang_gorm.Seed(ctx, db); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "seed: done")
+	}
+	return nil
+}
+
+// secretColumns tidak ikut ditampilkan oleh dump: hash password yang bocor tetap bisa di-brute force secara offline
+var secretColumns = map[string][]string{
+	"users": {"password"},
+}
+
+func dump(ctx context.Context, db *gorm.DB, tables []string, stdout io.Writer) error {
+	if len(tables) == 0 {
+		var err error
+		if tables, err = db.WithContext(ctx).Migrator().GetTables(); err != nil {
+			return err
+		}
+		sort.Strings(tables)
+	}
+
+	result := make(map[string][]map[string]interface{}, len(tables))
+	for _, table := range tables {
+		if !db.Migrator().HasTable(table) {
+			return fmt.Errorf("table %q not found", table)
+		}
+		rows := []map[string]interface{}{}
+		if err := db.WithContext(ctx).Table(table).Find(&rows).Error; err != nil {
+			return fmt.Errorf("dump %s: %w", table, err)
+		}
+		for _, row := range rows {
+			for _, column := range secretColumns[table] {
+				delete(row, column)
+			}
+		}
+		result[table] = rows
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+func inspect(ctx context.Context, db *gorm.DB, args []string, stdout io.Writer) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	s, err := findModel(db, args[0])
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := db.WithContext(ctx).Table(s.Table).Count(&count).Error; err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "model: %s\ntable: %s\nrows:  %d\n\n", s.Name, s.Table, count)
+
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tCOLUMN\tTYPE\tATTRIBUTES")
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		var attributes []string
+		if field.PrimaryKey {
+			attributes = append(attributes, "primary key")
+		}
+		if field.AutoIncrement {
+			attributes = append(attributes, "auto increment")
+		}
+		if field.NotNull {
+			attributes = append(attributes, "not null")
+		}
+		if field.AutoCreateTime > 0 {
+			attributes = append(attributes, "auto create time")
+		}
+		if field.AutoUpdateTime > 0 {
+			attributes = append(attributes, "auto update time")
+		}
+		if len(field.EmbeddedBindNames) > 1 {
+			attributes = append(attributes, "embedded "+strings.Join(field.EmbeddedBindNames[:len(field.EmbeddedBindNames)-1], "."))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", field.Name, field.DBName, db.Migrator().FullDataTypeOf(field).SQL, strings.Join(attributes, ", "))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(s.Relationships.Relations) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(s.Relationships.Relations))
+	for name := range s.Relationships.Relations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(stdout)
+	w = tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "RELATION\tTYPE\tMODEL\tJOIN TABLE")
+	for _, name := range names {
+		relation := s.Relationships.Relations[name]
+		joinTable := "-"
+		if relation.JoinTable != nil {
+			joinTable = relation.JoinTable.Table
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, relation.Type, relation.FieldSchema.Name, joinTable)
+	}
+	return w.Flush()
+}
+
+// findModel mencari model berdasarkan nama struct (User) atau nama tabel (users), tidak case sensitive
+func findModel(db *gorm.DB, name string) (*schema.Schema, error) {
+	var available []string
+	for _, model := range learn_golang_gorm.Models() {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			return nil, err
+		}
+		s := statement.Schema
+		if strings.EqualFold(name, s.Name) || strings.EqualFold(name, s.Table) {
+			return s, nil
+		}
+		available = append(available, strings.ToLower(s.Name))
+	}
+	return nil, fmt.Errorf("model %q not found, available: %s", name, strings.Join(available, ", "))
+}
+
+var repairModes = map[string]learn_golang_gorm.RepairMode{
+	"none":    learn_golang_gorm.RepairNone,
+	"balance": learn_golang_gorm.RepairBalance,
+	"ledger":  learn_golang_gorm.RepairLedger,
+}
+
+// reconcile gagal (exit code bukan 0) jika masih ada selisih, supaya bisa dijalankan sebagai cron job
+func reconcile(ctx context.Context, db *gorm.DB, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	repair := flags.String("repair", "none", "none, balance (ikuti ledger) atau ledger (tambah journal adjustment)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	mode, ok := repairModes[*repair]
+	if !ok {
+		return fmt.Errorf("unknown repair mode %q", *repair)
+	}
+
+	report, err := learn_golang_gorm.NewLedger(db).Reconcile(ctx, mode)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "wallets checked: %d, drifted: %d, unbalanced journals: %d\n",
+		report.Wallets, len(report.Drifts), len(report.UnbalancedJournals))
+	if len(report.Drifts) > 0 {
+		w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "WALLET\tBALANCE\tLEDGER\tDRIFT\tREPAIRED")
+		for _, drift := range report.Drifts {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%t\n", drift.WalletID, drift.Balance, drift.LedgerBalance, drift.Drift(), drift.Repaired)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	for _, journal := range report.UnbalancedJournals {
+		fmt.Fprintf(stdout, "unbalanced journal: %s\n", journal)
+	}
+
+	if !report.OK() {
+		return errors.New("reconcile: ledger and wallet balances do not match")
+	}
+	return nil
+}
+
+// statement menulis rekening koran satu wallet ke stdout, periode -to tidak termasuk (tanggal eksklusif)
+func statement(ctx context.Context, db *gorm.DB, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("statement", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	format := flags.String("format", "text", "csv, json atau text")
+	month := flags.String("month", time.Now().Format("2006-01"), "bulan statement (YYYY-MM)")
+	fromDate := flags.String("from", "", "tanggal awal (YYYY-MM-DD), menggantikan -month")
+	toDate := flags.String("to", "", "tanggal akhir (YYYY-MM-DD, tidak termasuk)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	var from, to time.Time
+	if *fromDate != "" || *toDate != "" {
+		var err error
+		if from, err = time.ParseInLocation("2006-01-02", *fromDate, time.Local); err != nil {
+			return fmt.Errorf("invalid -from: %w", err)
+		}
+		if to, err = time.ParseInLocation("2006-01-02", *toDate, time.Local); err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+	} else {
+		first, err := time.ParseInLocation("2006-01", *month, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid -month: %w", err)
+		}
+		from, to = learn_golang_gorm.StatementMonth(first.Year(), first.Month(), time.Local)
+	}
+
+	_, err := learn_golang_gorm.NewStatementService(db).
+		Export(ctx, stdout, learn_golang_gorm.StatementFormat(*format), flags.Arg(0), from, to)
+	return err
+}
+
+func archiveLogs(ctx context.Context, db *gorm.DB, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("archive-logs", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	days := flags.Int("days", 90, "log yang lebih lama dari jumlah hari ini diarsipkan")
+	dir := flags.String("dir", "archive", "folder tujuan file arsip")
+	batch := flags.Int("batch", learn_golang_gorm.DefaultUserLogArchiveBatch, "jumlah log yang dihapus per transaction")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	result, err := learn_golang_gorm.NewUserLogRepository(db).ApplyRetention(ctx, *dir, *days, *batch)
+	if err != nil {
+		if result != nil && result.File != "" {
+			fmt.Fprintf(stdout, "archive-logs: %d logs archived to %s before the error\n", result.Archived, result.File)
+		}
+		return err
+	}
+	if result.File == "" {
+		fmt.Fprintf(stdout, "archive-logs: nothing older than %d days\n", *days)
+		return nil
+	}
+	fmt.Fprintf(stdout, "archive-logs: %d logs archived to %s\n", result.Archived, result.File)
+	return nil
+}
+
+// serve menjalankan REST API (dan API gRPC jika -grpc-addr diisi) sampai ctx dibatalkan,
+// lalu menunggu request yang sedang berjalan selesai
+func serve(ctx context.Context, db *gorm.DB, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	addr := flags.String("addr", ":8080", "alamat HTTP server")
+	grpcAddr := flags.String("grpc-addr", "", "alamat server gRPC, kosong berarti tanpa gRPC")
+	sweepInterval := flags.Duration("hold-sweep-interval", time.Minute, "interval mengubah hold wallet yang kedaluwarsa menjadi expired, 0 berarti tidak dijalankan")
+	trusted := flags.String("trusted-proxies", "", "daftar IP / CIDR (dipisah koma) yang boleh mengirim X-Actor-ID / metadata x-actor-id, kosong berarti selalu ditolak")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *sweepInterval < 0 {
+		return errUsage
+	}
+	proxies, err := parsePrefixes(*trusted)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	handler := server.New(db, server.WithAuthenticator(server.TrustedProxies(proxies...)))
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	served := make(chan error, 2)
+	go func() { served <- srv.Serve(listener) }()
+	fmt.Fprintf(stdout, "serve: listening on %s\n", listener.Addr())
+
+	if *grpcAddr != "" {
+		grpcListener, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			srv.Close()
+			return err
+		}
+		grpcSrv := rpc.New(db, rpc.WithAuthenticator(rpc.TrustedPeers(proxies...)))
+		go func() { served <- grpcSrv.Serve(grpcListener) }()
+		defer grpcSrv.GracefulStop()
+		fmt.Fprintf(stdout, "serve: grpc listening on %s\n", grpcListener.Addr())
+	}
+
+	if *sweepInterval > 0 {
+		// tanpa sweeper hold yang kedaluwarsa tetap berstatus active (meskipun tidak lagi mengurangi saldo)
+		sweepCtx, stopSweep := context.WithCancel(ctx)
+		defer stopSweep()
+		go learn_golang_gorm.NewHoldService(db).Sweep(sweepCtx, *sweepInterval)
+		fmt.Fprintf(stdout, "serve: expiring wallet holds every %s\n", *sweepInterval)
+	}
+
+	select {
+	case err := <-served:
+		srv.Close()
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "serve: stopped")
+	return nil
+}
+
+// parsePrefixes membaca daftar "10.0.0.0/8,127.0.0.1", IP tanpa prefix berarti satu alamat itu saja
+func parsePrefixes(list string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(item); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", item, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
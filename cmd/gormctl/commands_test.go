@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func runCommand(t *testing.T, args ...string) (string, error) {
+	var stdout bytes.Buffer
+	err := run(context.Background(), args, &stdout)
+	return stdout.String(), err
+}
+
+func openDB(t *testing.T) *gorm.DB {
+	cfg, err := learn_golang_gorm.LoadConfig("")
+	assert.Nil(t, err)
+	db, err := learn_golang_gorm.Open(cfg)
+	assert.Nil(t, err)
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestCommands(t *testing.T) {
+	t.Setenv("DB_CONFIG_FILE", "")
+	t.Setenv("DB_DIALECT", "sqlite")
+	t.Setenv("DB_DSN", filepath.Join(t.TempDir(), "gormctl.db"))
+	t.Setenv("DB_LOG_LEVEL", "silent")
+
+	output, err := runCommand(t, "migrate", "up")
+	assert.Nil(t, err)
+	assert.Contains(t, output, "create_users")
+	assert.NotContains(t, output, "pending")
+
+	_, err = runCommand(t, "seed")
+	assert.Nil(t, err)
+	_, err = runCommand(t, "seed") // seed boleh dijalankan berulang kali
+	assert.Nil(t, err)
+
+	output, err = runCommand(t, "dump", "users", "wallets")
+	assert.Nil(t, err)
+	var dumped map[string][]map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(output), &dumped))
+	assert.Equal(t, 9, len(dumped["users"]))
+	assert.Equal(t, 1, len(dumped["wallets"]))
+	assert.Contains(t, dumped["users"][0], "first_name")
+	assert.NotContains(t, dumped["users"][0], "password")
+
+	// seed dua kali tetap hanya mencatat satu saldo awal di ledger
+	output, err = runCommand(t, "reconcile")
+	assert.Nil(t, err)
+	assert.Contains(t, output, "wallets checked: 1, drifted: 0, unbalanced journals: 0")
+
+	db := openDB(t)
+	assert.Nil(t, db.Exec("UPDATE wallets SET balance_amount = balance_amount + 500 WHERE id = ?", "1").Error)
+	output, err = runCommand(t, "reconcile")
+	assert.NotNil(t, err)
+	assert.Regexp(t, `1\s+1000500\s+1000000\s+500\s+false`, output)
+	output, err = runCommand(t, "reconcile", "-repair", "balance")
+	assert.Nil(t, err)
+	assert.Regexp(t, `1\s+1000500\s+1000000\s+500\s+true`, output)
+	_, err = runCommand(t, "reconcile", "-repair", "everything")
+	assert.NotNil(t, err)
+
+	output, err = runCommand(t, "statement", "-format", "csv", "1")
+	assert.Nil(t, err)
+	assert.Contains(t, output, "opening balance")
+	assert.Contains(t, output, "opening_balance")
+	output, err = runCommand(t, "statement", "-from", "2000-01-01", "-to", "2000-02-01", "1")
+	assert.Nil(t, err)
+	assert.Contains(t, output, "0 movement(s)")
+	_, err = runCommand(t, "statement", "-format", "pdf", "1")
+	assert.ErrorIs(t, err, learn_golang_gorm.ErrUnknownStatementFormat)
+	_, err = runCommand(t, "statement")
+	assert.ErrorIs(t, err, errUsage)
+
+	// log audit dari seed (created_at dimundurkan) diarsipkan lalu dihapus
+	assert.Nil(t, db.Exec("UPDATE user_logs SET created_at = created_at - ?", int64(100*24*time.Hour/time.Millisecond)).Error)
+	output, err = runCommand(t, "archive-logs", "-days", "90", "-dir", t.TempDir())
+	assert.Nil(t, err)
+	assert.Regexp(t, `archive-logs: \d+ logs archived to .*user_logs_.*\.jsonl\.gz`, output)
+	output, err = runCommand(t, "archive-logs", "-dir", t.TempDir())
+	assert.Nil(t, err)
+	assert.Contains(t, output, "nothing older than 90 days")
+
+	output, err = runCommand(t, "inspect", "user")
+	assert.Nil(t, err)
+	assert.Contains(t, output, "table: users")
+	assert.Contains(t, output, "rows:  9")
+	assert.Contains(t, output, "embedded Name")
+	assert.Contains(t, output, "user_like_product")
+
+	output, err = runCommand(t, "migrate", "down", "-steps", "1")
+	assert.Nil(t, err)
+	assert.Contains(t, output, "pending")
+
+	_, err = runCommand(t, "migrate", "resolve", "applied")
+	assert.ErrorIs(t, err, migrations.ErrNotDirty)
+	_, err = runCommand(t, "migrate", "resolve")
+	assert.ErrorIs(t, err, errUsage)
+
+	_, err = runCommand(t, "reset")
+	assert.Nil(t, err)
+	output, err = runCommand(t, "dump", "users")
+	assert.Nil(t, err)
+	assert.Contains(t, output, `"users": []`)
+
+	// ctx yang sudah dibatalkan: server langsung dihentikan
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var stdout bytes.Buffer
+	assert.Nil(t, run(ctx, []string{"serve", "-addr", "127.0.0.1:0"}, &stdout))
+	assert.Regexp(t, `serve: listening on 127\.0\.0\.1:\d+\nserve: expiring wallet holds every 1m0s\nserve: stopped`, stdout.String())
+	stdout.Reset()
+	assert.Nil(t, run(ctx, []string{"serve", "-addr", "127.0.0.1:0", "-grpc-addr", "127.0.0.1:0", "-hold-sweep-interval", "0"}, &stdout))
+	assert.Regexp(t, `serve: grpc listening on 127\.0\.0\.1:\d+\nserve: stopped`, stdout.String())
+	assert.ErrorIs(t, run(ctx, []string{"serve", "-hold-sweep-interval", "-1s"}, &stdout), errUsage)
+	assert.NotNil(t, run(ctx, []string{"serve", "-trusted-proxies", "10.0.0.0/33"}, &stdout))
+
+	_, err = runCommand(t, "inspect", "invoice")
+	assert.NotNil(t, err)
+	_, err = runCommand(t, "deploy")
+	assert.NotNil(t, err)
+}
+
+func TestParsePrefixes(t *testing.T) {
+	prefixes, err := parsePrefixes(" 10.1.2.3/8, 127.0.0.1,::1 ")
+	assert.Nil(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("127.0.0.1/32"),
+		netip.MustParsePrefix("::1/128"),
+	}, prefixes)
+
+	prefixes, err = parsePrefixes("")
+	assert.Nil(t, err)
+	assert.Empty(t, prefixes)
+	_, err = parsePrefixes("localhost")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,33 @@
+// gormctl menyiapkan dan memeriksa database tanpa harus menjalankan test.
+//
+//	gormctl [-config database.yaml] <command> [arguments]
+//
+// Command:
+//
+//	migrate up | down [-steps n] | status | redo | resolve <applied|pending>
+//	seed
+//	reset [-seed]
+//	dump [table ...]
+//	inspect <model>
+//	serve [-addr :8080] [-grpc-addr :9090] [-hold-sweep-interval 1m] [-trusted-proxies 10.0.0.0/8]
+//
+// Koneksi memakai konfigurasi yang sama dengan library (learn_golang_gorm.LoadConfig),
+// jadi environment variable DB_* juga berlaku.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "gormctl:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,300 @@
+package learn_golang_gorm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"learn-golang-gorm/dberrors"
+	"learn-golang-gorm/password"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Config berisi semua pengaturan koneksi database.
+// Nilai default sama dengan yang dulu di-hardcode pada OpenConnection.
+type Config struct {
+	Dialect                string // mysql, postgres, sqlite
+	DSN                    string
+	LogLevel               string // silent, error, warn, info
+	SkipDefaultTransaction bool
+	PrepareStmt            bool
+	MaxIdleConns           int
+	MaxOpenConns           int
+	ConnMaxIdleTime        time.Duration
+	ConnMaxLifetime        time.Duration
+}
+
+// Nama environment variable yang dibaca oleh LoadConfig
+const (
+	EnvConfigFile             = "DB_CONFIG_FILE"
+	EnvDialect                = "DB_DIALECT"
+	EnvDSN                    = "DB_DSN"
+	EnvLogLevel               = "DB_LOG_LEVEL"
+	EnvSkipDefaultTransaction = "DB_SKIP_DEFAULT_TRANSACTION"
+	EnvPrepareStmt            = "DB_PREPARE_STMT"
+	EnvMaxIdleConns           = "DB_MAX_IDLE_CONNS"
+	EnvMaxOpenConns           = "DB_MAX_OPEN_CONNS"
+	EnvConnMaxIdleTime        = "DB_CONN_MAX_IDLE_TIME"
+	EnvConnMaxLifetime        = "DB_CONN_MAX_LIFETIME"
+)
+
+// Dialect yang didukung
+const (
+	DialectMySQL    = "mysql"
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+var logLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Dialect:                DialectMySQL,
+		DSN:                    "root:@tcp(localhost:3306)/learn_golang_gorm?charset=utf8mb4&parseTime=True&loc=Local",
+		LogLevel:               "info",
+		SkipDefaultTransaction: true,
+		PrepareStmt:            true,
+		MaxIdleConns:           10,
+		MaxOpenConns:           100,
+		ConnMaxIdleTime:        5 * time.Minute,
+		ConnMaxLifetime:        30 * time.Minute,
+	}
+}
+
+// fileConfig adalah bentuk Config di file YAML/JSON.
+// Semua field berupa pointer supaya key yang tidak ditulis tetap memakai nilai default.
+type fileConfig struct {
+	Dialect                *string `json:"dialect" yaml:"dialect"`
+	DSN                    *string `json:"dsn" yaml:"dsn"`
+	LogLevel               *string `json:"log_level" yaml:"log_level"`
+	SkipDefaultTransaction *bool   `json:"skip_default_transaction" yaml:"skip_default_transaction"`
+	PrepareStmt            *bool   `json:"prepare_stmt" yaml:"prepare_stmt"`
+	MaxIdleConns           *int    `json:"max_idle_conns" yaml:"max_idle_conns"`
+	MaxOpenConns           *int    `json:"max_open_conns" yaml:"max_open_conns"`
+	ConnMaxIdleTime        *string `json:"conn_max_idle_time" yaml:"conn_max_idle_time"` // contoh: "5m"
+	ConnMaxLifetime        *string `json:"conn_max_lifetime" yaml:"conn_max_lifetime"`
+}
+
+// LoadConfig membaca konfigurasi dengan urutan prioritas:
+// 1. DefaultConfig()
+// 2. file YAML/JSON (path, atau DB_CONFIG_FILE jika path kosong)
+// 3. environment variable DB_*
+// lalu memvalidasi hasilnya.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+
+	if path == "" {
+		path = os.Getenv(EnvConfigFile)
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	var raw fileConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("config file %s: unsupported extension, use .json, .yaml or .yml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	if raw.Dialect != nil {
+		c.Dialect = *raw.Dialect
+	}
+	if raw.DSN != nil {
+		c.DSN = *raw.DSN
+	}
+	if raw.LogLevel != nil {
+		c.LogLevel = *raw.LogLevel
+	}
+	if raw.SkipDefaultTransaction != nil {
+		c.SkipDefaultTransaction = *raw.SkipDefaultTransaction
+	}
+	if raw.PrepareStmt != nil {
+		c.PrepareStmt = *raw.PrepareStmt
+	}
+	if raw.MaxIdleConns != nil {
+		c.MaxIdleConns = *raw.MaxIdleConns
+	}
+	if raw.MaxOpenConns != nil {
+		c.MaxOpenConns = *raw.MaxOpenConns
+	}
+	if raw.ConnMaxIdleTime != nil {
+		if c.ConnMaxIdleTime, err = time.ParseDuration(*raw.ConnMaxIdleTime); err != nil {
+			return fmt.Errorf("config file %s: conn_max_idle_time: %w", path, err)
+		}
+	}
+	if raw.ConnMaxLifetime != nil {
+		if c.ConnMaxLifetime, err = time.ParseDuration(*raw.ConnMaxLifetime); err != nil {
+			return fmt.Errorf("config file %s: conn_max_lifetime: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	var err error
+	if v, ok := os.LookupEnv(EnvDialect); ok {
+		c.Dialect = v
+	}
+	if v, ok := os.LookupEnv(EnvDSN); ok {
+		c.DSN = v
+	}
+	if v, ok := os.LookupEnv(EnvLogLevel); ok {
+		c.LogLevel = v
+	}
+	if v, ok := os.LookupEnv(EnvSkipDefaultTransaction); ok {
+		if c.SkipDefaultTransaction, err = strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("%s: %w", EnvSkipDefaultTransaction, err)
+		}
+	}
+	if v, ok := os.LookupEnv(EnvPrepareStmt); ok {
+		if c.PrepareStmt, err = strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("%s: %w", EnvPrepareStmt, err)
+		}
+	}
+	if v, ok := os.LookupEnv(EnvMaxIdleConns); ok {
+		if c.MaxIdleConns, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("%s: %w", EnvMaxIdleConns, err)
+		}
+	}
+	if v, ok := os.LookupEnv(EnvMaxOpenConns); ok {
+		if c.MaxOpenConns, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("%s: %w", EnvMaxOpenConns, err)
+		}
+	}
+	if v, ok := os.LookupEnv(EnvConnMaxIdleTime); ok {
+		if c.ConnMaxIdleTime, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("%s: %w", EnvConnMaxIdleTime, err)
+		}
+	}
+	if v, ok := os.LookupEnv(EnvConnMaxLifetime); ok {
+		if c.ConnMaxLifetime, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("%s: %w", EnvConnMaxLifetime, err)
+		}
+	}
+	return nil
+}
+
+// Validate mengecek apakah konfigurasi masuk akal sebelum dipakai untuk membuka koneksi
+func (c Config) Validate() error {
+	var errs []error
+	switch strings.ToLower(c.Dialect) {
+	case DialectMySQL, DialectPostgres, DialectSQLite:
+	default:
+		errs = append(errs, fmt.Errorf("dialect %q is invalid, use mysql, postgres or sqlite", c.Dialect))
+	}
+	if strings.TrimSpace(c.DSN) == "" {
+		errs = append(errs, errors.New("dsn is required"))
+	}
+	if _, ok := logLevels[strings.ToLower(c.LogLevel)]; !ok {
+		errs = append(errs, fmt.Errorf("log level %q is invalid, use silent, error, warn or info", c.LogLevel))
+	}
+	if c.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("max idle conns must not be negative"))
+	}
+	if c.MaxOpenConns < 0 {
+		errs = append(errs, errors.New("max open conns must not be negative"))
+	}
+	// MaxOpenConns = 0 artinya tidak dibatasi
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("max idle conns (%d) must not exceed max open conns (%d)", c.MaxIdleConns, c.MaxOpenConns))
+	}
+	if c.ConnMaxIdleTime < 0 {
+		errs = append(errs, errors.New("conn max idle time must not be negative"))
+	}
+	if c.ConnMaxLifetime < 0 {
+		errs = append(errs, errors.New("conn max lifetime must not be negative"))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid database config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Open membuka koneksi GORM sesuai cfg dan mengatur connection pool-nya.
+// Berbeda dengan OpenConnection yang lama, Open mengembalikan error (tidak panic).
+func Open(cfg Config) (*gorm.DB, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector(cfg), &gorm.Config{
+		Logger:                 password.RedactLogger(logger.Default.LogMode(logLevels[strings.ToLower(cfg.LogLevel)])),
+		SkipDefaultTransaction: cfg.SkipDefaultTransaction,
+		PrepareStmt:            cfg.PrepareStmt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	// setiap perubahan data user, wallet, address, product dan todo dicatat di user_logs
+	if err := db.Use(NewAuditPlugin(AuditedModels()...)); err != nil {
+		return nil, fmt.Errorf("register audit plugin: %w", err)
+	}
+	// error driver (duplicate key, foreign key, ...) diterjemahkan menjadi error dberrors
+	if err := db.Use(dberrors.Plugin{}); err != nil {
+		return nil, fmt.Errorf("register dberrors plugin: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get sql.DB: %w", err)
+	}
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	return db, nil
+}
+
+// dialector memilih driver GORM sesuai cfg.Dialect (sudah divalidasi oleh Validate)
+func dialector(cfg Config) gorm.Dialector {
+	switch strings.ToLower(cfg.Dialect) {
+	case DialectPostgres:
+		return postgres.Open(cfg.DSN)
+	case DialectSQLite:
+		return sqlite.Open(cfg.DSN)
+	default:
+		return mysql.Open(cfg.DSN)
+	}
+}
@@ -0,0 +1,87 @@
+package learn_golang_gorm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clearConfigEnv menghapus semua env DB_* selama test berjalan supaya hasil LoadConfig bisa ditebak
+func clearConfigEnv(t *testing.T) {
+	for _, key := range []string{
+		EnvConfigFile, EnvDialect, EnvDSN, EnvLogLevel, EnvSkipDefaultTransaction, EnvPrepareStmt,
+		EnvMaxIdleConns, EnvMaxOpenConns, EnvConnMaxIdleTime, EnvConnMaxLifetime,
+	} {
+		t.Setenv(key, "") // t.Setenv mengembalikan nilai lama setelah test selesai
+		os.Unsetenv(key)
+	}
+}
+
+func TestLoadConfigFromFileAndEnv(t *testing.T) {
+	clearConfigEnv(t)
+	dir := t.TempDir()
+
+	yamlFile := filepath.Join(dir, "database.yaml")
+	err := os.WriteFile(yamlFile, []byte("dsn: user:pass@tcp(db:3306)/app\nmax_open_conns: 20\nconn_max_lifetime: 1h\n"), 0o644)
+	assert.Nil(t, err)
+
+	jsonFile := filepath.Join(dir, "database.json")
+	err = os.WriteFile(jsonFile, []byte(`{"dialect": "postgres", "dsn": "host=db user=app dbname=app", "log_level": "warn", "prepare_stmt": false}`), 0o644)
+	assert.Nil(t, err)
+
+	t.Setenv(EnvMaxIdleConns, "5")
+
+	cfg, err := LoadConfig(yamlFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "user:pass@tcp(db:3306)/app", cfg.DSN)
+	assert.Equal(t, 20, cfg.MaxOpenConns)
+	assert.Equal(t, 5, cfg.MaxIdleConns)                // dari env
+	assert.Equal(t, time.Hour, cfg.ConnMaxLifetime)     // dari file
+	assert.Equal(t, 5*time.Minute, cfg.ConnMaxIdleTime) // tetap default
+	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, DialectMySQL, cfg.Dialect)
+
+	cfg, err = LoadConfig(jsonFile)
+	assert.Nil(t, err)
+	assert.Equal(t, DialectPostgres, cfg.Dialect)
+	assert.Equal(t, "warn", cfg.LogLevel)
+	assert.False(t, cfg.PrepareStmt)
+}
+
+func TestConfigValidate(t *testing.T) {
+	clearConfigEnv(t)
+	cfg := DefaultConfig()
+	assert.Nil(t, cfg.Validate())
+
+	cfg.Dialect = "oracle"
+	cfg.DSN = ""
+	cfg.LogLevel = "debug"
+	cfg.MaxIdleConns = 200
+	err := cfg.Validate()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "dialect")
+	assert.Contains(t, err.Error(), "dsn is required")
+	assert.Contains(t, err.Error(), "log level")
+	assert.Contains(t, err.Error(), "max idle conns")
+
+	_, err = Open(cfg) // Open tidak panic, tetapi mengembalikan error
+	assert.NotNil(t, err)
+
+	t.Setenv(EnvMaxOpenConns, "banyak")
+	_, err = LoadConfig("")
+	assert.NotNil(t, err)
+}
+
+func TestTestConfigDialect(t *testing.T) {
+	clearConfigEnv(t)
+	assert.Equal(t, DialectSQLite, testConfig(t).Dialect)
+
+	// DB_DSN tanpa DB_DIALECT: dialect default, bukan sqlite dengan DSN yang diabaikan
+	t.Setenv(EnvDSN, "user:pass@tcp(db:3306)/app")
+	cfg := testConfig(t)
+	assert.Equal(t, DialectMySQL, cfg.Dialect)
+	assert.Equal(t, "user:pass@tcp(db:3306)/app", cfg.DSN)
+}
@@ -0,0 +1,15 @@
+package learn_golang_gorm
+
+import (
+	"learn-golang-gorm/money"
+)
+
+// DefaultCurrency dipakai untuk Wallet dan Product yang dibuat tanpa mata uang
+var DefaultCurrency = money.IDR
+
+// withDefaultCurrency mengisi mata uang jika masih kosong
+func withDefaultCurrency(m *money.Money) {
+	if m.Currency == "" {
+		m.Currency = DefaultCurrency
+	}
+}
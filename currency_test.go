@@ -0,0 +1,116 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"testing"
+
+	"learn-golang-gorm/migrations"
+	"learn-golang-gorm/money"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletCurrency(t *testing.T) {
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
+	// wallet tanpa mata uang memakai DefaultCurrency
+	assert.Nil(t, db.Create(&Wallet{ID: "3", UserId: "3", Balance: money.Money{Amount: 5000}}).Error)
+	assert.Nil(t, db.Create(&Wallet{ID: "4", UserId: "4", Balance: money.New(2500, money.USD)}).Error)
+
+	var wallets []Wallet
+	assert.Nil(t, db.Where("balance_currency = ?", money.USD).Find(&wallets).Error)
+	assert.Equal(t, 1, len(wallets))
+	assert.Equal(t, "USD 25.00", wallets[0].Balance.String())
+
+	var user User
+	assert.Nil(t, db.Joins("Wallet").Take(&user, "users.id = ?", "3").Error)
+	assert.Equal(t, idr(5000), user.Wallet.Balance)
+}
+
+func TestCrossCurrencyRejected(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	assert.Nil(t, db.Create(&Wallet{ID: "3", UserId: "3", Balance: money.New(10000, money.USD)}).Error)
+
+	transfers := NewTransferService(db)
+	_, err := transfers.Transfer(ctx, "1", "3", idr(1000), "idr-to-usd")
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+	_, err = transfers.Transfer(ctx, "1", "2", money.New(1000, money.USD), "usd-amount")
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+
+	_, err = NewWalletService(db).TopUp(ctx, "3", idr(1000), "top-up-usd")
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+	result, err := NewWalletService(db).TopUp(ctx, "3", money.New(550, money.USD), "top-up-usd")
+	assert.Nil(t, err)
+	assert.Equal(t, money.New(10550, money.USD), result.Balance)
+
+	report, err := NewLedger(db).Reconcile(ctx, RepairNone)
+	assert.Nil(t, err)
+	assert.True(t, report.OK())
+}
+
+func TestProductPrice(t *testing.T) {
+	db := newTestDB(t)
+	loadFixtures(t, db, "products")
+
+	var product Product
+	assert.Nil(t, db.Take(&product, "id = ?", "P001").Error)
+	assert.Equal(t, idr(1000000), product.Price)
+
+	assert.Nil(t, db.Create(&Product{ID: "P002", Name: "Product 2", Price: money.New(1999, money.USD)}).Error)
+	var price string
+	assert.Nil(t, db.Model(&Product{}).Select("price").Where("id = ?", "P002").Scan(&price).Error)
+	assert.Equal(t, "USD 19.99", price)
+
+	// harga dikonversi dengan kurs dari tabel exchange_rates
+	ctx := context.Background()
+	rates := money.NewTableRates(db)
+	assert.Nil(t, rates.Set(ctx, money.USD, money.IDR, "16250"))
+	var imported Product
+	assert.Nil(t, db.Take(&imported, "id = ?", "P002").Error)
+	converted, err := money.Convert(ctx, rates, imported.Price, money.IDR)
+	assert.Nil(t, err)
+	assert.Equal(t, idr(324838), converted) // 19.99 * 16250 = 324837.5
+}
+
+func TestCurrencyMigrationKeepsExistingData(t *testing.T) {
+	if testConfig(t).Dialect != DialectSQLite {
+		t.Skip("rollback migration hanya dijalankan di database sqlite sementara")
+	}
+	ctx := context.Background()
+	db := newTestDB(t)
+	migrator, err := migrations.New(db)
+	assert.Nil(t, err)
+
+	// kembali ke schema sebelum 0015_add_currencies
+	statuses, err := migrator.Status(ctx)
+	assert.Nil(t, err)
+	steps := 0
+	for _, status := range statuses {
+		if status.Version >= 15 {
+			steps++
+		}
+	}
+	assert.Nil(t, migrator.Down(ctx, steps))
+
+	// data dengan format sebelum migration 0015
+	assert.Nil(t, db.Exec("INSERT INTO users (id, first_name, password) VALUES ('1', 'Lev', 'secret')").Error)
+	assert.Nil(t, db.Exec("INSERT INTO wallets (id, user_id, balance) VALUES ('1', '1', 1000000)").Error)
+	assert.Nil(t, db.Exec("INSERT INTO products (id, name, price) VALUES ('P001', 'Product 1', '1000000')").Error)
+
+	assert.Nil(t, migrator.Up(ctx))
+	var wallet Wallet
+	assert.Nil(t, db.Take(&wallet, "id = ?", "1").Error)
+	assert.Equal(t, idr(1000000), wallet.Balance)
+	var product Product
+	assert.Nil(t, db.Take(&product, "id = ?", "P001").Error)
+	assert.Equal(t, idr(1000000), product.Price)
+
+	// dan bisa dikembalikan lagi
+	assert.Nil(t, migrator.Down(ctx, steps))
+	var price string
+	assert.Nil(t, db.Raw("SELECT price FROM products WHERE id = ?", "P001").Scan(&price).Error)
+	assert.Equal(t, "1000000", price)
+}
@@ -0,0 +1,294 @@
+// Package dberrors menerjemahkan error dari driver MySQL, Postgres dan SQLite menjadi error domain
+// yang bisa dicek dengan errors.Is tanpa bergantung pada kode error driver:
+//
+//	err := db.Create(&user).Error
+//	switch {
+//	case errors.Is(err, dberrors.ErrDuplicateKey):   // 409
+//	case errors.Is(err, dberrors.ErrNotFound):       // 404
+//	}
+//
+// Detailnya (tabel, constraint, kolom) ada di *Error:
+//
+//	var dbErr *dberrors.Error
+//	if errors.As(err, &dbErr) {
+//		fmt.Println(dbErr.Table, dbErr.Constraint, dbErr.Columns)
+//	}
+//
+// Open sudah memasang Plugin sehingga semua error dari GORM otomatis diterjemahkan. Error asli driver
+// tetap ada di dalamnya (errors.As ke *mysql.MySQLError, *pgconn.PgError atau sqlite3.Error masih bisa).
+package dberrors
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotFound            = errors.New("record not found")
+	ErrDuplicateKey        = errors.New("duplicate key")
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+	ErrCheckViolation      = errors.New("check constraint violation")
+	ErrDataTooLong         = errors.New("data too long")
+	ErrDeadlock            = errors.New("deadlock")
+	ErrLockTimeout         = errors.New("lock wait timeout")
+	ErrSerialization       = errors.New("serialization failure")
+)
+
+// Error adalah error driver yang sudah diterjemahkan. Table, Constraint dan Columns diisi sejauh
+// informasinya tersedia dari driver (misalnya SQLite tidak menyebut nama constraint foreign key).
+type Error struct {
+	Kind       error // salah satu Err* di atas
+	Table      string
+	Constraint string
+	Columns    []string
+	Err        error // error asli
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap membuat errors.Is cocok dengan Kind maupun error asli (misalnya gorm.ErrRecordNotFound)
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+var (
+	mysqlDuplicateKey = regexp.MustCompile(`for key '([^']+)'`)
+	mysqlForeignKey   = regexp.MustCompile("`([^`]+)`, CONSTRAINT `([^`]+)` FOREIGN KEY \\(([^)]+)\\)")
+	mysqlColumn       = regexp.MustCompile(`for column '([^']+)'`)
+	mysqlCheck        = regexp.MustCompile(`Check constraint '([^']+)'`)
+	postgresKey       = regexp.MustCompile(`^Key \(([^)]+)\)`)
+	sqliteConstraint  = regexp.MustCompile(`constraint failed: (.+)$`)
+)
+
+// Translate mengembalikan *Error untuk error driver yang dikenali (termasuk yang dibungkus fmt.Errorf),
+// dan err apa adanya untuk error lain. Error yang sudah diterjemahkan tidak diterjemahkan ulang.
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+	var translated *Error
+	if errors.As(err, &translated) {
+		return err
+	}
+	if e := translate(err); e != nil {
+		e.Err = err
+		return e
+	}
+	return err
+}
+
+func translate(err error) *Error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return fromMySQL(mysqlErr)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return fromPostgres(pgErr)
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return fromSQLite(sqliteErr)
+	}
+
+	// error GORM, misalnya dari First / Take, atau gorm.Config.TranslateError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, sql.ErrNoRows):
+		return &Error{Kind: ErrNotFound}
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return &Error{Kind: ErrDuplicateKey}
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return &Error{Kind: ErrForeignKeyViolation}
+	case errors.Is(err, gorm.ErrCheckConstraintViolated):
+		return &Error{Kind: ErrCheckViolation}
+	}
+	return nil
+}
+
+func fromMySQL(err *mysql.MySQLError) *Error {
+	switch err.Number {
+	case 1062: // Duplicate entry '1' for key 'users.PRIMARY'
+		e := &Error{Kind: ErrDuplicateKey}
+		if m := mysqlDuplicateKey.FindStringSubmatch(err.Message); m != nil {
+			e.Constraint = m[1]
+			// MySQL 8 menulis nama index dengan nama tabelnya
+			if table, index, ok := strings.Cut(m[1], "."); ok {
+				e.Table, e.Constraint = table, index
+			}
+		}
+		return e
+	case 1451, 1452: // Cannot delete or update a parent row / Cannot add or update a child row
+		e := &Error{Kind: ErrForeignKeyViolation}
+		if m := mysqlForeignKey.FindStringSubmatch(err.Message); m != nil {
+			e.Table, e.Constraint = m[1], m[2]
+			e.Columns = splitColumns(strings.ReplaceAll(m[3], "`", ""))
+		}
+		return e
+	case 1406: // Data too long for column 'id' at row 1
+		e := &Error{Kind: ErrDataTooLong}
+		if m := mysqlColumn.FindStringSubmatch(err.Message); m != nil {
+			e.Columns = []string{m[1]}
+		}
+		return e
+	case 3819: // Check constraint 'chk_balance' is violated.
+		e := &Error{Kind: ErrCheckViolation}
+		if m := mysqlCheck.FindStringSubmatch(err.Message); m != nil {
+			e.Constraint = m[1]
+		}
+		return e
+	case 1213:
+		return &Error{Kind: ErrDeadlock}
+	case 1205:
+		return &Error{Kind: ErrLockTimeout}
+	}
+	return nil
+}
+
+func fromPostgres(err *pgconn.PgError) *Error {
+	e := &Error{Table: err.TableName, Constraint: err.ConstraintName}
+	switch err.Code {
+	case "23505": // unique_violation
+		e.Kind = ErrDuplicateKey
+	case "23503": // foreign_key_violation
+		e.Kind = ErrForeignKeyViolation
+	case "23514": // check_violation
+		e.Kind = ErrCheckViolation
+	case "22001": // string_data_right_truncation
+		e.Kind = ErrDataTooLong
+	case "40P01":
+		e.Kind = ErrDeadlock
+	case "55P03":
+		e.Kind = ErrLockTimeout
+	case "40001":
+		e.Kind = ErrSerialization
+	default:
+		return nil
+	}
+	// Key (user_id, product_id)=(1, P001) already exists.
+	if m := postgresKey.FindStringSubmatch(err.Detail); m != nil {
+		e.Columns = splitColumns(m[1])
+	} else if err.ColumnName != "" {
+		e.Columns = []string{err.ColumnName}
+	}
+	return e
+}
+
+func fromSQLite(err sqlite3.Error) *Error {
+	switch err.Code {
+	case sqlite3.ErrBusy, sqlite3.ErrLocked:
+		return &Error{Kind: ErrLockTimeout}
+	case sqlite3.ErrConstraint:
+		return fromSQLiteConstraint(err)
+	}
+	return nil
+}
+
+func fromSQLiteConstraint(err sqlite3.Error) *Error {
+	e := &Error{}
+	switch err.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		e.Kind = ErrDuplicateKey
+	case sqlite3.ErrConstraintForeignKey: // SQLite tidak menyebut tabel maupun kolomnya
+		return &Error{Kind: ErrForeignKeyViolation}
+	case sqlite3.ErrConstraintCheck:
+		e.Kind = ErrCheckViolation
+	default:
+		return nil
+	}
+	m := sqliteConstraint.FindStringSubmatch(err.Error())
+	if m == nil {
+		return e
+	}
+	if e.Kind == ErrCheckViolation { // CHECK constraint failed: chk_balance
+		e.Constraint = m[1]
+		return e
+	}
+	// UNIQUE constraint failed: likes.user_id, likes.product_id
+	for _, column := range splitColumns(m[1]) {
+		table, name, _ := strings.Cut(column, ".")
+		e.Table = table
+		e.Columns = append(e.Columns, name)
+	}
+	return e
+}
+
+func splitColumns(columns string) []string {
+	names := strings.Split(columns, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return names
+}
+
+// StatusCode memetakan error ke status HTTP: 404 untuk ErrNotFound, 409 untuk duplicate key dan
+// foreign key, 422 untuk data yang ditolak constraint, 503 untuk transaction yang bentrok
+// (boleh dicoba lagi), dan 500 untuk error lain.
+func StatusCode(err error) int {
+	err = Translate(err)
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrDuplicateKey), errors.Is(err, ErrForeignKeyViolation):
+		return http.StatusConflict
+	case errors.Is(err, ErrCheckViolation), errors.Is(err, ErrDataTooLong):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrDeadlock), errors.Is(err, ErrLockTimeout), errors.Is(err, ErrSerialization):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// Plugin menerjemahkan db.Error setelah setiap Create, Query, Update, Delete, Row dan Raw.
+// Untuk duplicate key di MySQL, yang hanya menyebut nama index, kolomnya dilengkapi dari schema model.
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "dberrors"
+}
+
+func (p Plugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().After("*").Register("dberrors:translate", p.translate),
+		callback.Query().After("*").Register("dberrors:translate", p.translate),
+		callback.Update().After("*").Register("dberrors:translate", p.translate),
+		callback.Delete().After("*").Register("dberrors:translate", p.translate),
+		callback.Row().After("*").Register("dberrors:translate", p.translate),
+		callback.Raw().After("*").Register("dberrors:translate", p.translate),
+	)
+}
+
+func (Plugin) translate(db *gorm.DB) {
+	if db.Error == nil {
+		return
+	}
+	db.Error = Translate(db.Error)
+
+	var e *Error
+	stmt := db.Statement
+	if !errors.As(db.Error, &e) || e.Kind != ErrDuplicateKey || len(e.Columns) > 0 || stmt.Schema == nil {
+		return
+	}
+	if e.Table == "" {
+		e.Table = stmt.Table
+	}
+	if e.Constraint == "PRIMARY" {
+		e.Columns = append(e.Columns, stmt.Schema.PrimaryFieldDBNames...)
+	} else if index := stmt.Schema.LookIndex(e.Constraint); index != nil {
+		for _, field := range index.Fields {
+			e.Columns = append(e.Columns, field.DBName)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package dberrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Error
+	}{
+		{"mysql duplicate", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '13' for key 'users.PRIMARY'"},
+			Error{Kind: ErrDuplicateKey, Table: "users", Constraint: "PRIMARY"}},
+		{"mysql 5.7 duplicate", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a-1' for key 'uq_wallet_transactions_idempotency_key'"},
+			Error{Kind: ErrDuplicateKey, Constraint: "uq_wallet_transactions_idempotency_key"}},
+		{"mysql foreign key", &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails " +
+			"(`belajar_golang_gorm`.`wallets`, CONSTRAINT `fk_wallets_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"},
+			Error{Kind: ErrForeignKeyViolation, Table: "wallets", Constraint: "fk_wallets_user", Columns: []string{"user_id"}}},
+		{"mysql parent row", &mysql.MySQLError{Number: 1451, Message: "Cannot delete or update a parent row: a foreign key constraint fails " +
+			"(`db`.`user_like_product`, CONSTRAINT `fk_likes` FOREIGN KEY (`user_id`, `product_id`) REFERENCES `users` (`id`))"},
+			Error{Kind: ErrForeignKeyViolation, Table: "user_like_product", Constraint: "fk_likes", Columns: []string{"user_id", "product_id"}}},
+		{"mysql too long", &mysql.MySQLError{Number: 1406, Message: "Data too long for column 'id' at row 1"},
+			Error{Kind: ErrDataTooLong, Columns: []string{"id"}}},
+		{"mysql check", &mysql.MySQLError{Number: 3819, Message: "Check constraint 'chk_balance' is violated."},
+			Error{Kind: ErrCheckViolation, Constraint: "chk_balance"}},
+		{"mysql deadlock", &mysql.MySQLError{Number: 1213}, Error{Kind: ErrDeadlock}},
+		{"mysql lock timeout", &mysql.MySQLError{Number: 1205}, Error{Kind: ErrLockTimeout}},
+
+		{"postgres duplicate", &pgconn.PgError{Code: "23505", TableName: "likes", ConstraintName: "likes_pkey",
+			Detail: "Key (user_id, product_id)=(1, P001) already exists."},
+			Error{Kind: ErrDuplicateKey, Table: "likes", Constraint: "likes_pkey", Columns: []string{"user_id", "product_id"}}},
+		{"postgres foreign key", &pgconn.PgError{Code: "23503", TableName: "wallets", ConstraintName: "wallets_user_id_fkey",
+			Detail: `Key (user_id)=(404) is not present in table "users".`},
+			Error{Kind: ErrForeignKeyViolation, Table: "wallets", Constraint: "wallets_user_id_fkey", Columns: []string{"user_id"}}},
+		{"postgres check", &pgconn.PgError{Code: "23514", TableName: "wallets", ConstraintName: "chk_balance"},
+			Error{Kind: ErrCheckViolation, Table: "wallets", Constraint: "chk_balance"}},
+		{"postgres too long", &pgconn.PgError{Code: "22001"}, Error{Kind: ErrDataTooLong}},
+		{"postgres serialization", &pgconn.PgError{Code: "40001"}, Error{Kind: ErrSerialization}},
+
+		{"sqlite busy", sqlite3.Error{Code: sqlite3.ErrBusy}, Error{Kind: ErrLockTimeout}},
+		{"sqlite foreign key", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintForeignKey},
+			Error{Kind: ErrForeignKeyViolation}},
+
+		{"gorm not found", gorm.ErrRecordNotFound, Error{Kind: ErrNotFound}},
+		{"gorm duplicated key", gorm.ErrDuplicatedKey, Error{Kind: ErrDuplicateKey}},
+		{"wrapped", fmt.Errorf("create wallet: %w", &mysql.MySQLError{Number: 1213}), Error{Kind: ErrDeadlock}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Translate(test.err)
+			var e *Error
+			assert.True(t, errors.As(err, &e))
+			assert.Equal(t, test.want.Kind, e.Kind)
+			assert.Equal(t, test.want.Table, e.Table)
+			assert.Equal(t, test.want.Constraint, e.Constraint)
+			assert.Equal(t, test.want.Columns, e.Columns)
+
+			// error asli tetap bisa dicek
+			assert.ErrorIs(t, err, test.want.Kind)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.err.Error(), err.Error())
+			assert.Same(t, e, Translate(err))
+		})
+	}
+
+	// error lain dikembalikan apa adanya
+	other := errors.New("connection refused")
+	assert.Equal(t, other, Translate(other))
+	assert.Equal(t, error(&mysql.MySQLError{Number: 1045}), Translate(&mysql.MySQLError{Number: 1045}))
+	assert.Nil(t, Translate(nil))
+}
+
+func TestStatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusOK, StatusCode(nil))
+	assert.Equal(t, http.StatusNotFound, StatusCode(fmt.Errorf("user 1: %w", gorm.ErrRecordNotFound)))
+	assert.Equal(t, http.StatusConflict, StatusCode(&mysql.MySQLError{Number: 1062}))
+	assert.Equal(t, http.StatusConflict, StatusCode(&pgconn.PgError{Code: "23503"}))
+	assert.Equal(t, http.StatusUnprocessableEntity, StatusCode(&mysql.MySQLError{Number: 1406}))
+	assert.Equal(t, http.StatusServiceUnavailable, StatusCode(&pgconn.PgError{Code: "40P01"}))
+	assert.Equal(t, http.StatusInternalServerError, StatusCode(errors.New("boom")))
+}
+
+type owner struct {
+	ID   string `gorm:"primaryKey;size:20"`
+	Name string
+}
+
+type pet struct {
+	ID      int    `gorm:"primaryKey"`
+	OwnerID string `gorm:"size:20;uniqueIndex:uq_pets_owner_name"`
+	Name    string `gorm:"size:50;uniqueIndex:uq_pets_owner_name"`
+	Age     int    `gorm:"check:chk_pets_age,age >= 0"`
+	Owner   owner
+}
+
+func TestPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared&_foreign_keys=1"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	assert.Nil(t, err)
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	assert.Nil(t, db.Use(Plugin{}))
+	assert.Nil(t, db.AutoMigrate(&owner{}, &pet{}))
+	assert.Nil(t, db.Create(&owner{ID: "1", Name: "Budi"}).Error)
+	assert.Nil(t, db.Create(&pet{ID: 1, OwnerID: "1", Name: "Kitty"}).Error)
+
+	var e *Error
+	err = db.Create(&owner{ID: "1"}).Error
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "owners", e.Table)
+	assert.Equal(t, []string{"id"}, e.Columns)
+	var sqliteErr sqlite3.Error
+	assert.True(t, errors.As(err, &sqliteErr)) // error driver masih ada
+
+	err = db.Create(&pet{ID: 2, OwnerID: "1", Name: "Kitty"}).Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, ErrDuplicateKey, e.Kind)
+	assert.Equal(t, []string{"owner_id", "name"}, e.Columns)
+
+	assert.ErrorIs(t, db.Create(&pet{ID: 3, OwnerID: "404", Name: "Rex"}).Error, ErrForeignKeyViolation)
+	assert.ErrorIs(t, db.Delete(&owner{ID: "1"}).Error, ErrForeignKeyViolation)
+
+	err = db.Model(&pet{ID: 1}).Update("age", -1).Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, ErrCheckViolation, e.Kind)
+	assert.Equal(t, "chk_pets_age", e.Constraint)
+
+	err = db.Take(&pet{}, 404).Error
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, db.Exec("INSERT INTO owners (id, name) VALUES (?, ?)", "1", "Budi").Error, ErrDuplicateKey)
+}
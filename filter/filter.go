@@ -0,0 +1,332 @@
+// Package filter mengubah parameter query string menjadi kondisi dan urutan GORM, hanya untuk field yang
+// didaftarkan di Spec (whitelist), sebagai pengganti kondisi string / map / struct yang dirakit manual:
+//
+//	?name.first=like:User%&wallet.balance=gt:500000&name.middle=null:true&sort=-created_at,name.first
+//
+//	f, err := learn_golang_gorm.UserFilter.Parse(r.URL.Query())
+//	err = db.Scopes(f.Scope).Find(&users).Error
+//
+// Setiap nilai berbentuk "operator:nilai", tanpa operator berarti eq. Berbeda dengan kondisi struct
+// (TestStructCondition), nilai kosong tidak diabaikan: "name.last=eq:" berarti last_name sama dengan string kosong.
+// NULL ditulis eksplisit dengan "null:true" (IS NULL) atau "null:false" (IS NOT NULL).
+//
+// Field dari relasi (misalnya "wallet.balance" dengan Join "Wallet") otomatis di-Joins jika dipakai.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"learn-golang-gorm/pagination"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidFilter = errors.New("filter: invalid filter")
+
+// Op adalah operator yang bisa dipakai di query string
+type Op string
+
+const (
+	Eq   Op = "eq"
+	Ne   Op = "ne"
+	Gt   Op = "gt"
+	Gte  Op = "gte"
+	Lt   Op = "lt"
+	Lte  Op = "lte"
+	Like Op = "like"
+	In   Op = "in"   // nilai dipisah koma, misalnya in:1,2,3
+	Null Op = "null" // null:true atau null:false
+)
+
+// SortKey adalah nama parameter untuk urutan: sort=-created_at,name.first (- berarti DESC)
+const SortKey = "sort"
+
+// MaxInValues membatasi jumlah nilai operator in
+const MaxInValues = 100
+
+// Type menentukan cara nilai dari query string dibaca
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+	Time // RFC 3339 atau tanggal 2006-01-02
+)
+
+// Field adalah satu field yang boleh dipakai untuk filter / sort
+type Field struct {
+	Column   string // nama kolom di tabel model, atau di tabel relasi jika Join diisi
+	Join     string // nama relasi untuk db.Joins, misalnya "Wallet"
+	Type     Type
+	Ops      []Op // operator yang diizinkan, kosong berarti semua yang cocok dengan Type
+	Nullable bool // operator null hanya untuk kolom yang boleh NULL
+	Sortable bool
+}
+
+func (f Field) column() clause.Column {
+	if f.Join != "" {
+		return clause.Column{Table: f.Join, Name: f.Column}
+	}
+	return clause.Column{Table: clause.CurrentTable, Name: f.Column}
+}
+
+func (f Field) allows(op Op) bool {
+	switch {
+	case len(f.Ops) > 0:
+		return slices.Contains(f.Ops, op)
+	case op == Null:
+		return f.Nullable
+	case op == Like:
+		return f.Type == String
+	case op == Gt || op == Gte || op == Lt || op == Lte:
+		return f.Type != Bool
+	}
+	return true
+}
+
+// Operators mengembalikan operator yang boleh dipakai untuk field ini (misalnya untuk dokumentasi API)
+func (f Field) Operators() []Op {
+	var allowed []Op
+	for _, op := range ops {
+		if f.allows(op) {
+			allowed = append(allowed, op)
+		}
+	}
+	return allowed
+}
+
+// Spec adalah whitelist field untuk satu model, key-nya nama parameter di query string
+type Spec struct {
+	Fields map[string]Field
+	// Ignore berisi parameter lain yang boleh ada tetapi bukan filter (misalnya "page", "size").
+	// Parameter yang tidak dikenal ditolak dengan ErrInvalidFilter.
+	Ignore []string
+}
+
+// Condition adalah satu kondisi hasil Parse, Value sudah dikonversi sesuai Field.Type
+type Condition struct {
+	Field string
+	Op    Op
+	Value interface{} // []interface{} untuk In, bool untuk Null
+}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Filter adalah hasil Parse yang siap dipakai sebagai scope
+type Filter struct {
+	Conditions []Condition
+	Sort       []Sort
+	spec       Spec
+}
+
+// ParseQuery sama seperti Parse, dari query string mentah (tanpa "?")
+func (s Spec) ParseQuery(query string) (*Filter, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+	}
+	return s.Parse(values)
+}
+
+// Parse membaca filter dan sort dari values. Parameter yang sama boleh diulang
+// (wallet.balance=gte:1000&wallet.balance=lt:5000), semua kondisinya digabung dengan AND.
+func (s Spec) Parse(values url.Values) (*Filter, error) {
+	f := &Filter{spec: s}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys) // urutan kondisi tetap sama untuk query yang sama
+
+	for _, key := range keys {
+		if key == SortKey {
+			for _, raw := range values[key] {
+				if err := f.parseSort(raw); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		field, ok := s.Fields[key]
+		if !ok {
+			if slices.Contains(s.Ignore, key) {
+				continue
+			}
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidFilter, key)
+		}
+		for _, raw := range values[key] {
+			condition, err := parseCondition(key, field, raw)
+			if err != nil {
+				return nil, err
+			}
+			f.Conditions = append(f.Conditions, condition)
+		}
+	}
+	return f, nil
+}
+
+func (f *Filter) parseSort(raw string) error {
+	for _, name := range strings.Split(raw, ",") {
+		sort := Sort{Field: strings.TrimSpace(name)}
+		if strings.HasPrefix(sort.Field, "-") {
+			sort.Field, sort.Desc = sort.Field[1:], true
+		}
+		if field, ok := f.spec.Fields[sort.Field]; !ok || !field.Sortable {
+			return fmt.Errorf("%w: cannot sort by %q", ErrInvalidFilter, sort.Field)
+		}
+		f.Sort = append(f.Sort, sort)
+	}
+	return nil
+}
+
+var ops = []Op{Eq, Ne, Gt, Gte, Lt, Lte, Like, In, Null}
+
+// parseCondition memisahkan "operator:nilai". Jika bagian sebelum ":" bukan operator
+// (misalnya waktu 10:00), seluruhnya dianggap nilai eq.
+func parseCondition(key string, field Field, raw string) (Condition, error) {
+	condition := Condition{Field: key, Op: Eq}
+	value := raw
+	if prefix, rest, ok := strings.Cut(raw, ":"); ok && slices.Contains(ops, Op(prefix)) {
+		condition.Op, value = Op(prefix), rest
+	}
+	if !field.allows(condition.Op) {
+		return condition, fmt.Errorf("%w: operator %s is not allowed for %s", ErrInvalidFilter, condition.Op, key)
+	}
+
+	var err error
+	switch condition.Op {
+	case Null:
+		condition.Value, err = strconv.ParseBool(value)
+	case In:
+		items := strings.Split(value, ",")
+		if len(items) > MaxInValues {
+			return condition, fmt.Errorf("%w: %s: more than %d values", ErrInvalidFilter, key, MaxInValues)
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			if list[i], err = parseValue(field.Type, item); err != nil {
+				break
+			}
+		}
+		condition.Value = list
+	default:
+		condition.Value, err = parseValue(field.Type, value)
+	}
+	if err != nil {
+		return condition, fmt.Errorf("%w: %s: %q: %w", ErrInvalidFilter, key, value, err)
+	}
+	return condition, nil
+}
+
+func parseValue(typ Type, value string) (interface{}, error) {
+	switch typ {
+	case Int:
+		return strconv.ParseInt(value, 10, 64)
+	case Float:
+		return strconv.ParseFloat(value, 64)
+	case Bool:
+		return strconv.ParseBool(value)
+	case Time:
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, nil
+		}
+		return time.ParseInLocation(time.DateOnly, value, time.Local)
+	}
+	return value, nil
+}
+
+func (c Condition) expression(field Field) clause.Expression {
+	column := field.column()
+	switch c.Op {
+	case Ne:
+		return clause.Neq{Column: column, Value: c.Value}
+	case Gt:
+		return clause.Gt{Column: column, Value: c.Value}
+	case Gte:
+		return clause.Gte{Column: column, Value: c.Value}
+	case Lt:
+		return clause.Lt{Column: column, Value: c.Value}
+	case Lte:
+		return clause.Lte{Column: column, Value: c.Value}
+	case Like:
+		return clause.Like{Column: column, Value: c.Value}
+	case In:
+		return clause.IN{Column: column, Values: c.Value.([]interface{})}
+	case Null:
+		if c.Value.(bool) {
+			return clause.Eq{Column: column, Value: nil} // IS NULL
+		}
+		return clause.Neq{Column: column, Value: nil} // IS NOT NULL
+	}
+	return clause.Eq{Column: column, Value: c.Value}
+}
+
+// Scope menambahkan Joins, Where dan Order ke db, dipakai lewat db.Scopes(f.Scope).
+// Relasi yang sudah di-Joins oleh db tidak di-Joins ulang.
+func (f *Filter) Scope(db *gorm.DB) *gorm.DB {
+	var joins []string
+	join := func(field Field) {
+		if field.Join != "" && !slices.Contains(joins, field.Join) {
+			joins = append(joins, field.Join)
+		}
+	}
+	expressions := make([]clause.Expression, 0, len(f.Conditions))
+	for _, condition := range f.Conditions {
+		field := f.spec.Fields[condition.Field]
+		join(field)
+		expressions = append(expressions, condition.expression(field))
+	}
+	for _, sort := range f.Sort {
+		join(f.spec.Fields[sort.Field])
+	}
+
+	for _, name := range joins {
+		if !joined(db, name) {
+			db = db.Joins(name)
+		}
+	}
+	if len(expressions) > 0 {
+		db = db.Where(clause.And(expressions...))
+	}
+	for _, sort := range f.Sort {
+		db = db.Order(clause.OrderByColumn{Column: f.spec.Fields[sort.Field].column(), Desc: sort.Desc})
+	}
+	return db
+}
+
+// CursorRequest memindahkan Sort ke pagination.CursorRequest, karena dengan cursor urutan diatur oleh
+// pagination.Cursor (Scope tidak lagi menambahkan Order). Sort dengan field relasi ditolak, cursor hanya
+// berisi kolom tabel model.
+func (f *Filter) CursorRequest(size int, after, before string) (pagination.CursorRequest, error) {
+	req := pagination.CursorRequest{Size: size, After: after, Before: before}
+	for _, sort := range f.Sort {
+		field := f.spec.Fields[sort.Field]
+		if field.Join != "" {
+			return req, fmt.Errorf("%w: cannot sort by %q with cursor pagination", ErrInvalidFilter, sort.Field)
+		}
+		req.Sort = append(req.Sort, pagination.Order{Column: field.Column, Desc: sort.Desc})
+	}
+	f.Sort = nil
+	return req, nil
+}
+
+func joined(db *gorm.DB, name string) bool {
+	for _, join := range db.Statement.Joins {
+		if join.Name == name {
+			return true
+		}
+	}
+	return false
+}
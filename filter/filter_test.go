@@ -0,0 +1,155 @@
+package filter
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"learn-golang-gorm/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type shelter struct {
+	ID   int `gorm:"primaryKey"`
+	City string
+}
+
+type pet struct {
+	ID        int `gorm:"primaryKey"`
+	Name      string
+	Nickname  *string
+	Age       int
+	Adopted   bool
+	BornAt    time.Time
+	ShelterID int
+	Shelter   shelter
+}
+
+var petFilter = Spec{
+	Fields: map[string]Field{
+		"name":         {Column: "name", Sortable: true},
+		"nickname":     {Column: "nickname", Nullable: true},
+		"age":          {Column: "age", Type: Int, Sortable: true},
+		"adopted":      {Column: "adopted", Type: Bool},
+		"born_at":      {Column: "born_at", Type: Time},
+		"shelter.city": {Join: "Shelter", Column: "city", Ops: []Op{Eq, In}, Sortable: true},
+	},
+	Ignore: []string{"page"},
+}
+
+func TestParse(t *testing.T) {
+	f, err := petFilter.ParseQuery("name=like:K%25&age=gte:2&age=lt:10&nickname=null:true&born_at=2024-01-01T10:00:00Z&page=2&sort=-age,name")
+	assert.Nil(t, err)
+	assert.Equal(t, []Condition{
+		{Field: "age", Op: Gte, Value: int64(2)},
+		{Field: "age", Op: Lt, Value: int64(10)},
+		{Field: "born_at", Op: Eq, Value: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}, // "10:" bukan operator
+		{Field: "name", Op: Like, Value: "K%"},
+		{Field: "nickname", Op: Null, Value: true},
+	}, f.Conditions)
+	assert.Equal(t, []Sort{{Field: "age", Desc: true}, {Field: "name"}}, f.Sort)
+
+	// nilai kosong tetap menjadi kondisi, eq:like:x berarti nilai "like:x"
+	f, err = petFilter.Parse(url.Values{"name": {"eq:", "eq:like:x"}, "shelter.city": {"in:Bandung,Jakarta"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []Condition{
+		{Field: "name", Op: Eq, Value: ""},
+		{Field: "name", Op: Eq, Value: "like:x"},
+		{Field: "shelter.city", Op: In, Value: []interface{}{"Bandung", "Jakarta"}},
+	}, f.Conditions)
+
+	for _, query := range []string{
+		"password=secret",       // field tidak ada di whitelist
+		"age=abc",               // bukan angka
+		"age=like:1%25",         // like hanya untuk string
+		"name=null:true",        // kolom tidak nullable
+		"shelter.city=ne:Bogor", // operator tidak diizinkan
+		"adopted=gt:true",       // bool tidak bisa dibandingkan
+		"born_at=gte:kemarin",   // bukan waktu
+		"sort=nickname",         // tidak sortable
+		"sort=-password",        // tidak ada
+		"nickname=null:mungkin", // bukan bool
+		"name=%zz",              // query string rusak
+	} {
+		_, err := petFilter.ParseQuery(query)
+		assert.ErrorIs(t, err, ErrInvalidFilter, query)
+	}
+}
+
+func TestOperators(t *testing.T) {
+	assert.Equal(t, []Op{Eq, Ne, Gt, Gte, Lt, Lte, Like, In}, petFilter.Fields["name"].Operators())
+	assert.Equal(t, []Op{Eq, Ne, Gt, Gte, Lt, Lte, Like, In, Null}, petFilter.Fields["nickname"].Operators())
+	assert.Equal(t, []Op{Eq, Ne, In}, petFilter.Fields["adopted"].Operators())
+	assert.Equal(t, []Op{Eq, In}, petFilter.Fields["shelter.city"].Operators())
+}
+
+func TestCursorRequest(t *testing.T) {
+	f, err := petFilter.ParseQuery("age=gte:2&sort=-age,name")
+	assert.Nil(t, err)
+	req, err := f.CursorRequest(10, "next", "")
+	assert.Nil(t, err)
+	assert.Equal(t, pagination.CursorRequest{
+		Sort: []pagination.Order{{Column: "age", Desc: true}, {Column: "name"}}, Size: 10, After: "next",
+	}, req)
+	assert.Nil(t, f.Sort) // urutan diatur oleh pagination.Cursor, bukan Scope
+	assert.Len(t, f.Conditions, 1)
+
+	// cursor hanya berisi kolom tabel model
+	f, err = petFilter.ParseQuery("sort=shelter.city")
+	assert.Nil(t, err)
+	_, err = f.CursorRequest(10, "", "")
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+}
+
+func TestScope(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	assert.Nil(t, err)
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	assert.Nil(t, db.AutoMigrate(&shelter{}, &pet{}))
+
+	kitty := "Kit"
+	born := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	assert.Nil(t, db.Create(&[]shelter{{ID: 1, City: "Bandung"}, {ID: 2, City: "Jakarta"}}).Error)
+	assert.Nil(t, db.Create(&[]pet{
+		{ID: 1, Name: "Kitty", Nickname: &kitty, Age: 3, BornAt: born, ShelterID: 1},
+		{ID: 2, Name: "Kiki", Age: 1, Adopted: true, BornAt: born.AddDate(2, 0, 0), ShelterID: 2},
+		{ID: 3, Name: "Rex", Age: 7, BornAt: born.AddDate(-4, 0, 0), ShelterID: 2},
+		{ID: 4, Name: "", Age: 0, BornAt: born, ShelterID: 1},
+	}).Error)
+
+	find := func(query string) []int {
+		t.Helper()
+		f, err := petFilter.ParseQuery(query)
+		assert.Nil(t, err, query)
+		var ids []int
+		assert.Nil(t, db.Model(&pet{}).Scopes(f.Scope).Pluck("pets.id", &ids).Error, query)
+		return ids
+	}
+
+	assert.Equal(t, []int{2, 1}, find("name=like:K%25&sort=name"))
+	assert.Equal(t, []int{3, 1}, find("age=gte:2&sort=-age"))
+	assert.Equal(t, []int{4, 2, 3}, find("nickname=null:true&sort=name"))
+	assert.Equal(t, []int{1}, find("nickname=null:false"))
+	assert.Equal(t, []int{4}, find("name=eq:&age=0")) // nilai kosong dan 0 tidak diabaikan
+	assert.Equal(t, []int{2}, find("adopted=true"))
+	assert.Equal(t, []int{2}, find("born_at=gt:2021-01-01"))
+
+	// field relasi otomatis di-Joins, termasuk hanya untuk sort
+	assert.Equal(t, []int{2, 3}, find("shelter.city=Jakarta&sort=name"))
+	assert.Equal(t, []int{2, 3, 4, 1}, find("shelter.city=in:Bandung,Jakarta&sort=-shelter.city,age"))
+
+	// relasi yang sudah di-Joins tidak di-Joins ulang
+	f, err := petFilter.ParseQuery("shelter.city=Bandung&sort=age")
+	assert.Nil(t, err)
+	var pets []pet
+	assert.Nil(t, db.Joins("Shelter").Scopes(f.Scope).Find(&pets).Error)
+	assert.Equal(t, 2, len(pets))
+	assert.Equal(t, "Bandung", pets[0].Shelter.City)
+	assert.Equal(t, 4, pets[0].ID)
+}
@@ -0,0 +1,78 @@
+package learn_golang_gorm
+
+import "learn-golang-gorm/filter"
+
+// Filter yang boleh dipakai dari query string (lihat package filter), misalnya untuk API list:
+//
+//	f, err := UserFilter.Parse(r.URL.Query())
+//	page, err := pagination.Offset[User](ctx, users.DB(ctx).Scopes(f.Scope), 1, 20)
+
+// pagingParams bukan filter, tetapi boleh ada di query string yang sama
+var pagingParams = []string{"page", "size", "after", "before"}
+
+var UserFilter = filter.Spec{
+	Fields: map[string]filter.Field{
+		"id":          {Column: "id", Ops: []filter.Op{filter.Eq, filter.In}, Sortable: true},
+		"name.first":  {Column: "first_name", Sortable: true},
+		"name.middle": {Column: "middle_name", Nullable: true, Sortable: true},
+		"name.last":   {Column: "last_name", Nullable: true, Sortable: true},
+		"created_at":  {Column: "created_at", Type: filter.Time, Sortable: true},
+		// dari relasi Wallet (LEFT JOIN), user tanpa wallet punya balance NULL
+		"wallet.balance":  {Join: "Wallet", Column: "balance_amount", Type: filter.Int, Nullable: true, Sortable: true},
+		"wallet.currency": {Join: "Wallet", Column: "balance_currency", Ops: []filter.Op{filter.Eq, filter.In}},
+	},
+	Ignore: pagingParams,
+}
+
+var WalletFilter = filter.Spec{
+	Fields: map[string]filter.Field{
+		"id":              {Column: "id", Ops: []filter.Op{filter.Eq, filter.In}, Sortable: true},
+		"user_id":         {Column: "user_id", Ops: []filter.Op{filter.Eq, filter.In}},
+		"balance":         {Column: "balance_amount", Type: filter.Int, Sortable: true},
+		"currency":        {Column: "balance_currency", Ops: []filter.Op{filter.Eq, filter.In}},
+		"created_at":      {Column: "created_at", Type: filter.Time, Sortable: true},
+		"user.name.first": {Join: "User", Column: "first_name", Sortable: true},
+	},
+	Ignore: pagingParams,
+}
+
+var AddressFilter = filter.Spec{
+	Fields: map[string]filter.Field{
+		"id":         {Column: "id", Type: filter.Int, Ops: []filter.Op{filter.Eq, filter.In}, Sortable: true},
+		"user_id":    {Column: "user_id", Ops: []filter.Op{filter.Eq, filter.In}},
+		"address":    {Column: "address", Sortable: true},
+		"created_at": {Column: "created_at", Type: filter.Time, Sortable: true},
+	},
+	Ignore: pagingParams,
+}
+
+// harga product disimpan sebagai teks ("IDR 1000000"), jadi tidak bisa difilter atau diurutkan sebagai angka
+var ProductFilter = filter.Spec{
+	Fields: map[string]filter.Field{
+		"id":         {Column: "id", Ops: []filter.Op{filter.Eq, filter.In}, Sortable: true},
+		"name":       {Column: "name", Sortable: true},
+		"created_at": {Column: "created_at", Type: filter.Time, Sortable: true},
+	},
+	Ignore: pagingParams,
+}
+
+// todo yang sudah di-soft delete tidak ikut, sama seperti query Todo biasa
+var TodoFilter = filter.Spec{
+	Fields: map[string]filter.Field{
+		"id":         {Column: "id", Type: filter.Int, Ops: []filter.Op{filter.Eq, filter.In}, Sortable: true},
+		"user_id":    {Column: "user_id", Ops: []filter.Op{filter.Eq, filter.In}},
+		"title":      {Column: "title", Sortable: true},
+		"created_at": {Column: "created_at", Type: filter.Time, Sortable: true},
+	},
+	Ignore: pagingParams,
+}
+
+var GuestBookFilter = filter.Spec{
+	Fields: map[string]filter.Field{
+		"id":         {Column: "id", Type: filter.Int, Ops: []filter.Op{filter.Eq, filter.In}, Sortable: true},
+		"name":       {Column: "name", Sortable: true},
+		"email":      {Column: "email", Sortable: true},
+		"created_at": {Column: "created_at", Type: filter.Time, Sortable: true},
+	},
+	Ignore: pagingParams,
+}
@@ -0,0 +1,72 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"testing"
+
+	"learn-golang-gorm/filter"
+	"learn-golang-gorm/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserFilter(t *testing.T) {
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
+	find := func(query string) []string {
+		t.Helper()
+		f, err := UserFilter.ParseQuery(query)
+		assert.Nil(t, err, query)
+		var ids []string
+		assert.Nil(t, db.Model(&User{}).Scopes(f.Scope).Pluck("users.id", &ids).Error, query)
+		return ids
+	}
+
+	// seperti TestStructCondition, tetapi last_name = '' ikut menjadi kondisi
+	assert.Equal(t, []string{"5"}, find("name.first=User5&name.last=eq:"))
+	assert.Empty(t, find("name.first=User5&name.last=eq:Vex"))
+	assert.Equal(t, []string{"1"}, find("name.first=like:Lev%25"))
+
+	// TestMapCondition: middle_name kosong bukan NULL
+	assert.Equal(t, 15, len(find("name.middle=eq:")))
+	assert.Empty(t, find("name.middle=null:true"))
+	assert.Nil(t, db.Exec("UPDATE users SET middle_name = NULL WHERE id = ?", "3").Error)
+	assert.Equal(t, []string{"3"}, find("name.middle=null:true"))
+
+	// field dari relasi Wallet, seperti TestCount
+	assert.Equal(t, []string{"1", "2", "20", "21"}, find("wallet.balance=gt:500000&sort=id"))
+	assert.Equal(t, []string{"2", "1"}, find("wallet.balance=gte:1000000&id=in:1,2&sort=-name.first"))
+	assert.Equal(t, 12, len(find("wallet.balance=null:true"))) // user tanpa wallet
+
+	_, err := UserFilter.ParseQuery("password=secret")
+	assert.ErrorIs(t, err, filter.ErrInvalidFilter)
+}
+
+func TestFilterWithPagination(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets", "addresses")
+
+	// sort dari filter dipakai, bukan urutan primary key bawaan Offset
+	f, err := UserFilter.ParseQuery("wallet.currency=IDR&sort=-wallet.balance,-id&page=1&size=3")
+	assert.Nil(t, err)
+	query := NewUserRepository(db).DB(ctx).Preload("Addresses").Joins("Wallet").Scopes(f.Scope)
+	page, err := pagination.Offset[User](ctx, query, 2, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), page.Total)
+	assert.Equal(t, []string{"1"}, []string{page.Items[0].ID})
+	assert.Equal(t, 2, len(page.Items[0].Addresses))
+	page, err = pagination.Offset[User](ctx, query, 1, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"21", "20", "2"}, []string{page.Items[0].ID, page.Items[1].ID, page.Items[2].ID})
+
+	// "User 20" dan "User 21" (dengan spasi) lebih dulu dari "User2"
+	f, err = WalletFilter.ParseQuery("user.name.first=like:User%25&sort=-user.name.first")
+	assert.Nil(t, err)
+	var wallets []Wallet
+	assert.Nil(t, db.Scopes(f.Scope).Find(&wallets).Error)
+	assert.Equal(t, 3, len(wallets))
+	assert.Equal(t, []string{"2", "21", "20"}, []string{wallets[0].ID, wallets[1].ID, wallets[2].ID})
+	assert.Equal(t, "User2", wallets[0].User.Name.FirstName)
+}
@@ -0,0 +1,405 @@
+// Package fixtures memuat data dari file YAML/JSON ke database, untuk test maupun seed data development.
+//
+// Format file: key paling luar adalah nama tabel, lalu label record, lalu kolom-kolomnya.
+//
+//	users:
+//	  lev:
+//	    id: "1"
+//	    name:                # kolom embedded boleh ditulis bertingkat
+//	      first_name: Lev
+//	    password: secret
+//	wallets:
+//	  lev_wallet:
+//	    id: "1"
+//	    user_id: $lev        # $label diganti dengan primary key record lev
+//	    balance_amount: 1000000
+//	user_like_product:       # tabel tanpa model (misalnya tabel penghubung many2many) juga bisa
+//	  lev_likes_p001:
+//	    user_id: $lev
+//	    product_id: $p001
+//
+// Record dimasukkan sesuai urutan dependency ($label), jadi urutan di file tidak penting.
+// Record untuk tabel yang modelnya didaftarkan dibuat lewat model tersebut, sehingga hook GORM
+// (misalnya BeforeCreate) tetap berjalan.
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	ErrUnknownLabel = errors.New("fixtures: unknown label")
+	ErrCycle        = errors.New("fixtures: circular reference")
+)
+
+// Loader menyimpan daftar model yang dikenal, dipetakan berdasarkan nama tabel
+type Loader struct {
+	db     *gorm.DB
+	tables []string // urutan tabel sesuai foreign key (induk lebih dulu)
+	models map[string]*schema.Schema
+
+	// IgnoreExisting melewati record yang primary key-nya sudah ada (ON CONFLICT DO NOTHING),
+	// cocok untuk seed data yang dijalankan berulang kali
+	IgnoreExisting bool
+}
+
+// New membuat Loader. models sebaiknya diurutkan dari tabel induk ke tabel anak,
+// karena Reset menghapus isi tabel dengan urutan terbalik.
+func New(db *gorm.DB, models ...interface{}) (*Loader, error) {
+	loader := &Loader{db: db, models: map[string]*schema.Schema{}}
+	var joinTables []string
+	for _, model := range models {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			return nil, fmt.Errorf("fixtures: parse model %T: %w", model, err)
+		}
+		s := statement.Schema
+		loader.models[s.Table] = s
+		loader.tables = append(loader.tables, s.Table)
+
+		for _, relation := range s.Relationships.Many2Many {
+			if relation.JoinTable != nil {
+				joinTables = append(joinTables, relation.JoinTable.Table)
+			}
+		}
+	}
+	for _, table := range joinTables {
+		if !loader.knows(table) {
+			loader.tables = append(loader.tables, table)
+		}
+	}
+	return loader, nil
+}
+
+func (l *Loader) knows(table string) bool {
+	for _, known := range l.tables {
+		if known == table {
+			return true
+		}
+	}
+	return false
+}
+
+type record struct {
+	table   string
+	label   string
+	columns map[string]interface{}
+	order   []string    // urutan kolom sesuai file, supaya pesan error konsisten
+	value   interface{} // model yang sudah dibuat (pointer) atau map untuk tabel tanpa model
+	schema  *schema.Schema
+}
+
+// Set berisi record hasil Load, bisa diambil berdasarkan label
+type Set struct {
+	records map[string]*record
+}
+
+// Get mengembalikan record dengan label tersebut: pointer ke model, atau map[string]interface{}
+// untuk tabel tanpa model
+func (s *Set) Get(label string) interface{} {
+	if r, ok := s.records[label]; ok {
+		return r.value
+	}
+	return nil
+}
+
+// LoadFiles memuat satu atau beberapa file .yml/.yaml/.json sekaligus,
+// sehingga label di satu file bisa dipakai oleh file lain
+func (l *Loader) LoadFiles(ctx context.Context, paths ...string) (*Set, error) {
+	return l.load(ctx, func(name string) ([]byte, error) { return os.ReadFile(name) }, paths)
+}
+
+// LoadFS sama seperti LoadFiles tetapi membaca dari fsys (misalnya embed.FS)
+func (l *Loader) LoadFS(ctx context.Context, fsys fs.FS, paths ...string) (*Set, error) {
+	return l.load(ctx, func(name string) ([]byte, error) { return fs.ReadFile(fsys, name) }, paths)
+}
+
+func (l *Loader) load(ctx context.Context, read func(string) ([]byte, error), paths []string) (*Set, error) {
+	var records []*record
+	for _, path := range paths {
+		data, err := read(path)
+		if err != nil {
+			return nil, fmt.Errorf("fixtures: %w", err)
+		}
+		parsed, err := parse(path, data)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, parsed...)
+	}
+
+	set := &Set{records: map[string]*record{}}
+	for _, r := range records {
+		if _, exists := set.records[r.label]; exists {
+			return nil, fmt.Errorf("fixtures: label %q is defined more than once", r.label)
+		}
+		set.records[r.label] = r
+	}
+
+	ordered, err := sortByDependency(records, set)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, r := range ordered {
+			if err := l.insert(tx, set, r); err != nil {
+				return fmt.Errorf("fixtures: %s.%s: %w", r.table, r.label, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func parse(path string, data []byte) ([]*record, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".yml" && ext != ".yaml" && ext != ".json" {
+		return nil, fmt.Errorf("fixtures: %s: unsupported extension, use .yml, .yaml or .json", path)
+	}
+
+	// JSON adalah bagian dari YAML, jadi keduanya dibaca dengan parser yang sama.
+	// yaml.Node dipakai supaya urutan tabel dan record sesuai isi file.
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("fixtures: parse %s: %w", path, err)
+	}
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("fixtures: %s: top level must be a map of table name", path)
+	}
+
+	var records []*record
+	for i := 0; i < len(root.Content); i += 2 {
+		table, labels := root.Content[i].Value, root.Content[i+1]
+		if labels.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("fixtures: %s: %s must be a map of label", path, table)
+		}
+		for j := 0; j < len(labels.Content); j += 2 {
+			label, body := labels.Content[j].Value, labels.Content[j+1]
+			r := &record{table: table, label: label, columns: map[string]interface{}{}}
+			if err := r.readColumns(body); err != nil {
+				return nil, fmt.Errorf("fixtures: %s: %s.%s: %w", path, table, label, err)
+			}
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+// readColumns membaca kolom record. Map bertingkat (misalnya name: {first_name: Lev})
+// diratakan karena kolom struct embedded tersimpan langsung di tabel induknya.
+func (r *record) readColumns(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errors.New("record must be a map of column")
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		if value.Kind == yaml.MappingNode {
+			if err := r.readColumns(value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var decoded interface{}
+		if err := value.Decode(&decoded); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if _, exists := r.columns[key]; exists {
+			return fmt.Errorf("column %s is defined more than once", key)
+		}
+		r.columns[key] = decoded
+		r.order = append(r.order, key)
+	}
+	return nil
+}
+
+// reference mengembalikan label dan kolom dari nilai "$label" atau "$label.column"
+func reference(value interface{}) (label, column string, ok bool) {
+	text, isString := value.(string)
+	if !isString || !strings.HasPrefix(text, "$") || len(text) == 1 {
+		return "", "", false
+	}
+	label, column, _ = strings.Cut(text[1:], ".")
+	return label, column, true
+}
+
+// sortByDependency mengurutkan record supaya record yang direferensikan dibuat lebih dulu,
+// selebihnya tetap mengikuti urutan di file
+func sortByDependency(records []*record, set *Set) ([]*record, error) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[*record]int{}
+	ordered := make([]*record, 0, len(records))
+
+	var visit func(r *record) error
+	visit = func(r *record) error {
+		switch state[r] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w at %s.%s", ErrCycle, r.table, r.label)
+		}
+		state[r] = visiting
+		for _, column := range r.order {
+			label, _, ok := reference(r.columns[column])
+			if !ok {
+				continue
+			}
+			dependency, exists := set.records[label]
+			if !exists {
+				return fmt.Errorf("%w %q in %s.%s", ErrUnknownLabel, label, r.table, r.label)
+			}
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		state[r] = done
+		ordered = append(ordered, r)
+		return nil
+	}
+
+	for _, r := range records {
+		if err := visit(r); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+func (l *Loader) insert(tx *gorm.DB, set *Set, r *record) error {
+	values := make(map[string]interface{}, len(r.columns))
+	for _, column := range r.order {
+		value := r.columns[column]
+		if label, field, ok := reference(value); ok {
+			resolved, err := set.resolve(label, field)
+			if err != nil {
+				return err
+			}
+			value = resolved
+		}
+		values[column] = value
+	}
+
+	if l.IgnoreExisting {
+		tx = tx.Clauses(clause.OnConflict{DoNothing: true})
+	}
+
+	s, ok := l.models[r.table]
+	if !ok {
+		// tabel tanpa model, misalnya tabel penghubung user_like_product
+		// Create diberi salinan karena GORM menambahkan key "@id" ke map yang dimasukkan
+		r.value = values
+		inserted := make(map[string]interface{}, len(values))
+		for column, value := range values {
+			inserted[column] = value
+		}
+		return tx.Table(r.table).Create(inserted).Error
+	}
+
+	model := reflect.New(s.ModelType)
+	for _, column := range r.order {
+		field := s.LookUpField(column)
+		if field == nil || field.DBName == "" {
+			return fmt.Errorf("unknown column %s", column)
+		}
+		value, err := convert(field, values[column])
+		if err != nil {
+			return fmt.Errorf("%s: %w", column, err)
+		}
+		if err := field.Set(tx.Statement.Context, model.Elem(), value); err != nil {
+			return fmt.Errorf("%s: %w", column, err)
+		}
+	}
+
+	r.value, r.schema = model.Interface(), s
+	return tx.Omit(clause.Associations).Create(r.value).Error
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	timeLayouts   = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+)
+
+// convert mengubah teks tanggal menjadi time.Time untuk kolom bertipe waktu
+func convert(field *schema.Field, value interface{}) (interface{}, error) {
+	text, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	fieldType := field.FieldType
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType != timeType && fieldType != deletedAtType {
+		return value, nil
+	}
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, text); err == nil {
+			return parsed, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid time value %q", text)
+}
+
+// resolve mengambil nilai kolom (default: primary key) dari record yang sudah dibuat
+func (s *Set) resolve(label, column string) (interface{}, error) {
+	r, ok := s.records[label]
+	if !ok || r.value == nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownLabel, label)
+	}
+
+	if r.schema == nil {
+		if column == "" {
+			return nil, fmt.Errorf("%s has no model, refer to a column with $%s.column", label, label)
+		}
+		return r.value.(map[string]interface{})[column], nil
+	}
+
+	field := r.schema.PrioritizedPrimaryField
+	if column != "" {
+		field = r.schema.LookUpField(column)
+	}
+	if field == nil {
+		return nil, fmt.Errorf("%s has no column %q", label, column)
+	}
+	value, _ := field.ValueOf(context.Background(), reflect.ValueOf(r.value).Elem())
+	return value, nil
+}
+
+// Reset menghapus semua isi tabel yang dikenal Loader (tabel anak lebih dulu),
+// dipakai untuk membersihkan data di antara dua kali load
+func (l *Loader) Reset(ctx context.Context) error {
+	return l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := len(l.tables) - 1; i >= 0; i-- {
+			if err := tx.Exec("DELETE FROM " + tx.Statement.Quote(l.tables[i])).Error; err != nil {
+				return fmt.Errorf("fixtures: reset %s: %w", l.tables[i], err)
+			}
+		}
+		return nil
+	})
+}
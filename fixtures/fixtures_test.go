@@ -0,0 +1,158 @@
+package fixtures
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"learn-golang-gorm/internal/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type PenName struct {
+	First string `gorm:"column:first_name"`
+	Last  string `gorm:"column:last_name"`
+}
+
+type Author struct {
+	ID      string  `gorm:"primaryKey;column:id"`
+	Name    PenName `gorm:"embedded"`
+	Created bool    `gorm:"-"`
+	Books   []Book  `gorm:"foreignKey:author_id;references:id"`
+}
+
+// BeforeCreate dipakai untuk memastikan hook model tetap berjalan
+func (a *Author) BeforeCreate(tx *gorm.DB) error {
+	a.Created = true
+	return nil
+}
+
+type Book struct {
+	ID        int64          `gorm:"primaryKey;column:id;autoIncrement"`
+	AuthorID  string         `gorm:"column:author_id"`
+	Title     string         `gorm:"column:title"`
+	Tags      []Tag          `gorm:"many2many:book_tags;foreignKey:id;joinForeignKey:book_id;references:id;joinReferences:tag_id"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+type Tag struct {
+	ID   string `gorm:"primaryKey;column:id"`
+	Name string `gorm:"column:name"`
+}
+
+func openSQLite(t *testing.T) *gorm.DB {
+	return testdb.Memory(t, &Author{}, &Book{}, &Tag{})
+}
+
+var files = fstest.MapFS{
+	// books ditulis lebih dulu, tapi tetap dibuat setelah authors karena ada referensi $lev
+	"books.yml": {Data: []byte(`
+books:
+  first_book:
+    author_id: $lev
+    title: First Book
+  deleted_book:
+    author_id: $lev.id
+    title: Deleted Book
+    deleted_at: 2024-01-02T03:04:05Z
+`)},
+	"authors.json": {Data: []byte(`{"authors": {"lev": {"id": "1", "name": {"first_name": "Lev", "last_name": "Vex"}}}}`)},
+	"tags.yml": {Data: []byte(`
+tags:
+  go: {id: go, name: Golang}
+book_tags:
+  first_book_go: {book_id: $first_book, tag_id: $go}
+`)},
+}
+
+func TestLoadResolvesReferences(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	loader, err := New(db, &Author{}, &Book{}, &Tag{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"authors", "books", "tags", "book_tags"}, loader.tables)
+
+	set, err := loader.LoadFS(ctx, files, "books.yml", "authors.json", "tags.yml")
+	assert.Nil(t, err)
+
+	lev := set.Get("lev").(*Author)
+	assert.Equal(t, PenName{First: "Lev", Last: "Vex"}, lev.Name)
+	assert.True(t, lev.Created)
+
+	// id auto increment dari book dipakai oleh tabel penghubung
+	book := set.Get("first_book").(*Book)
+	assert.NotZero(t, book.ID)
+	assert.Equal(t, map[string]interface{}{"book_id": book.ID, "tag_id": "go"}, set.Get("first_book_go"))
+
+	var author Author
+	assert.Nil(t, db.Preload("Books.Tags").Take(&author, "id = ?", "1").Error)
+	assert.Equal(t, 1, len(author.Books)) // deleted_book sudah di-soft delete
+	assert.Equal(t, "Golang", author.Books[0].Tags[0].Name)
+
+	var deleted Book
+	assert.Nil(t, db.Unscoped().Take(&deleted, "title = ?", "Deleted Book").Error)
+	assert.True(t, deleted.DeletedAt.Time.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+}
+
+func TestLoadErrors(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	loader, err := New(db, &Author{}, &Book{}, &Tag{})
+	assert.Nil(t, err)
+
+	// authors.json tidak ikut dimuat, jadi $lev tidak dikenal
+	_, err = loader.LoadFS(ctx, files, "books.yml")
+	assert.ErrorIs(t, err, ErrUnknownLabel)
+
+	cycle := fstest.MapFS{"cycle.yml": {Data: []byte(`
+authors:
+  a: {id: $b.id}
+  b: {id: $a.id}
+`)}}
+	_, err = loader.LoadFS(ctx, cycle, "cycle.yml")
+	assert.ErrorIs(t, err, ErrCycle)
+
+	unknown := fstest.MapFS{"unknown.yml": {Data: []byte("authors:\n  a: {id: x, nickname: y}\n")}}
+	_, err = loader.LoadFS(ctx, unknown, "unknown.yml")
+	assert.ErrorContains(t, err, "unknown column nickname")
+
+	_, err = loader.LoadFS(ctx, files, "authors.json", "authors.json")
+	assert.ErrorContains(t, err, "defined more than once")
+
+	// load yang gagal tidak meninggalkan data apa pun
+	var count int64
+	assert.Nil(t, db.Model(&Author{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestResetAndIgnoreExisting(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	loader, err := New(db, &Author{}, &Book{}, &Tag{})
+	assert.Nil(t, err)
+
+	_, err = loader.LoadFS(ctx, files, "authors.json")
+	assert.Nil(t, err)
+	_, err = loader.LoadFS(ctx, files, "authors.json")
+	assert.NotNil(t, err) // primary key sudah ada
+
+	loader.IgnoreExisting = true
+	_, err = loader.LoadFS(ctx, files, "authors.json", "books.yml", "tags.yml")
+	assert.Nil(t, err)
+
+	assert.Nil(t, loader.Reset(ctx))
+	for _, table := range loader.tables {
+		var count int64
+		assert.Nil(t, db.Table(table).Count(&count).Error)
+		assert.Equal(t, int64(0), count, table)
+	}
+
+	// setelah reset, fixture bisa dimuat ulang
+	loader.IgnoreExisting = false
+	_, err = loader.LoadFS(ctx, files, "authors.json", "books.yml", "tags.yml")
+	assert.Nil(t, err)
+}
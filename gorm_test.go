@@ -5,45 +5,24 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
-	"time"
+
+	"learn-golang-gorm/dberrors"
+	"learn-golang-gorm/money"
 
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
-	"gorm.io/gorm/logger"
 )
 
-func OpenConnection() *gorm.DB {
-	dialect := mysql.Open("root:@tcp(localhost:3306)/learn_golang_gorm?charset=utf8mb4&parseTime=True&loc=Local")
-	db, err := gorm.Open(dialect, &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info), // Set log level to Info
-		SkipDefaultTransaction: true,				// Disable default transaction
-		PrepareStmt: true,							// Enable prepare statement
-	})
-	if err != nil {
-		panic(err)		
-	}
-
-	sqlDB, err := db.DB()
-	if err != nil {
-		panic(err)
-	}
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxIdleTime(5 * time.Minute)
-	sqlDB.SetConnMaxLifetime(30 * time.Minute)
-
-	return db
-}
-
-var db = OpenConnection()
-
 func TestOpenConnection(t *testing.T) {
+	db := newTestDB(t)
+
 	assert.NotNil(t, db)
 }
 
 func TestExecuteSQL(t *testing.T){
+	db := newTestDB(t)
+
 	err := db.Exec("insert into sample(id,name) values(?, ?)", "1", "Lev").Error
 	assert.Nil(t, err)
 
@@ -63,6 +42,9 @@ type Sample struct {
 }
 
 func TestRawSQL(t *testing.T) {
+	db := newTestDB(t)
+	loadFixtures(t, db, "samples")
+
 	var sample Sample
 	err := db.Raw("select id, name from sample where id = ?", "1").Scan(&sample).Error
 	assert.Nil(t, err)
@@ -78,6 +60,9 @@ func TestRawSQL(t *testing.T) {
 // Menggunakan Rows untuk mendapatkan hasil query
 // Rows adalah metode yang digunakan untuk mendapatkan hasil query dalam bentuk baris.
 func TestSqlRow(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "samples")
+
 	rows, err := db.Raw("select id, name from sample").Rows()
 	assert.Nil(t, err)
 	defer rows.Close()
@@ -96,6 +81,9 @@ func TestSqlRow(t *testing.T){
 // ScanRows adalah metode yang disediakan oleh GORM untuk memindai hasil query ke dalam slice atau struct.
 // Ini mengurangi boilerplate code yang diperlukan untuk memindai setiap baris secara manual.
 func TestScanRow(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "samples")
+
 	rows, err := db.Raw("select id, name from sample").Rows()
 	assert.Nil(t, err)
 	defer rows.Close()
@@ -110,6 +98,8 @@ func TestScanRow(t *testing.T){
 }
 
 func TestCreateUser(t *testing.T) {
+	db := newTestDB(t)
+
 	user := User{
 		ID: "1",
 		Name: Name{
@@ -126,6 +116,8 @@ func TestCreateUser(t *testing.T) {
 }
 
 func TestBatchInsertUsers(t *testing.T) {
+	db := newTestDB(t)
+
 	var users []User
 	for i := 2; i < 10; i++ {
 		users = append(users, User{
@@ -143,6 +135,8 @@ func TestBatchInsertUsers(t *testing.T) {
 }
 
 func TestManualTransactionSuccess(t *testing.T){
+	db := newTestDB(t)
+
 	tx := db.Begin()
 	defer tx.Rollback() // Pastikan rollback jika tidak commit
 
@@ -158,6 +152,9 @@ func TestManualTransactionSuccess(t *testing.T){
 }
 
 func TestManualTransactionFailure(t *testing.T){
+	db := newTestDB(t)
+	load(t, db, &User{ID: "11", Name: Name{FirstName: "User11"}, Password: "secret"})
+
 	tx := db.Begin()
 	defer tx.Rollback() // Pastikan rollback jika tidak commit
 
@@ -165,14 +162,28 @@ func TestManualTransactionFailure(t *testing.T){
 	assert.Nil(t, err)
 
 	err = tx.Create(&User{ID: "11", Name: Name{FirstName: "User11"}, Password: "secret"}).Error
-	assert.Nil(t, err)
+	assert.ErrorIs(t, err, dberrors.ErrDuplicateKey) // user 11 sudah ada (duplicate primary key)
+	var dbErr *dberrors.Error
+	if assert.ErrorAs(t, err, &dbErr) {
+		assert.Equal(t, "users", dbErr.Table)
+		assert.Equal(t, []string{"id"}, dbErr.Columns)
+	}
 
 	if err == nil {
 		tx.Commit() // Commit jika tidak ada error
+	} else {
+		tx.Rollback()
 	}
+
+	var count int64
+	err = db.Model(&User{}).Where("id = ?", "12").Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count) // user 12 ikut dibatalkan
 }
 
 func TestTransactionSuccessWithGorm(t *testing.T) {
+	db := newTestDB(t)
+
 	err := db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(&User{ID: "12", Name: Name{FirstName: "User12"}, Password: "secret"}).Error; err != nil {
 			return err
@@ -189,6 +200,9 @@ func TestTransactionSuccessWithGorm(t *testing.T) {
 }
 
 func TestTransactionFailureWithGorm(t *testing.T) {
+	db := newTestDB(t)
+	load(t, db, &User{ID: "13", Name: Name{FirstName: "User13"}, Password: "secret"})
+
 	err := db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(&User{ID: "15", Name: Name{FirstName: "User15"}, Password: "secret"}).Error; err != nil {
 			return err
@@ -198,10 +212,18 @@ func TestTransactionFailureWithGorm(t *testing.T) {
 		}
 		return nil
 	})
-	assert.NotNil(t, err)
+	assert.ErrorIs(t, err, dberrors.ErrDuplicateKey)
+
+	var count int64
+	err = db.Model(&User{}).Where("id = ?", "15").Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count) // user 15 ikut di-rollback
 }
 
 func TestSingleQuery(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	user := User{}
 	err := db.First(&user).Error
 	assert.Nil(t, err)
@@ -214,6 +236,9 @@ func TestSingleQuery(t *testing.T){
 }
 
 func TestSingleSingleObjectInLineCondition(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	user := User{}
 	err := db.Take(&user, "id = ?" , "5").Error
 	assert.Nil(t, err)
@@ -222,6 +247,9 @@ func TestSingleSingleObjectInLineCondition(t *testing.T){
 }
 
 func TestQueryAllObjects(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	var users []User
 	err := db.Find(&users, "id in ?" , []string{"1", "2", "3", "4"}).Error
 	assert.Nil(t, err)
@@ -229,27 +257,39 @@ func TestQueryAllObjects(t *testing.T){
 }
 
 func TestQueryCondition(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	var users []User
-	err := db.Where("first_name like ?" , "%User%").Where("password = ?", "secret").Find(&users).Error
+	err := db.Where("first_name like ?" , "%User%").Where("middle_name = ?", "").Find(&users).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 13, len(users))
 }
 
 func TestOrOperator(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	var users []User
-	err := db.Where("first_name like ?" , "%User%").Or("password = ?", "secret").Find(&users).Error
+	err := db.Where("first_name like ?" , "%User%").Or("last_name = ?", "Vex").Find(&users).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 14, len(users))
 }
 
 func TestNotOperator(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	var users []User
-	err := db.Not("first_name like ?" , "%User%").Where("password = ?", "secret").Find(&users).Error
+	err := db.Not("first_name like ?" , "%User%").Where("last_name = ?", "Vex").Find(&users).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(users))
 }
 
 func TestSelectFields(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	var users []User
 	err := db.Select("id, first_name").Find(&users).Error
 	assert.Nil(t, err)
@@ -263,12 +303,14 @@ func TestSelectFields(t *testing.T){
 }
 
 func TestStructCondition(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	userCondition := User{
 		Name: Name{
 			FirstName: "User5",
 			LastName: "", // LastName akan diabaikan karena dianggap default value
 		},
-		Password: "secret",
 	}
 	var users []User
 	err := db.Where(userCondition).Find(&users).Error
@@ -277,6 +319,9 @@ func TestStructCondition(t *testing.T){
 }
 
 func TestMapCondition(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	mapCondition := map[string]interface{}{
 		"middle_name": "",
 	}
@@ -287,6 +332,9 @@ func TestMapCondition(t *testing.T){
 }
 
 func TestOrderLimitOffset(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	var users []User
 	err := db.Order("id asc, first_name desc").Limit(5).Offset(5).Find(&users).Error
 	assert.Nil(t, err)
@@ -300,6 +348,9 @@ type UserResponse struct {
 }
 
 func TestQueryNonModel(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	var users []UserResponse
 	// dari model users, pilih id, first_name, last_name, lalu simpan ke variable users
 	err := db.Model(&User{}).Select("id", "first_name", "last_name").Find(&users).Error
@@ -309,6 +360,9 @@ func TestQueryNonModel(t *testing.T){
 }
 
 func TestUpdate(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	user := User{}
 	err := db.Take(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
@@ -320,9 +374,13 @@ func TestUpdate(t *testing.T){
 
 	err = db.Save(&user).Error
 	assert.Nil(t, err)
+	assert.True(t, user.VerifyPassword("secret123")) // password disimpan sebagai hash
 }
 
 func TestUpdateSelectedColumns(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	// updates versi 1
 	// dari model (tabel) users, yang id = 1, lakukan update...
 	err := db.Model(&User{}).Where("id = ?", "1").Updates(map[string]interface{}{
@@ -337,7 +395,8 @@ func TestUpdateSelectedColumns(t *testing.T){
 
 	// update versi 3
 	// karena diatas sudah menentukan model yg akan digunakan, maka menambahakan kode db.Model() bisa optional
-	err = db.Where("id = ?", "1").Updates(User{
+	// (gunakan pointer, karena User punya hook BeforeSave)
+	err = db.Where("id = ?", "1").Updates(&User{
 		Name: Name{
 			FirstName: "Lev",
 			LastName: "Tempest",
@@ -347,6 +406,8 @@ func TestUpdateSelectedColumns(t *testing.T){
 }
 
 func TestAutoIncrement(t *testing.T){
+	db := newTestDB(t)
+
 	for i :=  0; i < 10; i++ {
 		userLog := UserLog{
 			UserId: "1",
@@ -361,6 +422,8 @@ func TestAutoIncrement(t *testing.T){
 }
 
 func TestSaveOrUpdate(t *testing.T){
+	db := newTestDB(t)
+
 	userLog := UserLog{
 		UserId: "1",
 		Action: "Test Action",
@@ -375,6 +438,8 @@ func TestSaveOrUpdate(t *testing.T){
 }
 
 func TestSaveOrUpdateNonAutoIncrement(t *testing.T){
+	db := newTestDB(t)
+
 	user := User{
 		ID	: "99",
 		Name: Name{
@@ -391,6 +456,8 @@ func TestSaveOrUpdateNonAutoIncrement(t *testing.T){
 }
 
 func TestOnConflict(t *testing.T){
+	db := newTestDB(t)
+
 	user := User{
 		ID	: "88",
 		Name: Name{
@@ -405,6 +472,10 @@ func TestOnConflict(t *testing.T){
 }
 
 func TestDeke(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+	load(t, db, &[]User{{ID: "88", Name: Name{FirstName: "User88"}}, {ID: "99", Name: Name{FirstName: "User99"}}})
+
 	var user User
 	// cara 1 : select dulu baru update
 	err := db.Take(&user, "id = ?", "88").Error
@@ -422,6 +493,8 @@ func TestDeke(t *testing.T){
 }
 
 func TestSoftDelete(t *testing.T){
+	db := newTestDB(t)
+
 	todo := Todo{
 		UserId:  		"1",
 		Title:   		"Title 1",
@@ -441,6 +514,9 @@ func TestSoftDelete(t *testing.T){
 }
 
 func TestUnscoped(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "todos")
+
 	var todo Todo
 	err := db.Unscoped().First(&todo, "id = ?", 1).Error
 	assert.Nil(t, err)
@@ -454,6 +530,9 @@ func TestUnscoped(t *testing.T){
 }
 
 func TestLock(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	err := db.Transaction(func(tx *gorm.DB) error {
 		var user User
 		err := tx.Clauses(clause.Locking{
@@ -472,10 +551,13 @@ func TestLock(t *testing.T){
 }
 
 func TestCreateWallet(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	wallet := Wallet{
 		ID: "1",
 		UserId: "1",
-		Balance: 1000000,
+		Balance: money.New(1000000, money.IDR),
 	}
 
 	err := db.Create(&wallet).Error
@@ -483,22 +565,30 @@ func TestCreateWallet(t *testing.T){
 }
 
 func TestRetrieveRelation(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
 	var user User
 	err := db.Model(&User{}).Preload("Wallet").Take(&user, "id = ?", "1").Error // preload melakukan 2x query
 	assert.Nil(t, err)
 
-	assert.Equal(t, 1000000, user.Wallet.Balance)
+	assert.Equal(t, money.New(1000000, money.IDR), user.Wallet.Balance)
 }
 
 func TestRetrieveRelationJoin(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
 	var user User
 	err := db.Model(&User{}).Joins("Wallet").Take(&user, "users.id = ?", "1").Error // joins melakukan 1x query (cocok untuk relasi One to One)
 	assert.Nil(t, err)
 
-	assert.Equal(t, 1000000, user.Wallet.Balance)
+	assert.Equal(t, money.New(1000000, money.IDR), user.Wallet.Balance)
 }
 
 func TestAutoCreateUpdate(t *testing.T){
+	db := newTestDB(t)
+
 	user := User{
 		ID: "20",
 		Password: "secret",
@@ -508,7 +598,7 @@ func TestAutoCreateUpdate(t *testing.T){
 		Wallet: Wallet{
 			ID: "20",
 			UserId: "20",
-			Balance: 1000000,
+			Balance: money.New(1000000, money.IDR),
 		},
 	}
 
@@ -517,6 +607,8 @@ func TestAutoCreateUpdate(t *testing.T){
 }
 
 func TestSkipAutoCreateUpdate(t *testing.T){
+	db := newTestDB(t)
+
 	user := User{
 		ID: "21",
 		Password: "secret",
@@ -526,7 +618,7 @@ func TestSkipAutoCreateUpdate(t *testing.T){
 		Wallet: Wallet{
 			ID: "21",
 			UserId: "21",
-			Balance: 1000000,
+			Balance: money.New(1000000, money.IDR),
 		},
 	}
 
@@ -535,6 +627,9 @@ func TestSkipAutoCreateUpdate(t *testing.T){
 }
 
 func TestUserAndAddresses(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users") // Save melakukan update karena user 2 sudah ada
+
 	user := User{
 		ID: "2",
 		Password: "secret",
@@ -544,7 +639,7 @@ func TestUserAndAddresses(t *testing.T){
 		Wallet: Wallet{
 			ID: "2",
 			UserId: "2",
-			Balance: 1000000,
+			Balance: money.New(1000000, money.IDR),
 		},
 		Addresses: []Address{
 			{
@@ -562,18 +657,27 @@ func TestUserAndAddresses(t *testing.T){
 }
 
 func TestPreloadJoinOneToMany(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets", "addresses")
+
 	var users []User
 	err := db.Model(&User{}).Preload("Addresses").Joins("Wallet").Find(&users).Error // preload untuk relasi One to Many, join untuk relasi One to One
 	assert.Nil(t, err)
 }
 
 func TestTakePreloadJoinOneToMany(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets", "addresses")
+
 	var user User
-	err := db.Model(&User{}).Preload("Addresses").Joins("Wallet").Take(&user, "users.id = ?", "50").Error // preload untuk relasi One to Many, join untuk relasi One to One
+	err := db.Model(&User{}).Preload("Addresses").Joins("Wallet").Take(&user, "users.id = ?", "2").Error // preload untuk relasi One to Many, join untuk relasi One to One
 	assert.Nil(t, err)
 }
 
 func TestBelongsTo(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "addresses")
+
 	fmt.Println("Preload");
 	var addresses []Address
 	err := db.Model(&Address{}).Preload("User").Find(&addresses).Error // preload melakukan 2x query
@@ -588,6 +692,9 @@ func TestBelongsTo(t *testing.T){
 }
 
 func TestBelongsToWallet(t *testing.T){ 	// BelongsToOneToOne
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
 	fmt.Println("Preload");
 	var wallets []Wallet
 	err := db.Model(&Wallet{}).Preload("User").Find(&wallets).Error // preload melakukan 2x query
@@ -600,10 +707,13 @@ func TestBelongsToWallet(t *testing.T){ 	// BelongsToOneToOne
 }
 
 func TestCreateManyToMany(t *testing.T){	// cara manual (lebih baik menggunakan associations /seperti baris kode 635)
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	product := Product{
 		ID: "P001",
 		Name: "Product 1",
-		Price: 1000000,
+		Price: money.New(1000000, money.IDR),
 	}
 	err := db.Create(&product).Error
 	assert.Nil(t, err)
@@ -622,6 +732,9 @@ func TestCreateManyToMany(t *testing.T){	// cara manual (lebih baik menggunakan
 }
 
 func TestPreloadManyToMany(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "products", "likes")
+
 	var product Product
 	err := db.Preload("LikedByUsers").Take(&product, "id = ?", "P001").Error
 	assert.Nil(t, err)
@@ -629,6 +742,9 @@ func TestPreloadManyToMany(t *testing.T){
 }
 
 func TestPreloadManyToManyUser(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "products", "likes")
+
 	var user User
 	err := db.Preload("LikeProducts").Take(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
@@ -636,6 +752,9 @@ func TestPreloadManyToManyUser(t *testing.T){
 }
 
 func TestAssociationFind(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "products", "likes")
+
 	var product Product
 	err := db.Take(&product, "id = ?", "P001").Error
 	assert.Nil(t, err)
@@ -647,6 +766,9 @@ func TestAssociationFind(t *testing.T){
 }
 
 func TestAssociationAdd(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "products", "likes")
+
 	var user User
 	err := db.Take(&user, "id = ?", "3").Error
 	assert.Nil(t, err)
@@ -660,6 +782,9 @@ func TestAssociationAdd(t *testing.T){
 }
 
 func TestAssociationReplace(t *testing.T){	// cocok untuk relasi Belongs To (One to One)
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
 	err := db.Transaction(func(tx *gorm.DB) error {
 		var user User
 		err := tx.Take(&user, "id = ?", "1").Error
@@ -668,7 +793,7 @@ func TestAssociationReplace(t *testing.T){	// cocok untuk relasi Belongs To (One
 		wallet := Wallet{
 			ID: "01",
 			UserId: user.ID,
-			Balance: 1000000,
+			Balance: money.New(1000000, money.IDR),
 		}
 
 		err = tx.Model(&user).Association("Wallet").Replace(&wallet)
@@ -678,6 +803,10 @@ func TestAssociationReplace(t *testing.T){	// cocok untuk relasi Belongs To (One
 }
 
 func TestAssociationDelete(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "products", "likes")
+	load(t, db, &userLikeProduct{UserId: "3", ProductId: "P001"})
+
 	var user User
 	err := db.Take(&user, "id = ?", "3").Error
 	assert.Nil(t, err)
@@ -691,6 +820,9 @@ func TestAssociationDelete(t *testing.T){
 }
 
 func TestAssociationClear(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "products", "likes")
+
 	var product Product
 	err := db.Take(&product, "id = ?", "P001").Error
 	assert.Nil(t, err)
@@ -700,14 +832,20 @@ func TestAssociationClear(t *testing.T){
 }
 
 func TestPreloadingWithCondition(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
 	var user User
-	err := db.Preload("Wallet", "balance > ?", 1000000).Take(&user, "id = ?", "1").Error
+	err := db.Preload("Wallet", "balance_amount > ?", 1000000).Take(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
 
 	fmt.Println(user);
 }
 
 func TestPreloadingNested(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets", "addresses")
+
 	var wallet Wallet
 	err := db.Preload("User.Addresses").Take(&wallet, "id = ?", "1").Error
 	assert.Nil(t, err)
@@ -718,12 +856,18 @@ func TestPreloadingNested(t *testing.T){
 }
 
 func TestPreloadingAll(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets", "addresses", "products", "likes")
+
 	var user User
 	err := db.Preload(clause.Associations).Take(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
 }
 
 func TestJoinQuery(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
 	var users []User
 	err := db.Joins("join wallets on wallets.user_id = users.id").Find(&users).Error	// inner join
 	assert.Nil(t, err)
@@ -736,20 +880,26 @@ func TestJoinQuery(t *testing.T){
 }
 
 func TestJoinWithCondition(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
 	var users []User
-	err := db.Joins("join wallets on wallets.user_id = users.id AND wallets.balance > ?", 500000).Find(&users).Error
+	err := db.Joins("join wallets on wallets.user_id = users.id AND wallets.balance_amount > ?", 500000).Find(&users).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 4, len(users))
 
 	users = []User{}
-	err = db.Joins("Wallet").Where("Wallet.balance > ?", 500000).Find(&users).Error	// alias menggunakan nama field	
+	err = db.Joins("Wallet").Where("Wallet.balance_amount > ?", 500000).Find(&users).Error	// alias menggunakan nama field	
 	assert.Nil(t, err)
 	assert.Equal(t, 4, len(users))
 }
 
 func TestCount(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
 	var count int64
-	err := db.Model(&User{}).Joins("Wallet").Where("Wallet.balance > ?", 500000).Count(&count).Error
+	err := db.Model(&User{}).Joins("Wallet").Where("Wallet.balance_amount > ?", 500000).Count(&count).Error
 	assert.Nil(t, err)
 	assert.Equal(t, int64(4), count)
 }
@@ -762,8 +912,11 @@ type AggregationResult struct {
 }
 
 func TestAggregation(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
 	var result AggregationResult
-	err := db.Model(&Wallet{}).Select("sum(balance) as total_balance", "max(balance) as max_balance", "min(balance) as min_balance", "avg(balance) as avg_balance").Take(&result).Error
+	err := db.Model(&Wallet{}).Select("sum(balance_amount) as total_balance", "max(balance_amount) as max_balance", "min(balance_amount) as min_balance", "avg(balance_amount) as avg_balance").Take(&result).Error
 	assert.Nil(t, err)
 
 	assert.Equal(t, int64(4000000), result.TotalBalance)
@@ -773,13 +926,19 @@ func TestAggregation(t *testing.T){
 }
 
 func TestAggregationGroupByAndHaving(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
 	var results []AggregationResult
-	err := db.Model(&Wallet{}).Select("sum(balance) as total_balance", "max(balance) as max_balance", "min(balance) as min_balance", "avg(balance) as avg_balance").Joins("User").Group("User.id").Having("sum(balance) > ?", 500000).Find(&results).Error
+	err := db.Model(&Wallet{}).Select("sum(balance_amount) as total_balance", "max(balance_amount) as max_balance", "min(balance_amount) as min_balance", "avg(balance_amount) as avg_balance").Joins("User").Group("User.id").Having("sum(balance_amount) > ?", 500000).Find(&results).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 4, len(results))
 }
 
 func TestWithContext(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners")
+
 	ctx := context.Background()
 	
 	var users []User
@@ -789,14 +948,18 @@ func TestWithContext(t *testing.T){
 }
 
 func BrokeWalletBalance(db *gorm.DB) *gorm.DB {
-	return db.Where("balance = ?", 0)
+	return db.Where("balance_amount = ?", 0)
 }
 
 func SultanWalletBalance(db *gorm.DB) *gorm.DB {
-	return db.Where("balance = ?", 1000000)
+	return db.Where("balance_amount = ?", 1000000)
 }
 
 func TestScopes(t *testing.T){
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	load(t, db, &Wallet{ID: "3", UserId: "3", Balance: money.New(0, money.IDR)})
+
 	var wallets []Wallet
 	err := db.Scopes(BrokeWalletBalance).Find(&wallets).Error
 	assert.Nil(t, err)
@@ -808,6 +971,8 @@ func TestScopes(t *testing.T){
 }
 
 func TestMigrator(t *testing.T){	// lebih disarankan menggunakan migrator manual
+	db := newTestDB(t)
+
 	err := db.Migrator().AutoMigrate(&GuestBook{})
 	assert.Nil(t, err)
 }
@@ -847,6 +1012,8 @@ func TestMigrator(t *testing.T){	// lebih disarankan menggunakan migrator manual
 // 3. AfterFind()
 
 func TestHook(t *testing.T){
+	db := newTestDB(t)
+
 	user := User{
 		Password: "secret",
 		Name: Name{
@@ -4,8 +4,8 @@ import "time"
 
 type GuestBook struct {
 	ID        int64     `gorm:"primary_key;autoIncrement;column:id"`
-	Name      string    `gorm:"column:name"`
-	Email     string    `gorm:"column:email"`
+	Name      string    `gorm:"column:name;size:100"`
+	Email     string    `gorm:"column:email;size:100"`
 	Message   string    `gorm:"column:message"`
 	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
@@ -0,0 +1,198 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"learn-golang-gorm/fixtures"
+	"learn-golang-gorm/internal/testdb"
+	"learn-golang-gorm/migrations"
+	"learn-golang-gorm/money"
+	"learn-golang-gorm/password"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Setiap test memanggil newTestDB supaya mendapat database yang bersih, jadi test tidak bergantung
+// pada urutan eksekusi (go test -run X dan go test -shuffle=on sama-sama aman).
+//
+//   - sqlite (default jika DB_DIALECT, DB_CONFIG_FILE dan DB_DSN kosong): setiap test punya database in-memory sendiri
+//   - mysql / postgres: schema dimigrasi sekali, lalu semua tabel dikosongkan sebelum setiap test
+//     (karena itu test tidak boleh dijalankan paralel pada mode ini)
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	cfg := testConfig(t)
+	if cfg.Dialect != DialectSQLite {
+		db := sharedTestDB(t, cfg)
+		if err := truncateTables(db); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+
+	// untuk sqlite, DSN selalu diganti dengan database in-memory yang namanya unik per test
+	cfg.DSN = testdb.MemoryDSN(t)
+	return openSQLiteTestDB(t, cfg)
+}
+
+// newConcurrentTestDB sama seperti newTestDB, untuk test yang menjalankan banyak transaction bersamaan
+// (sqlite memakai testdb.FileDSN: file sementara, transaction antre)
+func newConcurrentTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	cfg := testConfig(t)
+	if cfg.Dialect != DialectSQLite {
+		return newTestDB(t)
+	}
+	cfg.DSN = testdb.FileDSN(t)
+	return openSQLiteTestDB(t, cfg)
+}
+
+func testConfig(t *testing.T) Config {
+	t.Helper()
+
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// DB_DSN tanpa DB_DIALECT tetap memakai dialect default (mysql), bukan diam-diam diganti sqlite
+	_, dialectSet := os.LookupEnv(EnvDialect)
+	if !dialectSet && os.Getenv(EnvConfigFile) == "" && os.Getenv(EnvDSN) == "" {
+		cfg.Dialect = DialectSQLite
+	}
+	return cfg
+}
+
+func openSQLiteTestDB(t *testing.T, cfg Config) *gorm.DB {
+	t.Helper()
+
+	db, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() }) // database in-memory hilang saat koneksi terakhir ditutup
+
+	if err := migrations.Up(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// hash password dengan cost minimal supaya fixture (puluhan user) tetap cepat dimuat
+func init() {
+	PasswordHasher = password.Bcrypt{Cost: bcrypt.MinCost}
+}
+
+var (
+	sharedDB     *gorm.DB
+	sharedDBErr  error
+	sharedDBOnce sync.Once
+)
+
+func sharedTestDB(t *testing.T, cfg Config) *gorm.DB {
+	t.Helper()
+	sharedDBOnce.Do(func() {
+		sharedDB, sharedDBErr = Open(cfg)
+		if sharedDBErr == nil {
+			sharedDBErr = migrations.Up(context.Background(), sharedDB)
+		}
+	})
+	if sharedDBErr != nil {
+		t.Fatal(sharedDBErr)
+	}
+	return sharedDB
+}
+
+// truncateTables mengosongkan semua tabel kecuali schema_migrations
+func truncateTables(db *gorm.DB) error {
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return err
+	}
+	var quoted []string
+	for _, table := range tables {
+		if table != "schema_migrations" {
+			quoted = append(quoted, db.Statement.Quote(table))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+
+	if db.Dialector.Name() == DialectPostgres {
+		return db.Exec("TRUNCATE " + strings.Join(quoted, ", ") + " RESTART IDENTITY CASCADE").Error
+	}
+
+	// mysql: foreign key dimatikan sementara di koneksi yang sama
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SET FOREIGN_KEY_CHECKS = 1")
+		for _, table := range quoted {
+			if err := conn.Exec("TRUNCATE TABLE " + table).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// loadFixtures memuat file testdata/fixtures/<name>.yml sekaligus dalam satu Set,
+// sehingga label di satu file (misalnya $user_1) bisa dipakai oleh file lain.
+// Relasi tidak ikut dibuat otomatis, jadi setiap file yang dibutuhkan harus disebutkan.
+func loadFixtures(t *testing.T, db *gorm.DB, names ...string) *fixtures.Set {
+	t.Helper()
+	loader, err := fixtures.New(db, Models()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		paths = append(paths, filepath.Join("testdata", "fixtures", name+".yml"))
+	}
+	set, err := loader.LoadFiles(context.Background(), paths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+// load memasukkan data tambahan khusus satu test (pointer ke struct atau slice) sesuai urutan parameter
+func load(t *testing.T, db *gorm.DB, records ...interface{}) {
+	t.Helper()
+	for _, record := range records {
+		if err := db.Omit(clause.Associations).Create(record).Error; err != nil {
+			t.Fatalf("load %T: %v", record, err)
+		}
+	}
+}
+
+// idr membuat money.Money dalam rupiah
+func idr(amount int64) money.Money {
+	return money.New(amount, money.IDR)
+}
+
+func (s *Sample) TableName() string {
+	return "sample"
+}
+
+type userLikeProduct struct {
+	UserId    string `gorm:"column:user_id"`
+	ProductId string `gorm:"column:product_id"`
+}
+
+func (u *userLikeProduct) TableName() string {
+	return "user_like_product"
+}
@@ -0,0 +1,40 @@
+package learn_golang_gorm
+
+import (
+	"learn-golang-gorm/idgen"
+
+	"gorm.io/gorm"
+)
+
+// IDGenerators menentukan cara membuat primary key untuk setiap model jika ID belum diisi.
+// Semua model memakai ULID. Generator bisa diganti per model, contoh:
+//
+//	learn_golang_gorm.IDGenerators.Set(&User{}, idgen.NewUUIDv7())
+//
+// Format lama User ("user-yyyyMMddHHmmss") hanya unik di dalam satu proses, jadi harus dipilih sendiri:
+//
+//	learn_golang_gorm.IDGenerators.Set(&User{}, idgen.NewPrefix("user-"))
+var IDGenerators = idgen.NewRegistry(idgen.NewULID())
+
+// assignID mengisi *id dengan ID baru dari generator milik model, jika masih kosong
+func assignID(model interface{}, id *string) error {
+	if *id != "" {
+		return nil
+	}
+	generated, err := IDGenerators.NewID(model)
+	if err != nil {
+		return err
+	}
+	*id = generated
+	return nil
+}
+
+func (w *Wallet) BeforeCreate(db *gorm.DB) error {
+	withDefaultCurrency(&w.Balance)
+	return assignID(w, &w.ID)
+}
+
+func (p *Product) BeforeCreate(db *gorm.DB) error {
+	withDefaultCurrency(&p.Price)
+	return assignID(p, &p.ID)
+}
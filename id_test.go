@@ -0,0 +1,89 @@
+package learn_golang_gorm
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"learn-golang-gorm/idgen"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchInsertUsersWithGeneratedID(t *testing.T) {
+	db := newTestDB(t)
+
+	// sebelumnya semua user di batch ini mendapat ID "user-<detik>" yang sama
+	var users []User
+	for i := 0; i < 50; i++ {
+		users = append(users, User{Name: Name{FirstName: fmt.Sprint("User", i)}, Password: "secret"})
+	}
+	assert.Nil(t, db.Create(&users).Error)
+
+	seen := map[string]bool{}
+	for _, user := range users {
+		assert.Regexp(t, `^[0-9A-HJKMNP-TV-Z]{26}$`, user.ID) // ULID
+		assert.False(t, seen[user.ID], user.ID)
+		seen[user.ID] = true
+	}
+
+	var count int64
+	assert.Nil(t, db.Model(&User{}).Count(&count).Error)
+	assert.Equal(t, int64(50), count)
+}
+
+func TestPrefixUserIDIsOptIn(t *testing.T) {
+	db := newTestDB(t)
+
+	previous := IDGenerators.Get(&User{})
+	IDGenerators.Set(&User{}, idgen.NewPrefix("user-"))
+	t.Cleanup(func() { IDGenerators.Set(&User{}, previous) })
+
+	users := []User{{Name: Name{FirstName: "A"}, Password: "secret"}, {Name: Name{FirstName: "B"}, Password: "secret"}}
+	assert.Nil(t, db.Create(&users).Error)
+	for _, user := range users {
+		assert.Regexp(t, `^user-\d{14}(-\d+)?$`, user.ID)
+	}
+	assert.NotEqual(t, users[0].ID, users[1].ID)
+}
+
+func TestConcurrentCreateWithGeneratedID(t *testing.T) {
+	// setiap Create juga menulis user_logs (audit) dan ledger, jadi transaction-nya harus antre
+	db := newConcurrentTestDB(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user := User{Name: Name{FirstName: "User"}, Password: "secret"}
+			assert.Nil(t, db.Create(&user).Error)
+			assert.Nil(t, db.Create(&Wallet{UserId: user.ID, Balance: idr(1000)}).Error)
+			assert.Nil(t, db.Create(&Product{Name: "Product", Price: idr(1000)}).Error)
+		}()
+	}
+	wg.Wait()
+
+	for _, model := range []interface{}{&User{}, &Wallet{}, &Product{}} {
+		var count int64
+		assert.Nil(t, db.Model(model).Count(&count).Error)
+		assert.Equal(t, int64(10), count, "%T", model)
+	}
+}
+
+func TestCustomIDGenerator(t *testing.T) {
+	db := newTestDB(t)
+
+	previous := IDGenerators.Get(&Product{})
+	IDGenerators.Set(&Product{}, idgen.NewUUIDv7())
+	t.Cleanup(func() { IDGenerators.Set(&Product{}, previous) })
+
+	product := Product{Name: "Product", Price: idr(1000)}
+	assert.Nil(t, db.Create(&product).Error)
+	assert.Len(t, product.ID, 36)
+
+	// ID yang diisi manual tidak diganti
+	product = Product{ID: "P001", Name: "Product 1", Price: idr(1000000)}
+	assert.Nil(t, db.Create(&product).Error)
+	assert.Equal(t, "P001", product.ID)
+}
@@ -0,0 +1,126 @@
+// Package idempotency mencegah operasi yang mengubah data dijalankan dua kali saat client melakukan retry.
+//
+// Setiap operasi diberi key dari client (misalnya header Idempotency-Key) dan scope (nama operasinya).
+// Panggilan pertama menjalankan operasi dan menyimpan hasilnya di tabel idempotency_keys.
+// Panggilan berikutnya dengan scope + key yang sama tidak menjalankan operasi lagi, tetapi
+// mengembalikan hasil yang tersimpan, atau ErrConflict jika isi request-nya berbeda.
+//
+//	result, replayed, err := idempotency.Do(ctx, store, "wallet_top_up", key, request,
+//		func(tx *gorm.DB) (TopUpResult, error) {
+//			// ubah data memakai tx
+//		})
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"learn-golang-gorm/uow"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrMissingKey = errors.New("idempotency: key is required")
+	ErrConflict   = errors.New("idempotency: key was already used with a different request")
+)
+
+const StatusCompleted = "completed"
+
+// Record adalah satu baris tabel idempotency_keys
+type Record struct {
+	Scope       string    `gorm:"primaryKey;column:scope;size:100"`
+	Key         string    `gorm:"primaryKey;column:idempotency_key;size:100"`
+	RequestHash string    `gorm:"column:request_hash;size:64"`
+	Status      string    `gorm:"column:status;size:20"`
+	Response    string    `gorm:"column:response"` // hasil operasi dalam format JSON
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (r *Record) TableName() string {
+	return "idempotency_keys"
+}
+
+// Store menyimpan key di database
+type Store struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// Do menjalankan fn di dalam db.Transaction, sekali untuk setiap scope + key.
+//
+// Key dicatat di transaction yang sama dengan fn, jadi jika fn gagal (atau proses mati di tengah jalan)
+// key ikut di-rollback dan request boleh diulang. Panggilan bersamaan dengan key yang sama menunggu
+// panggilan pertama selesai (unique primary key), lalu mendapat hasilnya dengan replayed = true.
+// request dipakai untuk mendeteksi key yang dipakai ulang untuk request berbeda, dan hasil fn
+// harus bisa di-encode ke JSON.
+func Do[T any](ctx context.Context, s *Store, scope, key string, request interface{}, fn func(tx *gorm.DB) (T, error)) (result T, replayed bool, err error) {
+	if key == "" {
+		return result, false, ErrMissingKey
+	}
+	hash, err := hashRequest(request)
+	if err != nil {
+		return result, false, err
+	}
+
+	err = uow.DB(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		record := Record{Scope: scope, Key: key, RequestHash: hash, Status: StatusCompleted}
+		inserted := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if inserted.Error != nil {
+			return inserted.Error
+		}
+
+		if inserted.RowsAffected == 0 {
+			// key sudah dipakai: kembalikan hasil sebelumnya
+			var existing Record
+			if err := tx.Take(&existing, "scope = ? AND idempotency_key = ?", scope, key).Error; err != nil {
+				return err
+			}
+			if existing.RequestHash != hash {
+				return fmt.Errorf("%w: %s/%s", ErrConflict, scope, key)
+			}
+			replayed = true
+			return json.Unmarshal([]byte(existing.Response), &result)
+		}
+
+		if result, err = fn(tx); err != nil {
+			return err
+		}
+		response, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("idempotency: encode response: %w", err)
+		}
+		return tx.Model(&record).Update("response", string(response)).Error
+	})
+	if err != nil {
+		var zero T
+		return zero, false, err
+	}
+	return result, replayed, nil
+}
+
+func hashRequest(request interface{}) (string, error) {
+	// encoding/json mengurutkan key map, jadi request yang sama selalu menghasilkan hash yang sama
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("idempotency: encode request: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Purge menghapus key yang dibuat sebelum waktu tersebut, setelah itu key yang sama bisa dipakai lagi
+func (s *Store) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("created_at < ?", before).Delete(&Record{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,147 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"learn-golang-gorm/internal/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type counter struct {
+	ID    int `gorm:"primaryKey"`
+	Value int
+}
+
+// openSQLite memakai file (bukan in-memory shared cache) supaya transaction bersamaan bisa antre
+func openSQLite(t *testing.T) *gorm.DB {
+	db := testdb.File(t, &Record{}, &counter{})
+	assert.Nil(t, db.Create(&counter{ID: 1}).Error)
+	return db
+}
+
+type incrementRequest struct {
+	By int
+}
+
+// increment menambah counter dan mengembalikan nilai barunya
+func increment(by int, calls *atomic.Int32) func(tx *gorm.DB) (int, error) {
+	return func(tx *gorm.DB) (int, error) {
+		calls.Add(1)
+		var c counter
+		if err := tx.Take(&c, 1).Error; err != nil {
+			return 0, err
+		}
+		c.Value += by
+		return c.Value, tx.Save(&c).Error
+	}
+}
+
+func TestDoExecutesOnce(t *testing.T) {
+	ctx := context.Background()
+	store := New(openSQLite(t))
+	var calls atomic.Int32
+
+	value, replayed, err := Do(ctx, store, "increment", "key-1", incrementRequest{By: 5}, increment(5, &calls))
+	assert.Nil(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, 5, value)
+
+	value, replayed, err = Do(ctx, store, "increment", "key-1", incrementRequest{By: 5}, increment(5, &calls))
+	assert.Nil(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, 5, value) // hasil tersimpan, bukan 10
+	assert.Equal(t, int32(1), calls.Load())
+
+	// key yang sama dengan request berbeda
+	_, _, err = Do(ctx, store, "increment", "key-1", incrementRequest{By: 7}, increment(7, &calls))
+	assert.ErrorIs(t, err, ErrConflict)
+
+	// key sama di scope lain adalah operasi yang berbeda
+	value, replayed, err = Do(ctx, store, "other", "key-1", incrementRequest{By: 1}, increment(1, &calls))
+	assert.Nil(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, 6, value)
+
+	_, _, err = Do(ctx, store, "increment", "", incrementRequest{By: 1}, increment(1, &calls))
+	assert.ErrorIs(t, err, ErrMissingKey)
+}
+
+func TestFailedCallCanBeRetried(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	store := New(db)
+	var calls atomic.Int32
+
+	failure := errors.New("payment gateway timeout")
+	_, _, err := Do(ctx, store, "increment", "key-1", incrementRequest{By: 5}, func(tx *gorm.DB) (int, error) {
+		if _, err := increment(5, &calls)(tx); err != nil {
+			return 0, err
+		}
+		return 0, failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	// perubahan dan key ikut di-rollback
+	var count int64
+	assert.Nil(t, db.Model(&Record{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	value, replayed, err := Do(ctx, store, "increment", "key-1", incrementRequest{By: 5}, increment(5, &calls))
+	assert.Nil(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, 5, value)
+}
+
+func TestConcurrentCallsWithSameKey(t *testing.T) {
+	ctx := context.Background()
+	store := New(openSQLite(t))
+	var calls atomic.Int32
+
+	var wg sync.WaitGroup
+	results := make(chan int, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, _, err := Do(ctx, store, "increment", "key-1", incrementRequest{By: 3}, increment(3, &calls))
+			assert.Nil(t, err)
+			results <- value
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	assert.Equal(t, int32(1), calls.Load())
+	for value := range results {
+		assert.Equal(t, 3, value)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	ctx := context.Background()
+	store := New(openSQLite(t))
+	var calls atomic.Int32
+
+	_, _, err := Do(ctx, store, "increment", "key-1", incrementRequest{By: 1}, increment(1, &calls))
+	assert.Nil(t, err)
+
+	purged, err := store.Purge(ctx, time.Now().Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), purged)
+	purged, err = store.Purge(ctx, time.Now().Add(time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	// setelah di-purge, key boleh dipakai lagi
+	_, replayed, err := Do(ctx, store, "increment", "key-1", incrementRequest{By: 1}, increment(1, &calls))
+	assert.Nil(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,289 @@
+// Package idgen berisi generator primary key berbentuk string yang aman dipakai bersamaan
+// (concurrent) maupun saat batch insert: ULID, UUIDv7, Snowflake dan format prefix lama.
+//
+// Generator dipilih per model lewat Registry:
+//
+//	ids := idgen.NewRegistry(idgen.NewULID())     // default untuk model yang tidak didaftarkan
+//	ids.Set(&User{}, idgen.NewPrefix("user-"))
+//	id, err := ids.NewID(&User{})
+package idgen
+
+import (
+	"crypto/rand"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Generator membuat ID baru yang unik. Implementasi harus aman dipanggil dari banyak goroutine.
+type Generator interface {
+	NewID() (string, error)
+}
+
+// Func mengubah fungsi biasa menjadi Generator
+type Func func() (string, error)
+
+func (f Func) NewID() (string, error) {
+	return f()
+}
+
+// Registry memetakan model (berdasarkan tipe struct-nya) ke Generator
+type Registry struct {
+	mu         sync.RWMutex
+	fallback   Generator
+	generators map[reflect.Type]Generator
+}
+
+// NewRegistry membuat Registry dengan generator default untuk model yang tidak didaftarkan
+func NewRegistry(fallback Generator) *Registry {
+	return &Registry{fallback: fallback, generators: map[reflect.Type]Generator{}}
+}
+
+// Set mengganti generator untuk model tersebut (boleh berupa pointer maupun struct)
+func (r *Registry) Set(model interface{}, generator Generator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generators[modelType(model)] = generator
+}
+
+// Get mengembalikan generator untuk model tersebut, atau generator default
+func (r *Registry) Get(model interface{}) Generator {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if generator, ok := r.generators[modelType(model)]; ok {
+		return generator
+	}
+	return r.fallback
+}
+
+// NewID membuat ID baru dengan generator milik model tersebut
+func (r *Registry) NewID(model interface{}) (string, error) {
+	generator := r.Get(model)
+	if generator == nil {
+		return "", fmt.Errorf("idgen: no generator for %s", modelType(model))
+	}
+	return generator.NewID()
+}
+
+func modelType(model interface{}) reflect.Type {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// ByName membuat generator dari namanya: ulid, uuidv7, snowflake atau prefix:<teks>
+// (contoh "prefix:user-"), berguna untuk konfigurasi dari file / environment variable
+func ByName(name string) (Generator, error) {
+	switch {
+	case name == "ulid":
+		return NewULID(), nil
+	case name == "uuidv7":
+		return NewUUIDv7(), nil
+	case name == "snowflake":
+		return NewSnowflake(0)
+	case strings.HasPrefix(name, "prefix:"):
+		return NewPrefix(strings.TrimPrefix(name, "prefix:")), nil
+	default:
+		return nil, fmt.Errorf("idgen: unknown generator %q, use ulid, uuidv7, snowflake or prefix:<text>", name)
+	}
+}
+
+// ===== ULID =====
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID menghasilkan ULID (26 karakter, bisa diurutkan berdasarkan waktu).
+// ID yang dibuat pada milidetik yang sama dibuat monoton dengan menaikkan bagian acaknya.
+type ULID struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	lastMs  uint64
+	entropy [10]byte
+}
+
+func NewULID() *ULID {
+	return &ULID{now: time.Now}
+}
+
+func (g *ULID) NewID() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.now().UnixMilli())
+	if ms > g.lastMs {
+		if _, err := rand.Read(g.entropy[:]); err != nil {
+			return "", fmt.Errorf("idgen: %w", err)
+		}
+		g.lastMs = ms
+	} else if !increment(g.entropy[:]) {
+		// bagian acak habis (sangat jarang), pindah ke milidetik berikutnya
+		g.lastMs++
+		if _, err := rand.Read(g.entropy[:]); err != nil {
+			return "", fmt.Errorf("idgen: %w", err)
+		}
+	}
+
+	var id [16]byte
+	for i := 0; i < 6; i++ {
+		id[i] = byte(g.lastMs >> (40 - 8*i))
+	}
+	copy(id[6:], g.entropy[:])
+	return encodeCrockford(id), nil
+}
+
+// increment menambah 1 pada bilangan big endian, false jika overflow
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeCrockford mengubah 128 bit menjadi 26 karakter base32 Crockford
+func encodeCrockford(id [16]byte) string {
+	var hi, lo uint64
+	for i := 0; i < 8; i++ {
+		hi = hi<<8 | uint64(id[i])
+		lo = lo<<8 | uint64(id[8+i])
+	}
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// ===== UUIDv7 =====
+
+// UUIDv7 menghasilkan UUID versi 7 (RFC 9562). 12 bit setelah timestamp dipakai sebagai
+// counter, sehingga ID pada milidetik yang sama tetap berurutan dan tidak bentrok.
+type UUIDv7 struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	lastMs  uint64
+	counter uint16
+}
+
+func NewUUIDv7() *UUIDv7 {
+	return &UUIDv7{now: time.Now}
+}
+
+func (g *UUIDv7) NewID() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[6:]); err != nil {
+		return "", fmt.Errorf("idgen: %w", err)
+	}
+
+	g.mu.Lock()
+	ms := uint64(g.now().UnixMilli())
+	if ms > g.lastMs {
+		// counter dimulai dari angka acak di setengah bawah supaya masih ada ruang untuk bertambah
+		g.lastMs, g.counter = ms, uint16(id[6]&0x07)<<8|uint16(id[7])
+	} else {
+		g.counter++
+		if g.counter > 0x0fff {
+			g.lastMs, g.counter = g.lastMs+1, 0
+		}
+	}
+	ms, counter := g.lastMs, g.counter
+	g.mu.Unlock()
+
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	id[6] = 0x70 | byte(counter>>8) // versi 7
+	id[7] = byte(counter)
+	id[8] = id[8]&0x3f | 0x80 // variant RFC 9562
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16]), nil
+}
+
+// ===== Snowflake =====
+
+// SnowflakeEpoch adalah titik nol timestamp Snowflake (41 bit milidetik cukup untuk ~69 tahun)
+var SnowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	MaxSnowflakeNode      = 1<<snowflakeNodeBits - 1
+)
+
+// Snowflake menghasilkan ID angka 64 bit (ditulis sebagai string desimal):
+// 41 bit milidetik sejak SnowflakeEpoch, 10 bit node, 12 bit sequence.
+// Setiap proses / server harus memakai node yang berbeda.
+type Snowflake struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	node     int64
+	lastMs   int64
+	sequence int64
+}
+
+func NewSnowflake(node int64) (*Snowflake, error) {
+	if node < 0 || node > MaxSnowflakeNode {
+		return nil, fmt.Errorf("idgen: snowflake node must be between 0 and %d", MaxSnowflakeNode)
+	}
+	return &Snowflake{now: time.Now, node: node}, nil
+}
+
+func (g *Snowflake) NewID() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.now().Sub(SnowflakeEpoch).Milliseconds()
+	if ms < 0 {
+		return "", fmt.Errorf("idgen: clock is before snowflake epoch %s", SnowflakeEpoch)
+	}
+	if ms > g.lastMs {
+		g.lastMs, g.sequence = ms, 0
+	} else {
+		// milidetik yang sama (atau jam mundur): lanjutkan sequence dari waktu terakhir
+		g.sequence++
+		if g.sequence >= 1<<snowflakeSequenceBits {
+			g.lastMs, g.sequence = g.lastMs+1, 0
+		}
+	}
+
+	id := g.lastMs<<(snowflakeNodeBits+snowflakeSequenceBits) | g.node<<snowflakeSequenceBits | g.sequence
+	return fmt.Sprint(id), nil
+}
+
+// ===== Prefix =====
+
+// Prefix mempertahankan format ID lama: prefix + waktu (yyyyMMddHHmmss).
+// ID berikutnya pada detik yang sama diberi akhiran counter ("user-20240101120000-1"),
+// sehingga tidak bentrok di dalam satu proses. Untuk banyak proses gunakan generator lain.
+type Prefix struct {
+	mu         sync.Mutex
+	now        func() time.Time
+	prefix     string
+	lastSecond int64
+	counter    int
+}
+
+func NewPrefix(prefix string) *Prefix {
+	return &Prefix{now: time.Now, prefix: prefix}
+}
+
+func (g *Prefix) NewID() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	second := g.now().Unix()
+	if second > g.lastSecond {
+		g.lastSecond, g.counter = second, 0
+		return g.prefix + time.Unix(second, 0).Format("20060102150405"), nil
+	}
+	g.counter++
+	return fmt.Sprintf("%s%s-%d", g.prefix, time.Unix(g.lastSecond, 0).Format("20060102150405"), g.counter), nil
+}
@@ -0,0 +1,135 @@
+package idgen
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedClock membuat semua ID dibuat pada waktu yang sama, kasus terburuk untuk bentrok
+func fixedClock() time.Time {
+	return time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local)
+}
+
+func generators(t *testing.T) map[string]Generator {
+	snowflake, err := NewSnowflake(7)
+	assert.Nil(t, err)
+	snowflake.now = fixedClock
+
+	ulid, uuid, prefix := NewULID(), NewUUIDv7(), NewPrefix("user-")
+	ulid.now, uuid.now, prefix.now = fixedClock, fixedClock, fixedClock
+
+	return map[string]Generator{"ulid": ulid, "uuidv7": uuid, "snowflake": snowflake, "prefix": prefix}
+}
+
+func TestUniqueUnderConcurrency(t *testing.T) {
+	for name, generator := range generators(t) {
+		t.Run(name, func(t *testing.T) {
+			const workers, perWorker = 8, 1000
+			ids := make(chan string, workers*perWorker)
+			var wg sync.WaitGroup
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < perWorker; i++ {
+						id, err := generator.NewID()
+						assert.Nil(t, err)
+						ids <- id
+					}
+				}()
+			}
+			wg.Wait()
+			close(ids)
+
+			seen := map[string]bool{}
+			for id := range ids {
+				assert.False(t, seen[id], "duplicate id %s", id)
+				seen[id] = true
+			}
+			assert.Equal(t, workers*perWorker, len(seen))
+		})
+	}
+}
+
+func TestSortableByCreationOrder(t *testing.T) {
+	for _, name := range []string{"ulid", "uuidv7"} {
+		generator := generators(t)[name]
+		var ids []string
+		for i := 0; i < 5000; i++ { // melewati batas counter 12 bit milik UUIDv7
+			id, err := generator.NewID()
+			assert.Nil(t, err)
+			ids = append(ids, id)
+		}
+		assert.True(t, sort.StringsAreSorted(ids), name)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	g := generators(t)
+
+	id, _ := g["ulid"].NewID()
+	assert.Regexp(t, regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`), id)
+	var ms int64 // 10 karakter pertama adalah timestamp milidetik
+	for _, c := range id[:10] {
+		ms = ms<<5 | int64(strings.IndexRune(crockford, c))
+	}
+	assert.Equal(t, fixedClock().UnixMilli(), ms)
+
+	id, _ = g["uuidv7"].NewID()
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+
+	id, _ = g["snowflake"].NewID()
+	assert.Regexp(t, regexp.MustCompile(`^[0-9]+$`), id)
+
+	// format lama tetap dipakai untuk ID pertama, berikutnya diberi counter
+	first, _ := g["prefix"].NewID()
+	second, _ := g["prefix"].NewID()
+	assert.Equal(t, "user-20240506070809", first)
+	assert.Equal(t, "user-20240506070809-1", second)
+}
+
+func TestSnowflakeNode(t *testing.T) {
+	_, err := NewSnowflake(MaxSnowflakeNode + 1)
+	assert.NotNil(t, err)
+
+	a, _ := NewSnowflake(1)
+	b, _ := NewSnowflake(2)
+	a.now, b.now = fixedClock, fixedClock
+	idA, _ := a.NewID()
+	idB, _ := b.NewID()
+	assert.NotEqual(t, idA, idB) // node berbeda pada milidetik yang sama
+}
+
+type user struct{}
+type wallet struct{}
+
+func TestRegistryAndByName(t *testing.T) {
+	registry := NewRegistry(NewULID())
+	registry.Set(&user{}, Func(func() (string, error) { return "fixed", nil }))
+
+	id, err := registry.NewID(&user{})
+	assert.Nil(t, err)
+	assert.Equal(t, "fixed", id)
+	id, err = registry.NewID(user{}) // pointer maupun struct dianggap model yang sama
+	assert.Nil(t, err)
+	assert.Equal(t, "fixed", id)
+
+	id, err = registry.NewID(&wallet{})
+	assert.Nil(t, err)
+	assert.Len(t, id, 26)
+
+	for _, name := range []string{"ulid", "uuidv7", "snowflake", "prefix:order-"} {
+		generator, err := ByName(name)
+		assert.Nil(t, err, name)
+		_, err = generator.NewID()
+		assert.Nil(t, err, name)
+	}
+	_, err = ByName("random")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,67 @@
+// Package testapp menyiapkan database aplikasi untuk test package server dan rpc: SQLite in-memory yang
+// sudah dimigrasi (package migrations) dan berisi fixture testdata/fixtures/<name>.yml dari package utama.
+package testapp
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/fixtures"
+	"learn-golang-gorm/internal/testdb"
+	"learn-golang-gorm/migrations"
+	"learn-golang-gorm/password"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// hash password dengan cost minimal supaya fixture tetap cepat dimuat. Package ini hanya di-import oleh test.
+func init() {
+	learn_golang_gorm.PasswordHasher = password.Bcrypt{Cost: bcrypt.MinCost}
+}
+
+// DB membuka database baru untuk satu test, lalu memuat fixture names sekaligus dalam satu Set
+func DB(t testing.TB, names ...string) *gorm.DB {
+	t.Helper()
+	cfg := learn_golang_gorm.DefaultConfig()
+	cfg.Dialect = learn_golang_gorm.DialectSQLite
+	cfg.DSN = testdb.MemoryDSN(t)
+	cfg.LogLevel = "silent"
+	db, err := learn_golang_gorm.Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := migrations.Up(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) == 0 {
+		return db
+	}
+
+	loader, err := fixtures.New(db, learn_golang_gorm.Models()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		paths = append(paths, filepath.Join(fixtureDir(), name+".yml"))
+	}
+	if _, err := loader.LoadFiles(context.Background(), paths...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// fixtureDir adalah testdata/fixtures di root repo, tidak bergantung pada direktori package yang menjalankan test
+func fixtureDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "testdata", "fixtures")
+}
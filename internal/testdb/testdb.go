@@ -0,0 +1,63 @@
+// Package testdb membuka database SQLite untuk test di repo ini, supaya setiap package tidak perlu
+// menyalin fungsi openSQLite sendiri. Hanya bergantung pada GORM, jadi bisa dipakai oleh test package
+// apa pun (termasuk uow, repository dan pagination yang di-import oleh package utama).
+package testdb
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var counter atomic.Int64
+
+// MemoryDSN mengembalikan DSN database SQLite in-memory (shared cache, foreign key aktif) yang namanya
+// unik per pemanggilan, jadi test dengan nama yang sama (go test -count 2) tetap mendapat database bersih
+func MemoryDSN(t testing.TB) string {
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	return fmt.Sprintf("file:%s_%d?mode=memory&cache=shared&_foreign_keys=1", name, counter.Add(1))
+}
+
+// FileDSN mengembalikan DSN database SQLite di file sementara dengan _txlock=immediate (transaction antre)
+// dan busy timeout. SQLite in-memory dengan shared cache langsung gagal ("database table is locked") jika
+// dua transaction menulis bersamaan, jadi test yang menjalankan transaction bersamaan memakai DSN ini.
+func FileDSN(t testing.TB) string {
+	return "file:" + filepath.Join(t.TempDir(), "test.db") + "?_foreign_keys=1&_txlock=immediate&_busy_timeout=10000"
+}
+
+// Memory membuka database dari MemoryDSN lalu AutoMigrate models
+func Memory(t testing.TB, models ...interface{}) *gorm.DB {
+	t.Helper()
+	return Open(t, MemoryDSN(t), models...)
+}
+
+// File membuka database dari FileDSN lalu AutoMigrate models
+func File(t testing.TB, models ...interface{}) *gorm.DB {
+	t.Helper()
+	return Open(t, FileDSN(t), models...)
+}
+
+// Open membuka dsn dengan logger silent, lalu AutoMigrate models. Koneksi ditutup saat test selesai
+// (database in-memory hilang saat koneksi terakhir ditutup).
+func Open(t testing.TB, dsn string, models ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
@@ -0,0 +1,21 @@
+// Package trusted berisi pemeriksaan alamat proxy yang dipercaya, dipakai bersama oleh REST API
+// (package server) dan API gRPC (package rpc) sebelum menerima actor dari header / metadata.
+package trusted
+
+import "net/netip"
+
+// Addr memeriksa alamat "ip:port" (http.Request.RemoteAddr, net.Addr.String()) ada di salah satu proxies.
+// Alamat yang bukan ip:port (misalnya bufconn atau unix socket) tidak pernah dipercaya.
+func Addr(remoteAddr string, proxies []netip.Prefix) bool {
+	addrPort, err := netip.ParseAddrPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	addr := addrPort.Addr().Unmap()
+	for _, proxy := range proxies {
+		if proxy.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
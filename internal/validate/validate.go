@@ -0,0 +1,55 @@
+// Package validate berisi pemeriksaan isi request yang dipakai bersama oleh REST API (package server) dan
+// API gRPC (package rpc), supaya aturan dan pesan error-nya sama. Cara mengirim kesalahannya (422 atau
+// InvalidArgument) diatur oleh masing-masing package.
+package validate
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/money"
+)
+
+// Errors mengumpulkan semua kesalahan isi request supaya dikirim sekaligus
+type Errors []string
+
+func (v *Errors) Check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		*v = append(*v, fmt.Sprintf(format, args...))
+	}
+}
+
+// Required menolak nilai kosong, termasuk yang hanya berisi spasi
+func (v *Errors) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", "%s is required", field)
+}
+
+// MaxLength mengikuti ukuran kolom di migration, karena SQLite tidak menolak teks yang terlalu panjang
+func (v *Errors) MaxLength(field, value string, max int) {
+	v.Check(utf8.RuneCountInString(value) <= max, "%s must be at most %d characters", field, max)
+}
+
+// Currency memeriksa kode mata uang, kosong berarti DefaultCurrency
+func (v *Errors) Currency(field, code string) money.Currency {
+	if code == "" {
+		return learn_golang_gorm.DefaultCurrency
+	}
+	currency, err := money.ParseCurrency(code)
+	v.Check(err == nil, "%s %q is not supported", field, code)
+	return currency
+}
+
+// Name memeriksa struct embedded Name di User (kolom first_name, middle_name dan last_name)
+func (v *Errors) Name(field string, name learn_golang_gorm.Name) {
+	v.Required(field+".first", name.FirstName)
+	v.MaxLength(field+".first", name.FirstName, 255)
+	v.MaxLength(field+".middle", name.MiddleName, 100)
+	v.MaxLength(field+".last", name.LastName, 100)
+}
+
+// Message menggabungkan semua kesalahan, kosong jika tidak ada
+func (v Errors) Message() string {
+	return strings.Join(v, "; ")
+}
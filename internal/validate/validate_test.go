@@ -0,0 +1,26 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/money"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	var v Errors
+	v.Required("id", "  ") // hanya spasi dianggap kosong
+	v.Required("password", "rahasia")
+	v.MaxLength("note", strings.Repeat("é", 10), 10) // dihitung per karakter, bukan byte
+	v.Name("name", learn_golang_gorm.Name{LastName: strings.Repeat("x", 101)})
+	assert.Equal(t, learn_golang_gorm.DefaultCurrency, v.Currency("currency", ""))
+	assert.Equal(t, money.Currency("USD"), v.Currency("currency", "USD"))
+	v.Currency("currency", "XYZ")
+
+	assert.Equal(t, `id is required; name.first is required; name.last must be at most 100 characters; `+
+		`currency "XYZ" is not supported`, v.Message())
+	assert.Empty(t, Errors(nil).Message())
+}
@@ -0,0 +1,251 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Jenis journal
+const (
+	LedgerJournalOpening    = "opening_balance"
+	LedgerJournalTransfer   = "transfer"
+	LedgerJournalAdjustment = "adjustment"
+	LedgerJournalTopUp      = "top_up"
+	LedgerJournalCapture    = "hold_capture"
+)
+
+// Akun di luar wallet, lawan dari saldo awal, top up, capture hold dan koreksi hasil rekonsiliasi
+const (
+	LedgerAccountOpening    = "equity:opening_balance"
+	LedgerAccountAdjustment = "equity:adjustment"
+	LedgerAccountTopUp      = "external:top_up"
+	LedgerAccountCapture    = "external:capture"
+)
+
+var ErrUnbalancedJournal = errors.New("ledger journal must have at least 2 postings that sum to zero")
+
+// LedgerJournal adalah satu kejadian di ledger (append-only, tidak pernah diubah / dihapus)
+type LedgerJournal struct {
+	ID        string          `gorm:"primary_key;column:id;size:100"`
+	Kind      string          `gorm:"column:kind;size:50"`
+	Reference string          `gorm:"column:reference;size:100"` // misalnya transfer_id atau wallet id
+	CreatedAt time.Time       `gorm:"column:created_at;autoCreateTime"`
+	Postings  []LedgerPosting `gorm:"foreignKey:journal_id;references:id"`
+}
+
+// LedgerPosting adalah perubahan saldo satu akun. Amount positif menambah saldo, negatif mengurangi.
+// Amount dalam minor unit mata uang wallet-nya (satu journal selalu satu mata uang).
+type LedgerPosting struct {
+	ID        string    `gorm:"primary_key;column:id;size:100"`
+	JournalID string    `gorm:"column:journal_id;size:100"`
+	Account   string    `gorm:"column:account;size:100"`
+	WalletID  *string   `gorm:"column:wallet_id;size:100"` // hanya terisi untuk akun wallet
+	Amount    int64     `gorm:"column:amount"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (j *LedgerJournal) BeforeCreate(db *gorm.DB) error {
+	return assignID(j, &j.ID)
+}
+
+func (p *LedgerPosting) BeforeCreate(db *gorm.DB) error {
+	return assignID(p, &p.ID)
+}
+
+// WalletAccount adalah nama akun ledger untuk sebuah wallet
+func WalletAccount(walletID string) string {
+	return "wallet:" + walletID
+}
+
+// WalletPosting membuat posting ke akun wallet
+func WalletPosting(walletID string, amount int64) LedgerPosting {
+	return LedgerPosting{Account: WalletAccount(walletID), WalletID: &walletID, Amount: amount}
+}
+
+// AccountPosting membuat posting ke akun selain wallet (misalnya LedgerAccountOpening)
+func AccountPosting(account string, amount int64) LedgerPosting {
+	return LedgerPosting{Account: account, Amount: amount}
+}
+
+// PostJournal menulis journal beserta posting-nya. Harus dipanggil di transaction yang sama
+// dengan perubahan Wallet.Balance, supaya saldo dan ledger selalu berubah bersamaan.
+func PostJournal(tx *gorm.DB, kind, reference string, postings ...LedgerPosting) (*LedgerJournal, error) {
+	var sum int64
+	for _, posting := range postings {
+		if posting.Amount == 0 {
+			return nil, fmt.Errorf("%w: posting to %s has zero amount", ErrUnbalancedJournal, posting.Account)
+		}
+		sum += posting.Amount
+	}
+	if len(postings) < 2 || sum != 0 {
+		return nil, fmt.Errorf("%w: %d postings, sum %d", ErrUnbalancedJournal, len(postings), sum)
+	}
+
+	journal := &LedgerJournal{Kind: kind, Reference: reference, Postings: postings}
+	if err := tx.Create(journal).Error; err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// AfterCreate mencatat saldo awal wallet ke ledger, sehingga sejak dibuat saldo wallet
+// selalu sama dengan jumlah posting-nya. Dilewati jika wallet sudah punya posting
+// (misalnya insert yang diabaikan karena ON CONFLICT DO NOTHING).
+func (w *Wallet) AfterCreate(tx *gorm.DB) error {
+	var postings int64
+	if err := tx.Model(&LedgerPosting{}).Where("wallet_id = ?", w.ID).Count(&postings).Error; err != nil || postings > 0 {
+		return err
+	}
+	// saldo dibaca dari database, bukan dari struct, karena insert bisa saja diabaikan
+	var balance int64
+	if err := tx.Model(&Wallet{}).Select("balance_amount").Where("id = ?", w.ID).Scan(&balance).Error; err != nil || balance == 0 {
+		return err
+	}
+	_, err := PostJournal(tx, LedgerJournalOpening, w.ID,
+		WalletPosting(w.ID, balance),
+		AccountPosting(LedgerAccountOpening, -balance),
+	)
+	return err
+}
+
+// Ledger membaca histori saldo dan melakukan rekonsiliasi
+type Ledger struct {
+	db *gorm.DB
+}
+
+func NewLedger(db *gorm.DB) *Ledger {
+	return &Ledger{db: db}
+}
+
+// WalletBalance menghitung saldo wallet dari posting-nya
+func (l *Ledger) WalletBalance(ctx context.Context, walletID string) (int64, error) {
+	return ledgerBalance(l.db.WithContext(ctx), walletID)
+}
+
+func ledgerBalance(tx *gorm.DB, walletID string) (int64, error) {
+	var balance int64
+	err := tx.Model(&LedgerPosting{}).Select("COALESCE(SUM(amount), 0)").Where("wallet_id = ?", walletID).Scan(&balance).Error
+	return balance, err
+}
+
+// WalletHistory mengembalikan semua posting wallet (paling lama lebih dulu) untuk menjelaskan saldonya
+func (l *Ledger) WalletHistory(ctx context.Context, walletID string) ([]LedgerPosting, error) {
+	var postings []LedgerPosting
+	err := l.db.WithContext(ctx).Where("wallet_id = ?", walletID).Order("created_at, id").Find(&postings).Error
+	return postings, err
+}
+
+// RepairMode menentukan apa yang dilakukan Reconcile jika saldo wallet berbeda dengan ledger
+type RepairMode int
+
+const (
+	RepairNone    RepairMode = iota // hanya laporan
+	RepairBalance                   // ledger dianggap benar: Wallet.Balance diganti dengan jumlah posting
+	RepairLedger                    // saldo dianggap benar: ditambahkan journal adjustment (misalnya wallet lama tanpa histori)
+)
+
+// WalletDrift adalah wallet yang saldonya berbeda dengan jumlah posting di ledger
+type WalletDrift struct {
+	WalletID      string
+	Balance       int64
+	LedgerBalance int64
+	Repaired      bool
+}
+
+// Drift adalah selisih saldo tersimpan terhadap ledger
+func (d WalletDrift) Drift() int64 {
+	return d.Balance - d.LedgerBalance
+}
+
+// ReconciliationReport adalah hasil Reconcile
+type ReconciliationReport struct {
+	Wallets            int // jumlah wallet yang diperiksa
+	Drifts             []WalletDrift
+	UnbalancedJournals []string // journal yang jumlah posting-nya bukan 0
+}
+
+// OK bernilai true jika tidak ada selisih yang belum diperbaiki
+func (r *ReconciliationReport) OK() bool {
+	for _, drift := range r.Drifts {
+		if !drift.Repaired {
+			return false
+		}
+	}
+	return len(r.UnbalancedJournals) == 0
+}
+
+// Reconcile membandingkan Wallet.Balance dengan jumlah posting setiap wallet.
+// Selisih diperiksa ulang sambil mengunci wallet-nya, jadi transfer yang sedang berjalan
+// tidak dilaporkan sebagai selisih, lalu diperbaiki sesuai mode.
+func (l *Ledger) Reconcile(ctx context.Context, mode RepairMode) (*ReconciliationReport, error) {
+	db := l.db.WithContext(ctx)
+
+	// pola agregasi yang sama dengan TestAggregation, digabung per wallet
+	var rows []WalletDrift
+	err := db.Model(&Wallet{}).
+		Select("wallets.id AS wallet_id", "wallets.balance_amount AS balance", "COALESCE(SUM(ledger_postings.amount), 0) AS ledger_balance").
+		Joins("LEFT JOIN ledger_postings ON ledger_postings.wallet_id = wallets.id").
+		Group("wallets.id, wallets.balance_amount").
+		Order("wallets.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconciliationReport{Wallets: len(rows)}
+	for _, row := range rows {
+		if row.Balance == row.LedgerBalance {
+			continue
+		}
+		drift, err := l.recheck(db, row.WalletID, mode)
+		if err != nil {
+			return nil, fmt.Errorf("reconcile wallet %s: %w", row.WalletID, err)
+		}
+		if drift != nil {
+			report.Drifts = append(report.Drifts, *drift)
+		}
+	}
+
+	err = db.Model(&LedgerPosting{}).Group("journal_id").Having("SUM(amount) <> 0").Order("journal_id").
+		Pluck("journal_id", &report.UnbalancedJournals).Error
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// recheck menghitung ulang selisih satu wallet di dalam transaction (wallet dikunci), lalu memperbaikinya
+func (l *Ledger) recheck(db *gorm.DB, walletID string, mode RepairMode) (*WalletDrift, error) {
+	var drift *WalletDrift
+	err := db.Transaction(func(tx *gorm.DB) error {
+		wallets, err := lockWallets(tx, walletID)
+		if err != nil {
+			return err
+		}
+		wallet := wallets[walletID]
+		balance, err := ledgerBalance(tx, walletID)
+		if err != nil || balance == wallet.Balance.Amount {
+			return err
+		}
+
+		drift = &WalletDrift{WalletID: walletID, Balance: wallet.Balance.Amount, LedgerBalance: balance}
+		switch mode {
+		case RepairBalance:
+			err = tx.Model(wallet).Update("balance_amount", balance).Error
+		case RepairLedger:
+			_, err = PostJournal(tx, LedgerJournalAdjustment, walletID,
+				WalletPosting(walletID, drift.Drift()),
+				AccountPosting(LedgerAccountAdjustment, -drift.Drift()),
+			)
+		default:
+			return nil
+		}
+		drift.Repaired = err == nil
+		return err
+	})
+	return drift, err
+}
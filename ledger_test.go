@@ -0,0 +1,116 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerFollowsWalletBalance(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	ledger := NewLedger(db)
+
+	// saldo awal setiap wallet fixture otomatis tercatat
+	balance, err := ledger.WalletBalance(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000000), balance)
+
+	_, err = NewTransferService(db).Transfer(ctx, "1", "2", idr(300000), "payout-1")
+	assert.Nil(t, err)
+
+	balance, err = ledger.WalletBalance(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(700000), balance)
+
+	history, err := ledger.WalletHistory(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, int64(1000000), history[0].Amount)
+	assert.Equal(t, int64(-300000), history[1].Amount)
+
+	// double-entry: jumlah semua posting selalu 0
+	var total int64
+	assert.Nil(t, db.Model(&LedgerPosting{}).Select("sum(amount)").Scan(&total).Error)
+	assert.Equal(t, int64(0), total)
+
+	report, err := ledger.Reconcile(ctx, RepairNone)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, report.Wallets)
+	assert.True(t, report.OK())
+	assert.Empty(t, report.Drifts)
+}
+
+func TestPostJournalRejectsUnbalanced(t *testing.T) {
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
+	_, err := PostJournal(db, LedgerJournalAdjustment, "1", WalletPosting("1", 100), AccountPosting(LedgerAccountAdjustment, -99))
+	assert.ErrorIs(t, err, ErrUnbalancedJournal)
+	_, err = PostJournal(db, LedgerJournalAdjustment, "1", WalletPosting("1", 100))
+	assert.ErrorIs(t, err, ErrUnbalancedJournal)
+	_, err = PostJournal(db, LedgerJournalAdjustment, "1", WalletPosting("1", 0), AccountPosting(LedgerAccountAdjustment, 0))
+	assert.ErrorIs(t, err, ErrUnbalancedJournal)
+}
+
+func TestReconcileRepair(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	ledger := NewLedger(db)
+
+	// saldo diubah langsung tanpa journal
+	assert.Nil(t, db.Model(&Wallet{}).Where("id = ?", "1").Update("balance_amount", 1200000).Error)
+	assert.Nil(t, db.Model(&Wallet{}).Where("id = ?", "2").Update("balance_amount", 900000).Error)
+
+	report, err := ledger.Reconcile(ctx, RepairNone)
+	assert.Nil(t, err)
+	assert.False(t, report.OK())
+	assert.Equal(t, []WalletDrift{
+		{WalletID: "1", Balance: 1200000, LedgerBalance: 1000000},
+		{WalletID: "2", Balance: 900000, LedgerBalance: 1000000},
+	}, report.Drifts)
+	assert.Equal(t, int64(200000), report.Drifts[0].Drift())
+
+	// ledger dianggap benar: saldo dikembalikan
+	report, err = ledger.Reconcile(ctx, RepairBalance)
+	assert.Nil(t, err)
+	assert.True(t, report.OK())
+	var wallet Wallet
+	assert.Nil(t, db.Take(&wallet, "id = ?", "1").Error)
+	assert.Equal(t, idr(1000000), wallet.Balance)
+
+	// saldo dianggap benar: ledger ditambah journal adjustment
+	assert.Nil(t, db.Model(&Wallet{}).Where("id = ?", "2").Update("balance_amount", 900000).Error)
+	report, err = ledger.Reconcile(ctx, RepairLedger)
+	assert.Nil(t, err)
+	assert.True(t, report.OK())
+	balance, err := ledger.WalletBalance(ctx, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(900000), balance)
+
+	report, err = ledger.Reconcile(ctx, RepairNone)
+	assert.Nil(t, err)
+	assert.Empty(t, report.Drifts)
+	assert.Empty(t, report.UnbalancedJournals)
+}
+
+func TestReconcileReportsUnbalancedJournal(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+
+	// posting yang ditulis tanpa PostJournal (melanggar double-entry)
+	journal := LedgerJournal{Kind: LedgerJournalAdjustment, Reference: "manual"}
+	assert.Nil(t, db.Create(&journal).Error)
+	posting := AccountPosting(LedgerAccountAdjustment, 10)
+	posting.JournalID = journal.ID
+	assert.Nil(t, db.Create(&posting).Error)
+
+	report, err := NewLedger(db).Reconcile(ctx, RepairNone)
+	assert.Nil(t, err)
+	assert.False(t, report.OK())
+	assert.Equal(t, []string{journal.ID}, report.UnbalancedJournals)
+}
@@ -0,0 +1,420 @@
+// Package migrations menggantikan langkah SQL manual di README dengan migration bernomor.
+//
+// File migration ada di folder sql/<dialect>/ dengan format NNNN_nama.up.sql dan NNNN_nama.down.sql.
+// Versi yang sudah dijalankan dicatat di tabel schema_migrations beserta checksum-nya,
+// sehingga migration yang diedit setelah dijalankan bisa terdeteksi.
+//
+// Setiap migration dijalankan di dalam transaction. Di SQLite dan Postgres DDL ikut transaction, jadi migration
+// yang gagal tidak meninggalkan perubahan apa pun. MySQL melakukan commit implisit setiap DDL (CREATE, ALTER,
+// DROP, ...), sehingga migration yang gagal di tengah bisa meninggalkan sebagian perubahan. Karena itu versinya
+// dicatat dulu sebagai dirty sebelum dijalankan (seperti golang-migrate). Selama ada migration yang dirty,
+// Up, Down dan Redo ditolak dengan ErrDirty: perbaiki schema secara manual, lalu tandai hasilnya dengan
+// Resolve (applied atau belum).
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql
+var embedded embed.FS
+
+var (
+	ErrChecksumMismatch = errors.New("migrations: applied migration has been modified")
+	ErrNoMigration      = errors.New("migrations: no migration to roll back")
+	ErrDirty            = errors.New("migrations: migration failed halfway, fix the schema then resolve it")
+	ErrNotDirty         = errors.New("migrations: no dirty migration to resolve")
+)
+
+// Migration adalah satu langkah perubahan schema
+type Migration struct {
+	Version  int64
+	Name     string
+	UpSQL    string
+	DownSQL  string
+	Checksum string // sha256 dari isi file up dan down
+}
+
+// SchemaMigration adalah baris pada tabel schema_migrations
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	Checksum  string    `gorm:"size:64;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+	Dirty     bool      `gorm:"not null;default:false"` // sedang / gagal dijalankan, lihat Resolve
+}
+
+func (s *SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status menggambarkan keadaan satu migration terhadap database
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+	Modified  bool // checksum di database berbeda dengan file migration
+	Dirty     bool // gagal di tengah jalan, schema-nya mungkin hanya berubah sebagian
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	// transactionalDDL false untuk MySQL: DDL yang gagal tidak ikut di-rollback
+	transactionalDDL bool
+}
+
+func newMigrator(db *gorm.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations, transactionalDDL: db.Dialector.Name() != "mysql"}
+}
+
+// New membuat Migrator dengan migration bawaan sesuai dialect db (mysql, postgres atau sqlite)
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := loadEmbedded(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return newMigrator(db, migrations), nil
+}
+
+func loadEmbedded(dialect string) ([]Migration, error) {
+	sub, err := fs.Sub(embedded, path.Join("sql", dialect))
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(sub)
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("migrations: no migration found for dialect %s", dialect)
+	}
+	return migrations, nil
+}
+
+// NewFromFS membuat Migrator dari file *.up.sql / *.down.sql di root fsys
+func NewFromFS(db *gorm.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return newMigrator(db, migrations), nil
+}
+
+// Up menjalankan semua migration bawaan yang belum dijalankan. Cukup satu panggilan untuk database baru.
+func Up(ctx context.Context, db *gorm.DB) error {
+	m, err := New(db)
+	if err != nil {
+		return err
+	}
+	return m.Up(ctx)
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load membaca dan mengurutkan migration dari fsys
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrations: %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrations: version %d is used by %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.UpSQL = string(content)
+		} else {
+			migration.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpSQL == "" || migration.DownSQL == "" {
+			return nil, fmt.Errorf("migrations: %04d_%s must have both up and down file", migration.Version, migration.Name)
+		}
+		sum := sha256.Sum256([]byte(migration.UpSQL + "\x00" + migration.DownSQL))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	return m.db.WithContext(ctx).AutoMigrate(&SchemaMigration{})
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]SchemaMigration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	var rows []SchemaMigration
+	if err := m.db.WithContext(ctx).Order("version asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Status mengembalikan keadaan semua migration, urut berdasarkan versi
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			status.Applied = !row.Dirty
+			status.AppliedAt = row.AppliedAt
+			status.Modified = row.Checksum != migration.Checksum
+			status.Dirty = row.Dirty
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// clean memastikan tidak ada migration yang gagal di tengah jalan (lihat Resolve)
+func (m *Migrator) clean(statuses []Status) error {
+	for _, status := range statuses {
+		if status.Dirty {
+			return fmt.Errorf("%w: %04d_%s", ErrDirty, status.Version, status.Name)
+		}
+	}
+	return nil
+}
+
+// verify memastikan tidak ada migration yang dirty dan migration yang sudah dijalankan tidak diedit
+func (m *Migrator) verify(statuses []Status) error {
+	if err := m.clean(statuses); err != nil {
+		return err
+	}
+	var modified []string
+	for _, status := range statuses {
+		if status.Modified {
+			modified = append(modified, fmt.Sprintf("%04d_%s", status.Version, status.Name))
+		}
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, strings.Join(modified, ", "))
+	}
+	return nil
+}
+
+// Up menjalankan semua migration yang belum dijalankan secara berurutan
+func (m *Migrator) Up(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if err := m.verify(statuses); err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if status.Applied {
+			continue
+		}
+		if err := m.run(ctx, status.Migration, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Down membatalkan migration terakhir sebanyak steps (steps <= 0 artinya semua)
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if err := m.verify(statuses); err != nil {
+		return err
+	}
+
+	rolledBack := 0
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if steps > 0 && rolledBack == steps {
+			break
+		}
+		if !statuses[i].Applied {
+			continue
+		}
+		if err := m.run(ctx, statuses[i].Migration, false); err != nil {
+			return err
+		}
+		rolledBack++
+	}
+	if rolledBack == 0 {
+		return ErrNoMigration
+	}
+	return nil
+}
+
+// Redo membatalkan lalu menjalankan ulang migration terakhir
+func (m *Migrator) Redo(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if err := m.clean(statuses); err != nil {
+		return err
+	}
+
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if !statuses[i].Applied {
+			continue
+		}
+		// checksum sengaja tidak dicek, redo dipakai saat mengembangkan migration terakhir
+		if err := m.run(ctx, statuses[i].Migration, false); err != nil {
+			return err
+		}
+		return m.run(ctx, statuses[i].Migration, true)
+	}
+	return ErrNoMigration
+}
+
+// Resolve menandai migration yang dirty setelah schema-nya diperbaiki secara manual:
+// applied true berarti perubahan migration tersebut sudah lengkap, false berarti sudah dibatalkan seluruhnya
+func (m *Migrator) Resolve(ctx context.Context, applied bool) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if !status.Dirty {
+			continue
+		}
+		db := m.db.WithContext(ctx).Model(&SchemaMigration{}).Where("version = ?", status.Version)
+		if !applied {
+			return db.Delete(&SchemaMigration{}).Error
+		}
+		return db.Updates(map[string]interface{}{"checksum": status.Checksum, "applied_at": time.Now(), "dirty": false}).Error
+	}
+	return ErrNotDirty
+}
+
+func (m *Migrator) run(ctx context.Context, migration Migration, up bool) error {
+	script, direction := migration.DownSQL, "down"
+	if up {
+		script, direction = migration.UpSQL, "up"
+	}
+	db := m.db.WithContext(ctx)
+	version := func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&SchemaMigration{}).Where("version = ?", migration.Version)
+	}
+
+	// dicatat dulu di luar transaction, supaya tetap tersimpan jika DDL MySQL sudah ter-commit sebagian
+	var err error
+	if up {
+		err = db.Create(&SchemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: time.Now(),
+			Dirty:     true,
+		}).Error
+	} else {
+		err = version(db).Update("dirty", true).Error
+	}
+	if err != nil {
+		return fmt.Errorf("migrations: %04d_%s %s: %w", migration.Version, migration.Name, direction, err)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range splitStatements(script) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		if !up {
+			return version(tx).Delete(&SchemaMigration{}).Error
+		}
+		return version(tx).Updates(map[string]interface{}{"applied_at": time.Now(), "dirty": false}).Error
+	})
+	if err != nil && m.transactionalDDL {
+		// semua statement ikut di-rollback, jadi tanda dirty boleh dibatalkan lagi
+		if up {
+			err = errors.Join(err, version(db).Delete(&SchemaMigration{}).Error)
+		} else {
+			err = errors.Join(err, version(db).Update("dirty", false).Error)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("migrations: %04d_%s %s: %w", migration.Version, migration.Name, direction, err)
+	}
+	return nil
+}
+
+// splitStatements memecah isi file menjadi beberapa statement (dipisah ';' di akhir baris),
+// karena driver mysql tidak menerima banyak statement dalam satu Exec
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,174 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"learn-golang-gorm/internal/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func openSQLite(t *testing.T) *gorm.DB {
+	return testdb.Memory(t)
+}
+
+func TestEmbeddedMigrationsForEveryDialect(t *testing.T) {
+	var versions []int64
+	for _, dialect := range []string{"mysql", "postgres", "sqlite"} {
+		m := &Migrator{}
+		var err error
+		m.migrations, err = loadEmbedded(dialect)
+		assert.Nil(t, err)
+
+		current := make([]int64, 0, len(m.migrations))
+		for _, migration := range m.migrations {
+			current = append(current, migration.Version)
+		}
+		// semua dialect harus punya daftar versi yang sama
+		if versions == nil {
+			versions = current
+		}
+		assert.Equal(t, versions, current, dialect)
+	}
+}
+
+func TestUpDownStatusRedo(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	m, err := New(db)
+	assert.Nil(t, err)
+	assert.Nil(t, m.Up(ctx))
+	assert.Nil(t, m.Up(ctx)) // menjalankan ulang tidak melakukan apa-apa
+
+	statuses, err := m.Status(ctx)
+	assert.Nil(t, err)
+	for _, status := range statuses {
+		assert.True(t, status.Applied, status.Name)
+		assert.False(t, status.Modified, status.Name)
+	}
+
+	// hasil migration sesuai dengan perubahan di README (name -> first_name, timestamp user_logs -> bigint)
+	assert.True(t, db.Migrator().HasColumn("users", "first_name"))
+	assert.True(t, db.Migrator().HasColumn("users", "last_name"))
+	assert.NotNil(t, db.Exec("select name from users").Error)
+	assert.Nil(t, db.Exec("insert into user_logs(user_id, action, created_at, updated_at) values (?, ?, ?, ?)", "1", "login", 1700000000000, 1700000000000).Error)
+
+	assert.Nil(t, m.Down(ctx, 1))
+	statuses, err = m.Status(ctx)
+	assert.Nil(t, err)
+	assert.False(t, statuses[len(statuses)-1].Applied)
+	assert.True(t, statuses[len(statuses)-2].Applied)
+
+	assert.Nil(t, m.Up(ctx))
+	assert.Nil(t, m.Redo(ctx))
+	statuses, err = m.Status(ctx)
+	assert.Nil(t, err)
+	assert.True(t, statuses[len(statuses)-1].Applied)
+
+	assert.Nil(t, m.Down(ctx, 0))
+	assert.False(t, db.Migrator().HasTable("users"))
+	assert.ErrorIs(t, m.Down(ctx, 1), ErrNoMigration)
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	files := fstest.MapFS{
+		"0001_create_notes.up.sql":   {Data: []byte("CREATE TABLE notes (id INTEGER PRIMARY KEY);")},
+		"0001_create_notes.down.sql": {Data: []byte("DROP TABLE notes;")},
+	}
+	m, err := NewFromFS(db, files)
+	assert.Nil(t, err)
+	assert.Nil(t, m.Up(ctx))
+
+	// file migration diedit setelah dijalankan
+	files["0001_create_notes.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT);")}
+	m, err = NewFromFS(db, files)
+	assert.Nil(t, err)
+
+	statuses, err := m.Status(ctx)
+	assert.Nil(t, err)
+	assert.True(t, statuses[0].Modified)
+	assert.ErrorIs(t, m.Up(ctx), ErrChecksumMismatch)
+}
+
+func TestDirtyMigration(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	files := fstest.MapFS{
+		"0001_create_notes.up.sql":   {Data: []byte("CREATE TABLE notes (id INTEGER PRIMARY KEY);\nINSERT INTO missing VALUES (1);")},
+		"0001_create_notes.down.sql": {Data: []byte("DROP TABLE notes;")},
+	}
+	m, err := NewFromFS(db, files)
+	assert.Nil(t, err)
+
+	// SQLite: CREATE TABLE ikut di-rollback, jadi versinya tidak ditandai dirty
+	assert.ErrorContains(t, m.Up(ctx), "0001_create_notes up")
+	statuses, err := m.Status(ctx)
+	assert.Nil(t, err)
+	assert.False(t, statuses[0].Applied)
+	assert.False(t, statuses[0].Dirty)
+	assert.False(t, db.Migrator().HasTable("notes"))
+	assert.ErrorIs(t, m.Resolve(ctx, true), ErrNotDirty)
+
+	// seperti MySQL: DDL yang sudah jalan tidak bisa di-rollback, Up / Down / Redo ditolak sampai di-resolve
+	m.transactionalDDL = false
+	assert.NotNil(t, m.Up(ctx))
+	statuses, err = m.Status(ctx)
+	assert.Nil(t, err)
+	assert.False(t, statuses[0].Applied)
+	assert.True(t, statuses[0].Dirty)
+	assert.ErrorIs(t, m.Up(ctx), ErrDirty)
+	assert.ErrorIs(t, m.Down(ctx, 1), ErrDirty)
+	assert.ErrorIs(t, m.Redo(ctx), ErrDirty)
+
+	// tabel notes dibuat manual, lalu migration ditandai sudah lengkap
+	assert.Nil(t, db.Exec("CREATE TABLE notes (id INTEGER PRIMARY KEY)").Error)
+	assert.Nil(t, m.Resolve(ctx, true))
+	statuses, err = m.Status(ctx)
+	assert.Nil(t, err)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[0].Dirty)
+	assert.Nil(t, m.Down(ctx, 1))
+	assert.False(t, db.Migrator().HasTable("notes"))
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := splitStatements(`
+-- komentar
+CREATE TABLE a (id INT);
+
+ALTER TABLE a
+    ADD COLUMN b INT;
+`)
+	assert.Equal(t, []string{"CREATE TABLE a (id INT);", "ALTER TABLE a\n    ADD COLUMN b INT;"}, statements)
+}
+
+// 0018 dicek dulu sebelum membuat index unique, supaya database dengan user ber-wallet ganda gagal dengan jelas
+func TestUniqueWalletPerUserCheck(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	m, err := New(db)
+	assert.Nil(t, err)
+	assert.Nil(t, m.Up(ctx))
+	assert.Nil(t, m.Down(ctx, 1)) // kembali ke sebelum 0018
+	assert.Nil(t, db.Exec("INSERT INTO users (id, password, first_name) VALUES ('1', 'x', 'Budi')").Error)
+	assert.Nil(t, db.Exec("INSERT INTO wallets (id, user_id, balance_amount) VALUES ('w1', '1', 0), ('w2', '1', 0)").Error)
+
+	err = m.Up(ctx)
+	assert.ErrorContains(t, err, "0018_add_unique_index_wallets_user_id up")
+	assert.ErrorContains(t, err, "wallets_one_per_user")
+	statuses, err := m.Status(ctx)
+	assert.Nil(t, err)
+	assert.False(t, statuses[len(statuses)-1].Applied)
+
+	// setelah wallet ganda dibereskan migration bisa dijalankan ulang
+	assert.Nil(t, db.Exec("DELETE FROM wallets WHERE id = 'w2'").Error)
+	assert.Nil(t, m.Up(ctx))
+	assert.NotNil(t, db.Exec("INSERT INTO wallets (id, user_id, balance_amount) VALUES ('w3', '1', 0)").Error)
+}
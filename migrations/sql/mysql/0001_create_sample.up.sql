@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS sample
+(
+    id   VARCHAR(100) NOT NULL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL
+) ENGINE = InnoDB;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS users
+(
+    id         VARCHAR(100) NOT NULL PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    password   VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE = InnoDB;
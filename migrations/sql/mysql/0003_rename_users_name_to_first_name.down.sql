@@ -0,0 +1,6 @@
+ALTER TABLE users
+    DROP COLUMN middle_name,
+    DROP COLUMN last_name;
+
+ALTER TABLE users
+    CHANGE first_name name VARCHAR(100) NOT NULL;
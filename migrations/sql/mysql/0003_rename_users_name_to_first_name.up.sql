@@ -0,0 +1,8 @@
+-- Ubah nama kolom 'name' menjadi 'first_name'
+ALTER TABLE users
+    CHANGE name first_name VARCHAR(255);
+
+-- Tambahkan kolom baru
+ALTER TABLE users
+    ADD COLUMN middle_name VARCHAR(100) NULL AFTER first_name,
+    ADD COLUMN last_name   VARCHAR(100) NULL AFTER middle_name;
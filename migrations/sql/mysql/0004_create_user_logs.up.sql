@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS user_logs
+(
+    id         INT AUTO_INCREMENT PRIMARY KEY,
+    user_id    VARCHAR(100) NOT NULL,
+    action     VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE = InnoDB;
@@ -0,0 +1,12 @@
+ALTER TABLE user_logs
+    ADD COLUMN created_at_ts TIMESTAMP NULL AFTER created_at,
+    ADD COLUMN updated_at_ts TIMESTAMP NULL AFTER updated_at;
+UPDATE user_logs
+SET created_at_ts = FROM_UNIXTIME(created_at DIV 1000),
+    updated_at_ts = FROM_UNIXTIME(updated_at DIV 1000);
+ALTER TABLE user_logs
+    DROP COLUMN created_at,
+    DROP COLUMN updated_at;
+ALTER TABLE user_logs
+    CHANGE created_at_ts created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHANGE updated_at_ts updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;
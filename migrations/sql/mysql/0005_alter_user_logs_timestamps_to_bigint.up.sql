@@ -0,0 +1,14 @@
+-- nilai lama diubah menjadi epoch milidetik, sama seperti autoCreateTime:milli pada model UserLog.
+-- MODIFY langsung ke BIGINT mengubah '2023-01-02 03:04:05' menjadi 20230102030405, jadi lewat kolom sementara.
+ALTER TABLE user_logs
+    ADD COLUMN created_at_ms BIGINT NULL AFTER created_at,
+    ADD COLUMN updated_at_ms BIGINT NULL AFTER updated_at;
+UPDATE user_logs
+SET created_at_ms = UNIX_TIMESTAMP(created_at) * 1000,
+    updated_at_ms = UNIX_TIMESTAMP(updated_at) * 1000;
+ALTER TABLE user_logs
+    DROP COLUMN created_at,
+    DROP COLUMN updated_at;
+ALTER TABLE user_logs
+    CHANGE created_at_ms created_at BIGINT NOT NULL,
+    CHANGE updated_at_ms updated_at BIGINT NOT NULL;
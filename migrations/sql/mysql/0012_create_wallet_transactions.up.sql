@@ -0,0 +1,18 @@
+-- setiap transfer menghasilkan 2 baris: debit pada wallet asal dan credit pada wallet tujuan
+CREATE TABLE wallet_transactions
+(
+    id                     VARCHAR(100) NOT NULL,
+    transfer_id            VARCHAR(100) NOT NULL,
+    wallet_id              VARCHAR(100) NOT NULL,
+    counterparty_wallet_id VARCHAR(100) NOT NULL,
+    type                   VARCHAR(10)  NOT NULL,
+    amount                 BIGINT       NOT NULL,
+    balance_after          BIGINT       NOT NULL,
+    idempotency_key        VARCHAR(100) NOT NULL,
+    created_at             TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets (id),
+    FOREIGN KEY (counterparty_wallet_id) REFERENCES wallets (id)
+) ENGINE = InnoDB;
+CREATE UNIQUE INDEX uq_wallet_transactions_idempotency_key ON wallet_transactions (idempotency_key, type);
+CREATE INDEX idx_wallet_transactions_wallet_id ON wallet_transactions (wallet_id, created_at);
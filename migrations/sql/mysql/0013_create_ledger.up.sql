@@ -0,0 +1,24 @@
+-- ledger double-entry: setiap journal berisi minimal 2 posting yang jumlahnya 0.
+-- posting ke akun wallet mengisi wallet_id, jadi saldo wallet = SUM(amount) posting miliknya
+CREATE TABLE ledger_journals
+(
+    id         VARCHAR(100) NOT NULL,
+    kind       VARCHAR(50)  NOT NULL,
+    reference  VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+CREATE TABLE ledger_postings
+(
+    id         VARCHAR(100) NOT NULL,
+    journal_id VARCHAR(100) NOT NULL,
+    account    VARCHAR(100) NOT NULL,
+    wallet_id  VARCHAR(100) NULL,
+    amount     BIGINT       NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (journal_id) REFERENCES ledger_journals (id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets (id)
+) ENGINE = InnoDB;
+CREATE INDEX idx_ledger_postings_journal_id ON ledger_postings (journal_id);
+CREATE INDEX idx_ledger_postings_wallet_id ON ledger_postings (wallet_id);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS exchange_rates;
+
+-- hanya harga rupiah yang bisa dikembalikan ke format lama
+UPDATE products SET price = SUBSTRING(price, 5) WHERE price LIKE 'IDR %';
+
+ALTER TABLE wallets
+    DROP COLUMN balance_currency,
+    CHANGE balance_amount balance BIGINT NOT NULL;
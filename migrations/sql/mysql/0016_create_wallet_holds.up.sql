@@ -0,0 +1,19 @@
+-- dana yang dicadangkan (hold) dari saldo wallet, misalnya saat checkout sebelum pembayaran di-capture
+CREATE TABLE wallet_holds
+(
+    id              VARCHAR(100) NOT NULL,
+    wallet_id       VARCHAR(100) NOT NULL,
+    amount          BIGINT       NOT NULL,
+    currency        VARCHAR(3)   NOT NULL,
+    captured_amount BIGINT       NOT NULL DEFAULT 0,
+    status          VARCHAR(20)  NOT NULL,
+    reference       VARCHAR(100) NOT NULL,
+    expires_at      TIMESTAMP    NOT NULL,
+    resolved_at     TIMESTAMP    NULL,
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets (id)
+) ENGINE = InnoDB;
+CREATE INDEX idx_wallet_holds_wallet_id ON wallet_holds (wallet_id, status);
+CREATE INDEX idx_wallet_holds_expires_at ON wallet_holds (status, expires_at);
@@ -0,0 +1,6 @@
+DROP INDEX idx_user_logs_record ON user_logs;
+
+ALTER TABLE user_logs
+    DROP COLUMN changes,
+    DROP COLUMN record_id,
+    DROP COLUMN table_name;
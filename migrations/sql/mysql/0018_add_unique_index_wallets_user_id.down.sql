@@ -0,0 +1,4 @@
+-- index foreign key user_id yang dibuat otomatis sudah diganti oleh index unique, jadi dibuat lagi lebih dulu
+ALTER TABLE wallets
+    ADD INDEX user_id (user_id),
+    DROP INDEX idx_wallets_user_id;
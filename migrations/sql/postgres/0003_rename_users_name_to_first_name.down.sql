@@ -0,0 +1,8 @@
+ALTER TABLE users
+    DROP COLUMN middle_name,
+    DROP COLUMN last_name;
+
+ALTER TABLE users
+    ALTER COLUMN first_name TYPE VARCHAR(100),
+    ALTER COLUMN first_name SET NOT NULL;
+ALTER TABLE users RENAME COLUMN first_name TO name;
@@ -0,0 +1,10 @@
+-- Ubah nama kolom 'name' menjadi 'first_name'
+ALTER TABLE users RENAME COLUMN name TO first_name;
+ALTER TABLE users
+    ALTER COLUMN first_name TYPE VARCHAR(255),
+    ALTER COLUMN first_name DROP NOT NULL;
+
+-- Tambahkan kolom baru
+ALTER TABLE users
+    ADD COLUMN middle_name VARCHAR(100) NULL,
+    ADD COLUMN last_name   VARCHAR(100) NULL;
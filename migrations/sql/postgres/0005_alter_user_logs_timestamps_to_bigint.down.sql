@@ -0,0 +1,6 @@
+ALTER TABLE user_logs
+    ALTER COLUMN created_at TYPE TIMESTAMP USING to_timestamp(created_at / 1000.0),
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING to_timestamp(updated_at / 1000.0);
+ALTER TABLE user_logs
+    ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP,
+    ALTER COLUMN updated_at SET DEFAULT CURRENT_TIMESTAMP;
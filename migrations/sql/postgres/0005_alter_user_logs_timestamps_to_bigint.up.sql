@@ -0,0 +1,7 @@
+-- nilai lama diubah menjadi epoch milidetik, sama seperti autoCreateTime:milli pada model UserLog
+ALTER TABLE user_logs
+    ALTER COLUMN created_at DROP DEFAULT,
+    ALTER COLUMN updated_at DROP DEFAULT;
+ALTER TABLE user_logs
+    ALTER COLUMN created_at TYPE BIGINT USING (EXTRACT(EPOCH FROM created_at) * 1000)::BIGINT,
+    ALTER COLUMN updated_at TYPE BIGINT USING (EXTRACT(EPOCH FROM updated_at) * 1000)::BIGINT;
@@ -0,0 +1,10 @@
+CREATE TABLE addresses
+(
+    id         BIGSERIAL    NOT NULL,
+    user_id    VARCHAR(100) NOT NULL,
+    address    VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
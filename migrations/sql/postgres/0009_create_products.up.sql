@@ -0,0 +1,9 @@
+CREATE TABLE products
+(
+    id         VARCHAR(100) NOT NULL,
+    name       VARCHAR(100) NOT NULL,
+    price      VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
@@ -0,0 +1,11 @@
+-- tabel untuk model GuestBook (sebelumnya hanya dibuat lewat AutoMigrate di TestMigrator)
+CREATE TABLE guest_books
+(
+    id         BIGSERIAL    NOT NULL,
+    name       VARCHAR(100) NULL,
+    email      VARCHAR(100) NULL,
+    message    TEXT         NULL,
+    created_at TIMESTAMP    NULL,
+    updated_at TIMESTAMP    NULL,
+    PRIMARY KEY (id)
+);
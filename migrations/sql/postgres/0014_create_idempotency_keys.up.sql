@@ -0,0 +1,12 @@
+CREATE TABLE idempotency_keys
+(
+    scope           VARCHAR(100) NOT NULL,
+    idempotency_key VARCHAR(100) NOT NULL,
+    request_hash    VARCHAR(64)  NOT NULL,
+    status          VARCHAR(20)  NOT NULL,
+    response        TEXT         NULL,
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, idempotency_key)
+);
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys (created_at);
@@ -0,0 +1,16 @@
+-- saldo wallet disimpan sebagai money.Money (embedded): balance_amount + balance_currency
+ALTER TABLE wallets RENAME COLUMN balance TO balance_amount;
+ALTER TABLE wallets ADD COLUMN balance_currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+
+-- harga produk disimpan sebagai teks "<currency> <amount>", harga lama dianggap rupiah
+UPDATE products SET price = 'IDR ' || price WHERE price NOT LIKE '% %';
+
+-- kurs: 1 base_currency = rate quote_currency
+CREATE TABLE exchange_rates
+(
+    base_currency  VARCHAR(3)  NOT NULL,
+    quote_currency VARCHAR(3)  NOT NULL,
+    rate           VARCHAR(50) NOT NULL,
+    updated_at     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base_currency, quote_currency)
+);
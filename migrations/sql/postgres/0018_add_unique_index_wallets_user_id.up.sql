@@ -0,0 +1,17 @@
+-- satu user hanya punya satu wallet, dijaga database supaya tidak bisa dilewati oleh insert yang bersamaan.
+--
+-- Database lama bisa saja sudah punya user dengan lebih dari satu wallet. Karena itu jumlah user tersebut
+-- dicek dulu sebelum schema diubah: jika tidak 0, migration gagal dengan CHECK constraint wallets_one_per_user.
+-- Cari user tersebut dengan
+--   SELECT user_id, COUNT(*) FROM wallets GROUP BY user_id HAVING COUNT(*) > 1
+-- lalu gabungkan wallet yang berlebih (transfer saldonya ke satu wallet, lalu hapus) dan jalankan ulang migration.
+-- Saldo tidak digabung otomatis di sini, karena wallet bisa berbeda mata uang dan punya ledger masing-masing.
+CREATE TEMPORARY TABLE wallets_one_per_user_check (
+    duplicate_users INTEGER NOT NULL,
+    CONSTRAINT wallets_one_per_user CHECK (duplicate_users = 0)
+);
+INSERT INTO wallets_one_per_user_check (duplicate_users)
+SELECT COUNT(*) FROM (SELECT user_id FROM wallets GROUP BY user_id HAVING COUNT(*) > 1) duplicates;
+DROP TABLE wallets_one_per_user_check;
+
+CREATE UNIQUE INDEX idx_wallets_user_id ON wallets (user_id);
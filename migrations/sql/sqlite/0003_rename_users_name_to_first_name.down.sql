@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN last_name;
+ALTER TABLE users DROP COLUMN middle_name;
+ALTER TABLE users RENAME COLUMN first_name TO name;
@@ -0,0 +1,7 @@
+-- Ubah nama kolom 'name' menjadi 'first_name'
+-- (SQLite tidak bisa mengubah tipe kolom, tetapi VARCHAR tidak membatasi panjang di SQLite)
+ALTER TABLE users RENAME COLUMN name TO first_name;
+
+-- Tambahkan kolom baru
+ALTER TABLE users ADD COLUMN middle_name VARCHAR(100) NULL;
+ALTER TABLE users ADD COLUMN last_name VARCHAR(100) NULL;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS user_logs
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    VARCHAR(100) NOT NULL,
+    action     VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
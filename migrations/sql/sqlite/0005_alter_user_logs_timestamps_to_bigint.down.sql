@@ -0,0 +1,15 @@
+CREATE TABLE user_logs_old
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    VARCHAR(100) NOT NULL,
+    action     VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO user_logs_old (id, user_id, action, created_at, updated_at)
+SELECT id, user_id, action,
+       datetime(created_at / 1000, 'unixepoch'),
+       datetime(updated_at / 1000, 'unixepoch')
+FROM user_logs;
+DROP TABLE user_logs;
+ALTER TABLE user_logs_old RENAME TO user_logs;
@@ -0,0 +1,17 @@
+-- SQLite tidak mendukung ALTER COLUMN, jadi tabel dibuat ulang.
+-- nilai lama diubah menjadi epoch milidetik, sama seperti autoCreateTime:milli pada model UserLog
+CREATE TABLE user_logs_new
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    VARCHAR(100) NOT NULL,
+    action     VARCHAR(100) NOT NULL,
+    created_at BIGINT       NOT NULL,
+    updated_at BIGINT       NOT NULL
+);
+INSERT INTO user_logs_new (id, user_id, action, created_at, updated_at)
+SELECT id, user_id, action,
+       CAST(strftime('%s', created_at) AS INTEGER) * 1000,
+       CAST(strftime('%s', updated_at) AS INTEGER) * 1000
+FROM user_logs;
+DROP TABLE user_logs;
+ALTER TABLE user_logs_new RENAME TO user_logs;
@@ -0,0 +1,10 @@
+CREATE TABLE todos
+(
+    id          INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    user_id     VARCHAR(100) NOT NULL,
+    title       VARCHAR(100) NOT NULL,
+    description TEXT         NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP    NULL
+);
@@ -0,0 +1,9 @@
+CREATE TABLE addresses
+(
+    id         INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    user_id    VARCHAR(100) NOT NULL,
+    address    VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
@@ -0,0 +1,10 @@
+-- tabel untuk model GuestBook (sebelumnya hanya dibuat lewat AutoMigrate di TestMigrator)
+CREATE TABLE guest_books
+(
+    id         INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(100) NULL,
+    email      VARCHAR(100) NULL,
+    message    TEXT         NULL,
+    created_at TIMESTAMP    NULL,
+    updated_at TIMESTAMP    NULL
+);
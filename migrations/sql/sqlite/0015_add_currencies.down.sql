@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS exchange_rates;
+
+-- hanya harga rupiah yang bisa dikembalikan ke format lama
+UPDATE products SET price = SUBSTR(price, 5) WHERE price LIKE 'IDR %';
+
+ALTER TABLE wallets DROP COLUMN balance_currency;
+ALTER TABLE wallets RENAME COLUMN balance_amount TO balance;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_user_logs_record;
+
+ALTER TABLE user_logs DROP COLUMN changes;
+ALTER TABLE user_logs DROP COLUMN record_id;
+ALTER TABLE user_logs DROP COLUMN table_name;
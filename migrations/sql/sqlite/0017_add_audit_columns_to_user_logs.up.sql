@@ -0,0 +1,6 @@
+-- audit log otomatis (lihat audit.go): tabel dan primary key yang berubah, beserta perubahan kolomnya (JSON)
+ALTER TABLE user_logs ADD COLUMN table_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE user_logs ADD COLUMN record_id VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE user_logs ADD COLUMN changes TEXT NULL;
+
+CREATE INDEX idx_user_logs_record ON user_logs (table_name, record_id);
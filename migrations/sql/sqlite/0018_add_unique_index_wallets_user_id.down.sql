@@ -0,0 +1 @@
+DROP INDEX idx_wallets_user_id;
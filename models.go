@@ -0,0 +1,34 @@
+package learn_golang_gorm
+
+import (
+	"learn-golang-gorm/idempotency"
+	"learn-golang-gorm/money"
+
+	"gorm.io/gorm"
+)
+
+// Models berisi semua model pada project ini.
+// Urutannya mengikuti foreign key: tabel induk (users) dibuat lebih dulu.
+func Models() []interface{} {
+	return []interface{}{
+		&User{},
+		&Wallet{},
+		&WalletTransaction{},
+		&WalletHold{},
+		&LedgerJournal{},
+		&LedgerPosting{},
+		&Address{},
+		&Product{}, // tabel penghubung user_like_product ikut dibuat dari relasi many2many
+		&Todo{},
+		&GuestBook{},
+		&UserLog{},
+		&idempotency.Record{},
+		&money.ExchangeRate{},
+	}
+}
+
+// AutoMigrate membuat/menyesuaikan tabel semua model untuk dialect apapun (mysql, postgres, sqlite).
+// Cocok untuk database sementara (misalnya SQLite in-memory saat testing di CI).
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(Models()...)
+}
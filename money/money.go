@@ -0,0 +1,321 @@
+// Package money berisi tipe Money: jumlah uang dalam satuan terkecil (minor unit, misalnya sen)
+// beserta kode mata uang ISO 4217.
+//
+// Operasi aritmatika menolak mata uang yang berbeda (ErrCurrencyMismatch), jadi IDR tidak pernah
+// tidak sengaja dijumlahkan dengan USD. Konversi antar mata uang harus lewat Convert dan RateProvider.
+//
+//	price := money.New(1500000, money.IDR)        // IDR 1500000
+//	tip, _ := money.Parse("USD 12.50")            // Amount 1250, Currency USD
+//	_, err := price.Add(tip)                      // ErrCurrencyMismatch
+//
+// Di database Money bisa disimpan dalam satu kolom teks ("IDR 1500000", lewat driver.Valuer / sql.Scanner
+// atau serializer:money), atau dua kolom (amount dan currency) dengan tag gorm:"embedded".
+package money
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrUnknownCurrency  = errors.New("money: unknown currency")
+	ErrInvalidFormat    = errors.New("money: invalid format")
+	ErrOverflow         = errors.New("money: amount overflows int64")
+)
+
+// Currency adalah kode mata uang ISO 4217 (3 huruf kapital)
+type Currency string
+
+const (
+	IDR Currency = "IDR"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	SGD Currency = "SGD"
+	MYR Currency = "MYR"
+	JPY Currency = "JPY"
+	GBP Currency = "GBP"
+	AUD Currency = "AUD"
+	CNY Currency = "CNY"
+	KWD Currency = "KWD"
+)
+
+var (
+	mu sync.RWMutex
+	// jumlah digit di belakang koma (minor unit) setiap mata uang.
+	// IDR di ISO 4217 punya 2 digit, tetapi sen sudah tidak dipakai, jadi di sini 0
+	// (saldo wallet dan harga produk yang sudah ada tetap bernilai rupiah penuh).
+	digits = map[Currency]int{
+		IDR: 0, USD: 2, EUR: 2, SGD: 2, MYR: 2, JPY: 0, GBP: 2, AUD: 2, CNY: 2, KWD: 3,
+	}
+)
+
+// Register menambah (atau mengganti) mata uang beserta jumlah digit minor unit-nya
+func Register(currency Currency, minorDigits int) error {
+	if !validCode(string(currency)) || minorDigits < 0 || minorDigits > 4 {
+		return fmt.Errorf("%w: %q with %d digits", ErrUnknownCurrency, currency, minorDigits)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	digits[currency] = minorDigits
+	return nil
+}
+
+// ParseCurrency memeriksa kode mata uang, huruf kecil diterima ("idr" menjadi IDR)
+func ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if _, err := currency.Digits(); err != nil {
+		return "", err
+	}
+	return currency, nil
+}
+
+// Digits mengembalikan jumlah digit di belakang koma
+func (c Currency) Digits() (int, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	d, ok := digits[c]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, string(c))
+	}
+	return d, nil
+}
+
+func validCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Money adalah jumlah uang dalam minor unit. Zero value (tanpa Currency) dianggap "belum diisi".
+type Money struct {
+	Amount   int64    `gorm:"column:amount" json:"amount"`
+	Currency Currency `gorm:"column:currency;size:3" json:"currency"`
+}
+
+// New membuat Money dari minor unit, misalnya New(1250, USD) adalah USD 12.50
+func New(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse membaca format "IDR 1500000" atau "USD 12.50" (kebalikan dari String)
+func Parse(s string) (Money, error) {
+	code, number, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q, expected \"<currency> <amount>\"", ErrInvalidFormat, s)
+	}
+	currency, err := ParseCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	amount, err := parseAmount(strings.TrimSpace(number), currency)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q: %v", ErrInvalidFormat, s, err)
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// parseAmount mengubah angka desimal ("12.5") menjadi minor unit (1250 untuk USD)
+func parseAmount(number string, currency Currency) (int64, error) {
+	d, _ := currency.Digits()
+	negative := strings.HasPrefix(number, "-")
+	whole, fraction, _ := strings.Cut(strings.TrimPrefix(number, "-"), ".")
+	if whole == "" || len(fraction) > d || strings.ContainsAny(whole+fraction, "+-") {
+		return 0, fmt.Errorf("%s allows %d decimal digits", currency, d)
+	}
+	amount, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", d-len(fraction)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// String menulis Money dalam satuan mayor, misalnya "USD 12.50" atau "IDR 1500000"
+func (m Money) String() string {
+	return string(m.Currency) + " " + m.Decimal()
+}
+
+// Decimal menulis Amount dalam satuan mayor tanpa kode mata uang, misalnya "12.50" atau "-1500000".
+// Mata uang yang tidak dikenal ditulis dalam minor unit.
+func (m Money) Decimal() string {
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+	}
+	text := strconv.FormatUint(absolute(m.Amount), 10)
+	d, err := m.Currency.Digits()
+	if err != nil || d == 0 {
+		return sign + text
+	}
+	if len(text) <= d {
+		text = strings.Repeat("0", d-len(text)+1) + text
+	}
+	return sign + text[:len(text)-d] + "." + text[len(text)-d:]
+}
+
+func absolute(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1 // aman untuk math.MinInt64
+	}
+	return uint64(n)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// SameCurrency mengembalikan ErrCurrencyMismatch jika mata uangnya berbeda
+func (m Money) SameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.SameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	sum := m.Amount + other.Amount
+	if (other.Amount > 0 && sum < m.Amount) || (other.Amount < 0 && sum > m.Amount) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrOverflow, m, other)
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrOverflow, m, other)
+	}
+	return m.Add(other.Neg())
+}
+
+// Neg membalik tanda Amount
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Mul mengalikan Amount dengan bilangan bulat (misalnya jumlah barang)
+func (m Money) Mul(n int64) (Money, error) {
+	if n != 0 && (m.Amount*n/n != m.Amount || (m.Amount == math.MinInt64 && n == -1)) {
+		return Money{}, fmt.Errorf("%w: %s * %d", ErrOverflow, m, n)
+	}
+	return Money{Amount: m.Amount * n, Currency: m.Currency}, nil
+}
+
+// Cmp mengembalikan -1, 0 atau 1 seperti strings.Compare, hanya untuk mata uang yang sama
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.SameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// ===== database =====
+
+// Value menyimpan Money sebagai teks, misalnya "IDR 1500000"
+func (m Money) Value() (driver.Value, error) {
+	if _, err := m.Currency.Digits(); err != nil {
+		return nil, err
+	}
+	return m.String(), nil
+}
+
+// GormDataType membuat GORM memperlakukan Money sebagai kolom teks. Tanpa ini GORM memakai tipe field
+// pertamanya (int64) dan Money tidak bisa dipakai sebagai field embedded.
+func (Money) GormDataType() string {
+	return "string"
+}
+
+// Scan membaca kolom teks yang ditulis oleh Value
+func (m *Money) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T into money.Money", ErrInvalidFormat, value)
+	}
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Serializer adalah serializer GORM untuk field Money (atau *Money) yang disimpan di satu kolom teks.
+// Berbeda dengan Value / Scan, NULL dibaca sebagai zero value dan *Money nil disimpan sebagai NULL.
+//
+//	Price money.Money `gorm:"column:price;serializer:money"`
+type Serializer struct{}
+
+func init() {
+	schema.RegisterSerializer("money", Serializer{})
+}
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var m Money
+	if dbValue != nil {
+		if err := m.Scan(dbValue); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+
+	value := reflect.ValueOf(m)
+	if field.FieldType.Kind() == reflect.Ptr {
+		value = reflect.ValueOf(&m)
+		if dbValue == nil {
+			value = reflect.Zero(field.FieldType)
+		}
+	}
+	field.ReflectValueOf(ctx, dst).Set(value)
+	return nil
+}
+
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	switch v := fieldValue.(type) {
+	case Money:
+		return v.Value()
+	case *Money:
+		if v == nil {
+			return nil, nil
+		}
+		return v.Value()
+	}
+	return nil, fmt.Errorf("money: serializer cannot be used for field %s of type %T", field.Name, fieldValue)
+}
@@ -0,0 +1,202 @@
+package money
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"learn-golang-gorm/internal/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestParseAndString(t *testing.T) {
+	cases := []struct {
+		text   string
+		amount int64
+		want   string // hasil String, kosong berarti sama dengan text
+	}{
+		{"IDR 1500000", 1500000, ""},
+		{"USD 12.50", 1250, ""},
+		{"USD 12.5", 1250, "USD 12.50"},
+		{"usd 12", 1200, "USD 12.00"},
+		{"USD 0.05", 5, ""},
+		{"USD -0.05", -5, ""},
+		{"KWD 1.005", 1005, ""},
+		{"JPY 500", 500, ""},
+	}
+	for _, c := range cases {
+		m, err := Parse(c.text)
+		assert.Nil(t, err, c.text)
+		assert.Equal(t, c.amount, m.Amount, c.text)
+		want := c.want
+		if want == "" {
+			want = c.text
+		}
+		assert.Equal(t, want, m.String())
+	}
+
+	for _, text := range []string{"1500000", "IDR", "IDR 10.5", "USD 1.234", "XXX 1", "USD abc", "USD --1", "USD .5"} {
+		_, err := Parse(text)
+		assert.NotNil(t, err, text)
+	}
+	_, err := Parse("XYZ 1")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+
+	assert.Equal(t, "USD -92233720368547758.08", New(math.MinInt64, USD).String())
+	assert.Equal(t, "-0.05", New(-5, USD).Decimal())
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := New(1000, IDR), New(250, IDR)
+
+	sum, err := a.Add(b)
+	assert.Nil(t, err)
+	assert.Equal(t, New(1250, IDR), sum)
+
+	diff, err := b.Sub(a)
+	assert.Nil(t, err)
+	assert.True(t, diff.IsNegative())
+	assert.Equal(t, New(750, IDR), diff.Neg())
+
+	product, err := b.Mul(3)
+	assert.Nil(t, err)
+	assert.Equal(t, New(750, IDR), product)
+
+	cmp, err := a.Cmp(b)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cmp)
+
+	// mata uang berbeda selalu ditolak
+	_, err = a.Add(New(1, USD))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = a.Sub(New(1, USD))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = a.Cmp(New(1, USD))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = New(math.MaxInt64, IDR).Add(New(1, IDR))
+	assert.ErrorIs(t, err, ErrOverflow)
+	_, err = New(0, IDR).Sub(New(math.MinInt64, IDR))
+	assert.ErrorIs(t, err, ErrOverflow)
+	_, err = New(math.MaxInt64/2+1, IDR).Mul(2)
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestRegister(t *testing.T) {
+	assert.NotNil(t, Register("btc", 8))
+	assert.Nil(t, Register("THB", 2))
+
+	m, err := Parse("THB 35.25")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3525), m.Amount)
+}
+
+type item struct {
+	ID       int
+	Price    Money  `gorm:"serializer:money;size:100"`
+	Discount *Money `gorm:"serializer:money;size:100"`
+	Total    Money  `gorm:"embedded;embeddedPrefix:total_"`
+}
+
+func openSQLite(t *testing.T) *gorm.DB {
+	return testdb.Memory(t, &item{}, &ExchangeRate{})
+}
+
+func TestDatabase(t *testing.T) {
+	db := openSQLite(t)
+
+	discount := New(500, USD)
+	assert.Nil(t, db.Create(&item{ID: 1, Price: New(1250, USD), Discount: &discount, Total: New(750, USD)}).Error)
+	assert.Nil(t, db.Create(&item{ID: 2, Price: New(1000, IDR), Total: New(1000, IDR)}).Error)
+
+	var first, second item
+	assert.Nil(t, db.Take(&first, 1).Error)
+	assert.Equal(t, New(1250, USD), first.Price)
+	assert.Equal(t, &discount, first.Discount)
+	assert.Equal(t, New(750, USD), first.Total)
+	assert.Nil(t, db.Take(&second, 2).Error)
+	assert.Nil(t, second.Discount)
+
+	// satu kolom teks, atau dua kolom untuk field embedded
+	var row struct {
+		Price         string
+		TotalAmount   int64
+		TotalCurrency string
+	}
+	assert.Nil(t, db.Model(&item{}).Where("id = ?", 1).Take(&row).Error)
+	assert.Equal(t, "USD 12.50", row.Price)
+	assert.Equal(t, int64(750), row.TotalAmount)
+	assert.Equal(t, "USD", row.TotalCurrency)
+
+	// Money juga bisa dipakai langsung dengan database/sql
+	var price Money
+	assert.Nil(t, db.Raw("SELECT price FROM items WHERE id = ?", 2).Row().Scan(&price))
+	assert.Equal(t, New(1000, IDR), price)
+	var count int64
+	assert.Nil(t, db.Model(&item{}).Where("price = ?", New(1000, IDR)).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// Money tanpa mata uang tidak bisa disimpan
+	assert.ErrorIs(t, db.Create(&item{ID: 3, Price: Money{Amount: 1}}).Error, ErrUnknownCurrency)
+}
+
+func TestConvert(t *testing.T) {
+	ctx := context.Background()
+	rates := NewStaticRates()
+	assert.Nil(t, rates.Set(USD, IDR, "16250.5"))
+	assert.Nil(t, rates.Set(USD, JPY, "150.255"))
+	assert.NotNil(t, rates.Set(USD, EUR, "-1"))
+
+	converted, err := Convert(ctx, rates, New(1250, USD), IDR)
+	assert.Nil(t, err)
+	assert.Equal(t, New(203131, IDR), converted) // 12.50 * 16250.5 = 203131.25
+
+	// kurs kebalikan: 100000 / 16250.5 = 6.1536... USD
+	converted, err = Convert(ctx, rates, New(100000, IDR), USD)
+	assert.Nil(t, err)
+	assert.Equal(t, New(615, USD), converted)
+
+	// dibulatkan ke minor unit terdekat: -0.10 * 150.255 = -15.0255 -> -15
+	converted, err = Convert(ctx, rates, New(-10, USD), JPY)
+	assert.Nil(t, err)
+	assert.Equal(t, New(-15, JPY), converted)
+	assert.Nil(t, rates.Set(JPY, KWD, "0.0025"))
+	// setengah dibulatkan menjauhi nol: 0.0025 KWD = 2.5 fils -> 3
+	converted, err = Convert(ctx, rates, New(1, JPY), KWD)
+	assert.Nil(t, err)
+	assert.Equal(t, New(3, KWD), converted)
+
+	same, err := Convert(ctx, rates, New(10, EUR), EUR)
+	assert.Nil(t, err)
+	assert.Equal(t, New(10, EUR), same)
+
+	_, err = Convert(ctx, rates, New(10, EUR), IDR)
+	assert.ErrorIs(t, err, ErrRateNotFound)
+	_, err = Convert(ctx, rates, New(math.MaxInt64, USD), IDR)
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestTableRates(t *testing.T) {
+	ctx := context.Background()
+	rates := NewTableRates(openSQLite(t))
+
+	_, err := rates.Rate(ctx, USD, IDR)
+	assert.ErrorIs(t, err, ErrRateNotFound)
+
+	assert.Nil(t, rates.Set(ctx, USD, IDR, "16000"))
+	assert.Nil(t, rates.Set(ctx, USD, IDR, "16250")) // update kurs yang sudah ada
+	assert.NotNil(t, rates.Set(ctx, USD, IDR, "abc"))
+
+	rate, err := rates.Rate(ctx, USD, IDR)
+	assert.Nil(t, err)
+	assert.Equal(t, "16250", rate.RatString())
+	rate, err = rates.Rate(ctx, IDR, USD)
+	assert.Nil(t, err)
+	assert.Equal(t, "1/16250", rate.RatString())
+
+	converted, err := Convert(ctx, rates, New(2000, USD), IDR)
+	assert.Nil(t, err)
+	assert.Equal(t, New(325000, IDR), converted)
+}
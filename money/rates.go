@@ -0,0 +1,185 @@
+package money
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrRateNotFound = errors.New("money: exchange rate not found")
+
+// RateProvider memberikan kurs dari satu mata uang ke mata uang lain:
+// 1 satuan mayor from = rate satuan mayor to (misalnya USD -> IDR = 16250).
+// Implementasi harus aman dipanggil dari banyak goroutine.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to Currency) (*big.Rat, error)
+}
+
+// Convert mengubah m ke mata uang to memakai kurs dari rates.
+// Hasilnya dibulatkan ke minor unit terdekat (setengah dibulatkan menjauhi nol).
+func Convert(ctx context.Context, rates RateProvider, m Money, to Currency) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+	fromDigits, err := m.Currency.Digits()
+	if err != nil {
+		return Money{}, err
+	}
+	toDigits, err := to.Digits()
+	if err != nil {
+		return Money{}, err
+	}
+	rate, err := rates.Rate(ctx, m.Currency, to)
+	if err != nil {
+		return Money{}, err
+	}
+
+	// minor(to) = minor(from) * rate * 10^(toDigits - fromDigits)
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), rate)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toDigits-fromDigits))), nil))
+	if toDigits > fromDigits {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+
+	amount := roundHalfAwayFromZero(value)
+	if !amount.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s in %s", ErrOverflow, m, to)
+	}
+	return Money{Amount: amount.Int64(), Currency: to}, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func roundHalfAwayFromZero(r *big.Rat) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	// |remainder| * 2 >= denominator berarti pecahannya >= 0.5
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		if r.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+// ParseRate membaca kurs dalam bentuk desimal ("16250" atau "0.0000615"), harus lebih dari 0
+func ParseRate(rate string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return nil, fmt.Errorf("money: invalid exchange rate %q", rate)
+	}
+	return r, nil
+}
+
+// ===== kurs tetap (di memory) =====
+
+// StaticRates menyimpan kurs di memory, cocok untuk testing atau kurs yang jarang berubah.
+// Jika hanya kurs A -> B yang diisi, kurs B -> A dihitung dari kebalikannya.
+type StaticRates struct {
+	mu    sync.RWMutex
+	rates map[[2]Currency]*big.Rat
+}
+
+func NewStaticRates() *StaticRates {
+	return &StaticRates{rates: map[[2]Currency]*big.Rat{}}
+}
+
+// Set mengisi kurs: 1 base = rate quote
+func (s *StaticRates) Set(base, quote Currency, rate string) error {
+	r, err := ParseRate(rate)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rates[[2]Currency{base, quote}] = r
+	return nil
+}
+
+func (s *StaticRates) Rate(ctx context.Context, from, to Currency) (*big.Rat, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if rate, ok := s.rates[[2]Currency{from, to}]; ok {
+		return new(big.Rat).Set(rate), nil
+	}
+	if rate, ok := s.rates[[2]Currency{to, from}]; ok {
+		return new(big.Rat).Inv(rate), nil
+	}
+	return nil, fmt.Errorf("%w: %s -> %s", ErrRateNotFound, from, to)
+}
+
+// ===== kurs dari tabel exchange_rates =====
+
+// ExchangeRate adalah satu baris tabel exchange_rates: 1 Base = Rate Quote.
+// Rate disimpan sebagai teks desimal supaya tidak ada pembulatan float.
+type ExchangeRate struct {
+	Base      Currency  `gorm:"primaryKey;column:base_currency;size:3"`
+	Quote     Currency  `gorm:"primaryKey;column:quote_currency;size:3"`
+	Rate      string    `gorm:"column:rate;size:50"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (r *ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// TableRates membaca kurs dari tabel exchange_rates. Seperti StaticRates,
+// kurs kebalikan dipakai jika pasangan yang diminta tidak ada.
+type TableRates struct {
+	db *gorm.DB
+}
+
+func NewTableRates(db *gorm.DB) *TableRates {
+	return &TableRates{db: db}
+}
+
+// Set menyimpan (insert atau update) kurs: 1 base = rate quote
+func (t *TableRates) Set(ctx context.Context, base, quote Currency, rate string) error {
+	if _, err := ParseRate(rate); err != nil {
+		return err
+	}
+	return t.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base_currency"}, {Name: "quote_currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(&ExchangeRate{Base: base, Quote: quote, Rate: rate}).Error
+}
+
+func (t *TableRates) Rate(ctx context.Context, from, to Currency) (*big.Rat, error) {
+	var rows []ExchangeRate
+	err := t.db.WithContext(ctx).
+		Where("(base_currency = ? AND quote_currency = ?) OR (base_currency = ? AND quote_currency = ?)", from, to, to, from).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var inverse *ExchangeRate
+	for i, row := range rows {
+		if row.Base == from {
+			return ParseRate(row.Rate)
+		}
+		inverse = &rows[i]
+	}
+	if inverse == nil {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrRateNotFound, from, to)
+	}
+	rate, err := ParseRate(inverse.Rate)
+	if err != nil {
+		return nil, err
+	}
+	return rate.Inv(rate), nil
+}
@@ -0,0 +1,282 @@
+// Package pagination membagi hasil query GORM menjadi halaman, sebagai pengganti Order / Limit / Offset
+// yang ditulis manual (lihat TestOrderLimitOffset). Query-nya disiapkan seperti biasa, termasuk Where,
+// Joins dan Preload:
+//
+//	query := db.Model(&User{}).Preload("Addresses").Joins("Wallet").Where("Wallet.balance_amount > ?", 0)
+//
+//	// offset: halaman ke-2, 20 per halaman, beserta jumlah total baris
+//	page, err := pagination.Offset[User](ctx, query, 2, 20)
+//
+//	// keyset / cursor: stabil dan tetap cepat untuk tabel besar
+//	page, err := pagination.Cursor[User](ctx, query, pagination.CursorRequest{
+//		Sort:  []pagination.Order{{Column: "created_at", Desc: true}},
+//		Size:  20,
+//		After: page.Next, // cursor dari halaman sebelumnya, kosong untuk halaman pertama
+//	})
+//
+// Cursor adalah string opaque (base64) berisi nilai kolom sort dari baris terakhir / pertama halaman.
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidCursor = errors.New("pagination: invalid cursor")
+
+// Ukuran halaman jika Size kosong, dan batas atasnya
+const (
+	DefaultSize = 20
+	MaxSize     = 100
+)
+
+// Page adalah satu halaman hasil Offset atau Cursor
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// HasMore bernilai true jika masih ada baris setelah halaman ini (untuk Cursor dengan Before:
+	// masih ada baris sebelum halaman ini)
+	HasMore bool `json:"has_more"`
+	Size    int  `json:"size"`
+
+	// hanya diisi oleh Cursor, kosong jika tidak ada halaman berikutnya / sebelumnya
+	Next string `json:"next_cursor,omitempty"`
+	Prev string `json:"prev_cursor,omitempty"`
+
+	// hanya diisi oleh Offset
+	Number     int   `json:"page,omitempty"`
+	Total      int64 `json:"total,omitempty"`
+	TotalPages int   `json:"total_pages,omitempty"`
+}
+
+func normalizeSize(size int) int {
+	switch {
+	case size <= 0:
+		return DefaultSize
+	case size > MaxSize:
+		return MaxSize
+	}
+	return size
+}
+
+// Offset mengambil halaman ke-number (mulai dari 1) beserta jumlah total baris (satu query Count
+// dengan kondisi dan join yang sama, seperti TestCount). Jika query belum punya Order, baris diurutkan
+// berdasarkan primary key supaya isi setiap halaman stabil.
+func Offset[T any](ctx context.Context, query *gorm.DB, number, size int) (*Page[T], error) {
+	size = normalizeSize(size)
+	if number < 1 {
+		number = 1
+	}
+	query = query.WithContext(ctx).Model(new(T))
+
+	page := &Page[T]{Items: []T{}, Size: size, Number: number}
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+	page.TotalPages = int((page.Total + int64(size) - 1) / int64(size))
+	page.HasMore = number < page.TotalPages
+	if int64(number-1)*int64(size) >= page.Total {
+		return page, nil
+	}
+
+	if err := query.Scopes(defaultOrder).Limit(size).Offset((number - 1) * size).Find(&page.Items).Error; err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// defaultOrder mengurutkan berdasarkan primary key jika query belum punya Order. Dipasang sebagai scope
+// supaya dijalankan setelah scope milik pemanggil (misalnya filter.Filter.Scope yang menambahkan Order).
+func defaultOrder(db *gorm.DB) *gorm.DB {
+	if _, ok := db.Statement.Clauses["ORDER BY"]; ok {
+		return db
+	}
+	return db.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey}})
+}
+
+// Order adalah satu kolom sort milik tabel T (bukan tabel hasil join)
+type Order struct {
+	Column string // nama kolom, misalnya "created_at"
+	Desc   bool
+}
+
+type CursorRequest struct {
+	// Sort kosong berarti urut primary key. Primary key selalu ditambahkan di akhir (jika belum ada)
+	// supaya urutannya unik.
+	Sort   []Order
+	Size   int
+	After  string // Page.Next dari halaman sebelumnya
+	Before string // Page.Prev, untuk kembali ke halaman sebelumnya
+}
+
+// cursor adalah isi Page.Next / Page.Prev sebelum di-encode
+type cursor struct {
+	Columns []string          `json:"c"`
+	Values  []json.RawMessage `json:"v"`
+}
+
+// Cursor mengambil satu halaman dengan keyset pagination: kondisi WHERE dari nilai sort baris terakhir
+// halaman sebelumnya, bukan OFFSET, jadi tidak melambat di halaman yang jauh dan tidak melompati / mengulang
+// baris saat ada baris baru. Kondisi dan Order yang sudah ada di query tetap dipakai (Order milik query
+// didahulukan, sebaiknya tidak dipakai bersamaan dengan Sort).
+func Cursor[T any](ctx context.Context, query *gorm.DB, req CursorRequest) (*Page[T], error) {
+	if req.After != "" && req.Before != "" {
+		return nil, fmt.Errorf("%w: After and Before cannot be used together", ErrInvalidCursor)
+	}
+	size := normalizeSize(req.Size)
+	query = query.WithContext(ctx).Model(new(T))
+
+	stmt := &gorm.Statement{DB: query}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	fields, orders, err := sortFields(stmt.Schema, req.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	backward := req.Before != ""
+	if token := req.After + req.Before; token != "" {
+		values, err := decodeCursor(token, fields)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(keysetCondition(fields, orders, values, backward))
+	}
+	for i, field := range fields {
+		query = query.Order(clause.OrderByColumn{
+			Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+			Desc:   orders[i].Desc != backward,
+		})
+	}
+
+	items := []T{}
+	if err := query.Limit(size + 1).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	page := &Page[T]{Items: items, Size: size, HasMore: len(items) > size}
+	if page.HasMore {
+		page.Items = items[:size]
+	}
+	if backward {
+		slices.Reverse(page.Items)
+	}
+	if len(page.Items) == 0 {
+		return page, nil
+	}
+
+	// halaman sebelumnya ada jika kita datang dari cursor After, atau masih ada baris saat mundur
+	hasNext, hasPrev := page.HasMore, req.After != ""
+	if backward {
+		hasNext, hasPrev = true, page.HasMore
+	}
+	if hasNext {
+		if page.Next, err = encodeCursor(ctx, fields, page.Items[len(page.Items)-1]); err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		if page.Prev, err = encodeCursor(ctx, fields, page.Items[0]); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// sortFields mencari field schema untuk setiap kolom sort dan menambahkan primary key di akhir
+func sortFields(s *schema.Schema, sort []Order) ([]*schema.Field, []Order, error) {
+	if s.PrioritizedPrimaryField == nil {
+		return nil, nil, fmt.Errorf("pagination: %s needs a single primary key for cursor pagination", s.Name)
+	}
+	orders := slices.Clone(sort)
+	fields := make([]*schema.Field, 0, len(orders)+1)
+	hasPrimaryKey := false
+	for _, order := range orders {
+		field := s.LookUpField(order.Column)
+		if field == nil || field.DBName == "" {
+			return nil, nil, fmt.Errorf("pagination: unknown sort column %q in %s", order.Column, s.Name)
+		}
+		fields = append(fields, field)
+		hasPrimaryKey = hasPrimaryKey || field == s.PrioritizedPrimaryField
+	}
+	if !hasPrimaryKey {
+		desc := len(orders) > 0 && orders[len(orders)-1].Desc
+		fields = append(fields, s.PrioritizedPrimaryField)
+		orders = append(orders, Order{Column: s.PrioritizedPrimaryField.DBName, Desc: desc})
+	}
+	return fields, orders, nil
+}
+
+// keysetCondition membuat kondisi "setelah values" untuk sort beberapa kolom dengan arah campuran:
+// (a > ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?)
+func keysetCondition(fields []*schema.Field, orders []Order, values []interface{}, backward bool) clause.Expression {
+	alternatives := make([]clause.Expression, 0, len(fields))
+	for i, field := range fields {
+		conditions := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, clause.Eq{Column: column(fields[j]), Value: values[j]})
+		}
+		if orders[i].Desc != backward {
+			conditions = append(conditions, clause.Lt{Column: column(field), Value: values[i]})
+		} else {
+			conditions = append(conditions, clause.Gt{Column: column(field), Value: values[i]})
+		}
+		alternatives = append(alternatives, clause.And(conditions...))
+	}
+	return clause.Or(alternatives...)
+}
+
+func column(field *schema.Field) clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+}
+
+func encodeCursor[T any](ctx context.Context, fields []*schema.Field, item T) (string, error) {
+	c := cursor{Columns: make([]string, len(fields)), Values: make([]json.RawMessage, len(fields))}
+	value := reflect.Indirect(reflect.ValueOf(&item))
+	for i, field := range fields {
+		fieldValue, _ := field.ValueOf(ctx, value)
+		raw, err := json.Marshal(fieldValue)
+		if err != nil {
+			return "", fmt.Errorf("pagination: encode %s: %w", field.DBName, err)
+		}
+		c.Columns[i], c.Values[i] = field.DBName, raw
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor mengembalikan nilai cursor dengan tipe field-nya (misalnya time.Time, bukan string),
+// dan ErrInvalidCursor jika cursor dibuat untuk sort yang berbeda
+func decodeCursor(token string, fields []*schema.Field) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || len(c.Columns) != len(fields) || len(c.Values) != len(fields) {
+		return nil, ErrInvalidCursor
+	}
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		if c.Columns[i] != field.DBName {
+			return nil, fmt.Errorf("%w: sorted by %v", ErrInvalidCursor, c.Columns)
+		}
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(c.Values[i], value.Interface()); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidCursor, field.DBName, err)
+		}
+		values[i] = value.Elem().Interface()
+	}
+	return values, nil
+}
@@ -0,0 +1,199 @@
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"learn-golang-gorm/internal/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type author struct {
+	ID   int `gorm:"primaryKey"`
+	Name string
+}
+
+type post struct {
+	ID        int `gorm:"primaryKey"`
+	AuthorID  int
+	Author    author
+	Score     int
+	CreatedAt time.Time
+	Comments  []comment
+}
+
+type comment struct {
+	ID     int `gorm:"primaryKey"`
+	PostID int
+	Body   string
+}
+
+// 25 post: score 0 - 4 (banyak yang sama), created_at berurutan per menit, setiap post punya 2 comment
+func openSQLite(t *testing.T) *gorm.DB {
+	db := testdb.Memory(t, &author{}, &post{}, &comment{})
+
+	assert.Nil(t, db.Create(&[]author{{ID: 1, Name: "Budi"}, {ID: 2, Name: "Joko"}}).Error)
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	for i := 1; i <= 25; i++ {
+		assert.Nil(t, db.Create(&post{
+			ID:        i,
+			AuthorID:  i%2 + 1,
+			Score:     i % 5,
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+			Comments:  []comment{{Body: "a"}, {Body: "b"}},
+		}).Error)
+	}
+	return db
+}
+
+func postIDs(posts []post) []int {
+	ids := make([]int, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	return ids
+}
+
+func TestOffset(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	page, err := Offset[post](ctx, db, 3, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{21, 22, 23, 24, 25}, postIDs(page.Items)) // urut primary key
+	assert.Equal(t, int64(25), page.Total)
+	assert.Equal(t, 3, page.TotalPages)
+	assert.False(t, page.HasMore)
+
+	// kondisi, join dan preload ikut dipakai untuk Count maupun Find
+	query := db.Preload("Comments").Joins("Author").Where("Author.name = ?", "Budi").Order("score DESC, posts.id")
+	page, err = Offset[post](ctx, query, 1, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(12), page.Total)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, []int{4, 14, 24, 8, 18}, postIDs(page.Items))
+	assert.Equal(t, "Budi", page.Items[0].Author.Name)
+	assert.Equal(t, 2, len(page.Items[0].Comments))
+
+	// query tidak berubah setelah dipakai
+	again, err := Offset[post](ctx, query, 1, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, postIDs(page.Items), postIDs(again.Items))
+
+	// Order dari scope (misalnya filter.Filter.Scope) baru dipasang saat query dijalankan,
+	// jadi urutan primary key tidak boleh ditambahkan lebih dulu
+	byScore := db.Scopes(func(db *gorm.DB) *gorm.DB { return db.Order("score DESC, id DESC") })
+	page, err = Offset[post](ctx, byScore, 1, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{24, 19, 14, 9, 4}, postIDs(page.Items))
+
+	page, err = Offset[post](ctx, db, 9, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultSize, page.Size)
+	assert.Empty(t, page.Items)
+	assert.Equal(t, 2, page.TotalPages)
+}
+
+// walk mengikuti Next sampai habis, lalu Prev kembali ke halaman pertama
+func walk(t *testing.T, db *gorm.DB, req CursorRequest) (forward, backward []int) {
+	t.Helper()
+	ctx := context.Background()
+	var pages []*Page[post]
+	for {
+		page, err := Cursor[post](ctx, db, req)
+		assert.Nil(t, err)
+		pages = append(pages, page)
+		forward = append(forward, postIDs(page.Items)...)
+		if page.Next == "" {
+			assert.False(t, page.HasMore)
+			break
+		}
+		req.After = page.Next
+	}
+
+	req.After, req.Before = "", pages[len(pages)-1].Prev
+	backward = postIDs(pages[len(pages)-1].Items)
+	for req.Before != "" {
+		page, err := Cursor[post](ctx, db, req)
+		assert.Nil(t, err)
+		backward = append(postIDs(page.Items), backward...)
+		req.Before = page.Prev
+	}
+	assert.Equal(t, len(pages) > 1, pages[0].HasMore)
+	return forward, backward
+}
+
+func TestCursor(t *testing.T) {
+	db := openSQLite(t)
+
+	var expected []int
+	assert.Nil(t, db.Model(&post{}).Order("score DESC, id DESC").Pluck("id", &expected).Error)
+	forward, backward := walk(t, db, CursorRequest{Sort: []Order{{Column: "score", Desc: true}}, Size: 4})
+	assert.Equal(t, expected, forward) // score sama diurutkan dengan id
+	assert.Equal(t, expected, backward)
+
+	// arah campuran dan kolom waktu
+	expected = nil
+	assert.Nil(t, db.Model(&post{}).Order("score, created_at DESC, id").Pluck("id", &expected).Error)
+	forward, backward = walk(t, db, CursorRequest{Sort: []Order{{Column: "score"}, {Column: "created_at", Desc: true}}, Size: 7})
+	assert.Equal(t, expected, forward)
+	assert.Equal(t, expected, backward)
+
+	// satu halaman saja
+	forward, _ = walk(t, db.Where("score = ?", 0), CursorRequest{Size: 10})
+	assert.Equal(t, []int{5, 10, 15, 20, 25}, forward)
+}
+
+func TestCursorWithPreloadAndJoins(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	query := db.Preload("Comments").Joins("Author").Where("Author.name = ?", "Joko")
+
+	first, err := Cursor[post](ctx, query, CursorRequest{Sort: []Order{{Column: "CreatedAt", Desc: true}}, Size: 5})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{25, 23, 21, 19, 17}, postIDs(first.Items))
+	assert.Equal(t, "", first.Prev)
+	assert.Equal(t, "Joko", first.Items[4].Author.Name)
+	assert.Equal(t, 2, len(first.Items[4].Comments))
+
+	second, err := Cursor[post](ctx, query, CursorRequest{Sort: []Order{{Column: "created_at", Desc: true}}, Size: 5, After: first.Next})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{15, 13, 11, 9, 7}, postIDs(second.Items))
+
+	// baris baru di depan tidak menggeser halaman berikutnya (berbeda dengan offset)
+	assert.Nil(t, db.Create(&post{ID: 99, AuthorID: 2, CreatedAt: time.Now()}).Error)
+	third, err := Cursor[post](ctx, query, CursorRequest{Sort: []Order{{Column: "created_at", Desc: true}}, Size: 5, After: second.Next})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{5, 3, 1}, postIDs(third.Items))
+	assert.False(t, third.HasMore)
+	assert.Equal(t, "", third.Next)
+
+	previous, err := Cursor[post](ctx, query, CursorRequest{Sort: []Order{{Column: "created_at", Desc: true}}, Size: 5, Before: third.Prev})
+	assert.Nil(t, err)
+	assert.Equal(t, postIDs(second.Items), postIDs(previous.Items))
+}
+
+func TestInvalidCursor(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	page, err := Cursor[post](ctx, db, CursorRequest{Sort: []Order{{Column: "score"}}, Size: 5})
+	assert.Nil(t, err)
+
+	for _, token := range []string{"bukan-cursor!", base64.RawURLEncoding.EncodeToString([]byte(`{"c":["score"],"v":[1]}`))} {
+		_, err = Cursor[post](ctx, db, CursorRequest{Sort: []Order{{Column: "score"}}, After: token})
+		assert.ErrorIs(t, err, ErrInvalidCursor, token)
+	}
+	// cursor dari sort yang berbeda
+	_, err = Cursor[post](ctx, db, CursorRequest{Sort: []Order{{Column: "created_at"}}, After: page.Next})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	_, err = Cursor[post](ctx, db, CursorRequest{After: page.Next, Before: page.Next})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = Cursor[post](ctx, db, CursorRequest{Sort: []Order{{Column: "title"}}})
+	assert.EqualError(t, err, fmt.Sprintf("pagination: unknown sort column %q in post", "title"))
+}
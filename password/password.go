@@ -0,0 +1,192 @@
+// Package password berisi hashing password (bcrypt dan argon2id) beserta verifikasinya.
+//
+// Hash disimpan dalam format standar ($2a$... untuk bcrypt, $argon2id$... untuk argon2id),
+// sehingga Verify bisa memeriksa hash dari algoritma mana pun dan NeedsRehash bisa mendeteksi
+// hash lama yang perlu dibuat ulang setelah algoritma / parameter diganti.
+package password
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm/logger"
+)
+
+var ErrUnknownFormat = errors.New("password: unknown hash format")
+
+// Hasher membuat hash dari password. Implementasi harus aman dipanggil dari banyak goroutine.
+type Hasher interface {
+	Hash(plain string) (string, error)
+	// NeedsRehash bernilai true jika hash dibuat dengan algoritma atau parameter yang berbeda
+	NeedsRehash(hash string) bool
+}
+
+var (
+	bcryptPattern   = regexp.MustCompile(`\$2[abxy]?\$\d{2}\$[./A-Za-z0-9]{53}`)
+	argon2idPattern = regexp.MustCompile(`\$argon2id\$v=(\d+)\$m=(\d+),t=(\d+),p=(\d+)\$([A-Za-z0-9+/]+)\$([A-Za-z0-9+/]+)`)
+
+	bcryptExact   = regexp.MustCompile(`^` + bcryptPattern.String() + `$`)
+	argon2idExact = regexp.MustCompile(`^` + argon2idPattern.String() + `$`)
+)
+
+// IsHash bernilai true jika s adalah hash bcrypt atau argon2id (bukan password biasa)
+func IsHash(s string) bool {
+	return bcryptExact.MatchString(s) || argon2idExact.MatchString(s)
+}
+
+// Verify memeriksa apakah plain cocok dengan hash, apa pun algoritmanya
+func Verify(hash, plain string) (bool, error) {
+	switch {
+	case bcryptExact.MatchString(hash):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	case argon2idExact.MatchString(hash):
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, err
+		}
+		computed := argon2.IDKey([]byte(plain), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(computed, key) == 1, nil
+	default:
+		return false, ErrUnknownFormat
+	}
+}
+
+// Redact mengganti semua hash password di dalam s dengan [REDACTED]
+func Redact(s string) string {
+	s = bcryptPattern.ReplaceAllString(s, "[REDACTED]")
+	return argon2idPattern.ReplaceAllString(s, "[REDACTED]")
+}
+
+// ===== bcrypt =====
+
+// Bcrypt meng-hash password dengan bcrypt. Cost 0 berarti bcrypt.DefaultCost.
+// Catatan: bcrypt hanya memakai 72 byte pertama, password yang lebih panjang ditolak.
+type Bcrypt struct {
+	Cost int
+}
+
+func (b Bcrypt) cost() int {
+	if b.Cost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return b.Cost
+}
+
+func (b Bcrypt) Hash(plain string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), b.cost())
+	if err != nil {
+		return "", fmt.Errorf("password: %w", err)
+	}
+	return string(hash), nil
+}
+
+func (b Bcrypt) NeedsRehash(hash string) bool {
+	if !bcryptExact.MatchString(hash) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != b.cost()
+}
+
+// ===== argon2id =====
+
+// Argon2id meng-hash password dengan argon2id. Memory dalam KiB.
+type Argon2id struct {
+	Time       uint32
+	Memory     uint32
+	Threads    uint8
+	KeyLength  uint32
+	SaltLength uint32
+}
+
+// DefaultArgon2id mengikuti rekomendasi minimal OWASP (19 MiB, 2 iterasi, 1 thread)
+var DefaultArgon2id = Argon2id{Time: 2, Memory: 19 * 1024, Threads: 1, KeyLength: 32, SaltLength: 16}
+
+func (a Argon2id) Hash(plain string) (string, error) {
+	salt := make([]byte, a.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("password: %w", err)
+	}
+	key := argon2.IDKey([]byte(plain), salt, a.Time, a.Memory, a.Threads, a.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, a.Memory, a.Time, a.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a Argon2id) NeedsRehash(hash string) bool {
+	if !argon2idExact.MatchString(hash) {
+		return true
+	}
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	params.SaltLength, params.KeyLength = uint32(len(salt)), uint32(len(key))
+	return params != a
+}
+
+func decodeArgon2id(hash string) (params Argon2id, salt, key []byte, err error) {
+	parts := argon2idExact.FindStringSubmatch(hash)
+	version, _ := strconv.Atoi(parts[1])
+	memory, errMemory := strconv.ParseUint(parts[2], 10, 32)
+	iterations, errTime := strconv.ParseUint(parts[3], 10, 32)
+	threads, errThreads := strconv.ParseUint(parts[4], 10, 8)
+	if version != argon2.Version || errMemory != nil || errTime != nil || errThreads != nil || threads == 0 {
+		return params, nil, nil, errors.New("password: invalid argon2id parameters in hash")
+	}
+	params.Memory, params.Time, params.Threads = uint32(memory), uint32(iterations), uint8(threads)
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return params, nil, nil, fmt.Errorf("password: %w", err)
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[6]); err != nil {
+		return params, nil, nil, fmt.Errorf("password: %w", err)
+	}
+	return params, salt, key, nil
+}
+
+// ===== logger =====
+
+// RedactLogger membungkus logger GORM supaya hash password tidak pernah tertulis di log query.
+// Redaksi dilakukan lewat ParamsFilter (dipanggil GORM sebelum SQL ditulis ke log),
+// jadi lokasi file:baris di log tetap menunjuk ke pemanggil query.
+func RedactLogger(inner logger.Interface) logger.Interface {
+	return redactLogger{inner}
+}
+
+type redactLogger struct {
+	logger.Interface
+}
+
+func (l redactLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return redactLogger{l.Interface.LogMode(level)}
+}
+
+func (l redactLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	// opsi logger asli (misalnya ParameterizedQueries) tetap berlaku
+	if filter, ok := l.Interface.(interface {
+		ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{})
+	}); ok {
+		sql, params = filter.ParamsFilter(ctx, sql, params...)
+	}
+
+	redacted := make([]interface{}, len(params))
+	for i, param := range params {
+		redacted[i] = param
+		if text, ok := param.(string); ok && Redact(text) != text {
+			redacted[i] = "[REDACTED]"
+		}
+	}
+	return Redact(sql), redacted
+}
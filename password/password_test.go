@@ -0,0 +1,82 @@
+package password
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm/logger"
+)
+
+// parameter kecil supaya test cepat
+var (
+	fastBcrypt   = Bcrypt{Cost: bcrypt.MinCost}
+	fastArgon2id = Argon2id{Time: 1, Memory: 64, Threads: 1, KeyLength: 16, SaltLength: 8}
+)
+
+func TestHashAndVerify(t *testing.T) {
+	for name, hasher := range map[string]Hasher{"bcrypt": fastBcrypt, "argon2id": fastArgon2id} {
+		hash, err := hasher.Hash("secret")
+		assert.Nil(t, err, name)
+		assert.True(t, IsHash(hash), name)
+		assert.NotContains(t, hash, "secret", name)
+
+		ok, err := Verify(hash, "secret")
+		assert.Nil(t, err, name)
+		assert.True(t, ok, name)
+
+		ok, err = Verify(hash, "wrong")
+		assert.Nil(t, err, name)
+		assert.False(t, ok, name)
+
+		// salt acak, jadi hash selalu berbeda
+		again, _ := hasher.Hash("secret")
+		assert.NotEqual(t, hash, again, name)
+		assert.False(t, hasher.NeedsRehash(hash), name)
+	}
+
+	_, err := Verify("secret", "secret")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+	assert.False(t, IsHash("secret"))
+}
+
+func TestNeedsRehash(t *testing.T) {
+	bcryptHash, _ := fastBcrypt.Hash("secret")
+	argon2idHash, _ := fastArgon2id.Hash("secret")
+
+	// cost / parameter berubah
+	assert.True(t, Bcrypt{Cost: bcrypt.MinCost + 1}.NeedsRehash(bcryptHash))
+	stronger := fastArgon2id
+	stronger.Time = 2
+	assert.True(t, stronger.NeedsRehash(argon2idHash))
+
+	// algoritma berubah
+	assert.True(t, fastArgon2id.NeedsRehash(bcryptHash))
+	assert.True(t, fastBcrypt.NeedsRehash(argon2idHash))
+}
+
+func TestRedactLogger(t *testing.T) {
+	hash, _ := fastArgon2id.Hash("secret")
+	bcryptHash, _ := fastBcrypt.Hash("secret")
+
+	l := RedactLogger(logger.Default).LogMode(logger.Info) // LogMode tetap mengembalikan logger yang meredaksi
+	filter, ok := l.(interface {
+		ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{})
+	})
+	assert.True(t, ok)
+
+	sql, params := filter.ParamsFilter(context.Background(), "UPDATE users SET password = ?, note = '"+bcryptHash+"' WHERE id = ?", hash, "1")
+	assert.Equal(t, "UPDATE users SET password = ?, note = '[REDACTED]' WHERE id = ?", sql)
+	assert.Equal(t, []interface{}{"[REDACTED]", "1"}, params)
+
+	// opsi ParameterizedQueries dari logger asli tetap berlaku
+	parameterized := RedactLogger(logger.New(nil, logger.Config{ParameterizedQueries: true})).(interface {
+		ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{})
+	})
+	_, params = parameterized.ParamsFilter(context.Background(), "SELECT ?", hash)
+	assert.Empty(t, params)
+
+	assert.False(t, strings.Contains(Redact("no hash here"), "[REDACTED]"))
+}
@@ -1,11 +1,15 @@
 package learn_golang_gorm
 
-import "time"
+import (
+	"time"
+
+	"learn-golang-gorm/money"
+)
 
 type Product struct {
-	ID        string	`gorm:"primary_key;column:id"`
-	Name      string	`gorm:"column:name"`
-	Price     int64		`gorm:"column:price"`
+	ID        string	`gorm:"primary_key;column:id;size:100"`
+	Name      string	`gorm:"column:name;size:100"`
+	Price     money.Money	`gorm:"column:price;size:100;serializer:money"` // disimpan sebagai teks, misalnya "IDR 1000000"
 	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
 	LikedByUsers []User	`gorm:"many2many:user_like_product;foreignKey:id;joinForeignKey:product_id;references:id;joinReferences:user_id"`
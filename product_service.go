@@ -0,0 +1,105 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"learn-golang-gorm/dberrors"
+	"learn-golang-gorm/idempotency"
+	"learn-golang-gorm/uow"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrUserNotFound    = errors.New("user not found")
+)
+
+// ProductService berisi operasi pada product yang dipanggil dari luar (misalnya API)
+type ProductService struct {
+	db   *gorm.DB
+	keys *idempotency.Store
+}
+
+func NewProductService(db *gorm.DB) *ProductService {
+	return &ProductService{db: db, keys: idempotency.New(db)}
+}
+
+// LikeResult adalah hasil Like. Replayed bernilai true jika hasil diambil dari request sebelumnya.
+type LikeResult struct {
+	ProductID string
+	UserID    string
+	Likes     int64 // jumlah user yang menyukai product setelah like
+	Replayed  bool  `json:"-"`
+}
+
+// Like mencatat bahwa user menyukai product (tabel user_like_product).
+// Retry dengan idempotencyKey yang sama mengembalikan hasil yang sama tanpa menulis ulang.
+func (s *ProductService) Like(ctx context.Context, productID, userID, idempotencyKey string) (*LikeResult, error) {
+	request := struct {
+		ProductID string
+		UserID    string
+	}{productID, userID}
+	result, replayed, err := idempotency.Do(ctx, s.keys, "product_like", idempotencyKey, request,
+		func(tx *gorm.DB) (LikeResult, error) {
+			var product Product
+			if err := tx.Take(&product, "id = ?", productID).Error; err != nil {
+				return LikeResult{}, notFound(err, ErrProductNotFound, productID)
+			}
+			if err := tx.Take(&User{}, "id = ?", userID).Error; err != nil {
+				return LikeResult{}, notFound(err, ErrUserNotFound, userID)
+			}
+
+			// sudah pernah like (dengan key lain) bukan error
+			like := map[string]interface{}{"user_id": userID, "product_id": productID}
+			if err := tx.Table("user_like_product").Clauses(clause.OnConflict{DoNothing: true}).Create(like).Error; err != nil {
+				return LikeResult{}, err
+			}
+			association := tx.Model(&product).Association("LikedByUsers")
+			likes := association.Count()
+			if association.Error != nil {
+				return LikeResult{}, association.Error
+			}
+			return LikeResult{ProductID: productID, UserID: userID, Likes: likes}, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	result.Replayed = replayed
+	return &result, nil
+}
+
+// Unlike menghapus like user pada product dan mengembalikan jumlah like yang tersisa.
+// Menghapus like yang tidak ada bukan error, jadi aman di-retry tanpa idempotency key.
+func (s *ProductService) Unlike(ctx context.Context, productID, userID string) (int64, error) {
+	var likes int64
+	err := uow.DB(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		var product Product
+		if err := tx.Take(&product, "id = ?", productID).Error; err != nil {
+			return notFound(err, ErrProductNotFound, productID)
+		}
+		if err := tx.Model(&product).Association("LikedByUsers").Delete(&User{ID: userID}); err != nil {
+			return err
+		}
+		// Association baru, karena Delete sudah mengubah statement association sebelumnya
+		association := tx.Model(&product).Association("LikedByUsers")
+		likes = association.Count()
+		return association.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return likes, nil
+}
+
+// notFound mengganti gorm.ErrRecordNotFound dengan error domain target, yang juga cocok
+// dengan dberrors.ErrNotFound (misalnya untuk HTTP 404)
+func notFound(err, target error, id string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &dberrors.Error{Kind: dberrors.ErrNotFound, Err: fmt.Errorf("%w: %s", target, id)}
+	}
+	return err
+}
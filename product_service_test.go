@@ -0,0 +1,85 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"learn-golang-gorm/idempotency"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestLikeProduct(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "products", "likes")
+	service := NewProductService(db)
+
+	result, err := service.Like(ctx, "P001", "3", "like-1")
+	assert.Nil(t, err)
+	assert.False(t, result.Replayed)
+	assert.Equal(t, int64(3), result.Likes) // user 1 dan 2 dari fixture, ditambah user 3
+
+	replay, err := service.Like(ctx, "P001", "3", "like-1")
+	assert.Nil(t, err)
+	assert.True(t, replay.Replayed)
+	assert.Equal(t, int64(3), replay.Likes)
+
+	_, err = service.Like(ctx, "P001", "4", "like-1")
+	assert.ErrorIs(t, err, idempotency.ErrConflict)
+
+	// like ulang dengan key baru tidak menambah baris
+	result, err = service.Like(ctx, "P001", "1", "like-2")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), result.Likes)
+
+	_, err = service.Like(ctx, "P404", "1", "like-3")
+	assert.ErrorIs(t, err, ErrProductNotFound)
+	_, err = service.Like(ctx, "P001", "404", "like-4")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestLikeProductCountError(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "products", "likes")
+	// hanya query jumlah like (Association.Count) yang gagal
+	broken := errors.New("connection reset")
+	assert.Nil(t, db.Callback().Query().Before("gorm:query").Register("test:broken_count", func(tx *gorm.DB) {
+		if _, count := tx.Statement.Dest.(*int64); count {
+			tx.AddError(broken)
+		}
+	}))
+
+	_, err := NewProductService(db).Like(ctx, "P001", "3", "like-1")
+	assert.ErrorIs(t, err, broken)
+	likers, err := NewUserRepository(db).FindLikers(ctx, "P001")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(likers)) // like-nya ikut di-rollback
+}
+
+func TestUnlikeProduct(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "products", "likes")
+	service := NewProductService(db)
+
+	likes, err := service.Unlike(ctx, "P001", "1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), likes)
+
+	// unlike ulang atau user yang belum like tidak mengubah apa pun
+	likes, err = service.Unlike(ctx, "P001", "1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), likes)
+
+	likers, err := NewUserRepository(db).FindLikers(ctx, "P001")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(likers))
+	assert.Equal(t, "2", likers[0].ID)
+
+	_, err = service.Unlike(ctx, "P404", "1")
+	assert.ErrorIs(t, err, ErrProductNotFound)
+}
@@ -0,0 +1,165 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"time"
+
+	"learn-golang-gorm/money"
+	"learn-golang-gorm/repository"
+
+	"gorm.io/gorm"
+)
+
+// Repository per model: operasi umum dari repository.Repository ditambah query khusus model tersebut.
+// Di dalam transaction, buat repository dari tx (misalnya NewWalletRepository(tx)).
+
+type UserRepository struct {
+	*repository.Repository[User, string]
+}
+
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{repository.New[User, string](db)}
+}
+
+// FindWithWallet membaca user beserta wallet-nya dalam satu query (JOIN)
+func (r *UserRepository) FindWithWallet(ctx context.Context, id string) (*User, error) {
+	var user User
+	if err := r.DB(ctx).Joins("Wallet").Take(&user, "users.id = ?", id).Error; err != nil {
+		return nil, notFound(err, ErrUserNotFound, id)
+	}
+	return &user, nil
+}
+
+// SearchByName mencari user yang nama depan, tengah atau belakangnya mengandung name
+func (r *UserRepository) SearchByName(ctx context.Context, name string, specs ...repository.Spec) ([]User, error) {
+	pattern := "%" + name + "%"
+	return r.FindAll(ctx, append(specs,
+		repository.Where("first_name LIKE ? OR middle_name LIKE ? OR last_name LIKE ?", pattern, pattern, pattern),
+	)...)
+}
+
+// FindLikers mengembalikan user yang menyukai product
+func (r *UserRepository) FindLikers(ctx context.Context, productID string) ([]User, error) {
+	var users []User
+	err := r.DB(ctx).
+		Joins("JOIN user_like_product ON user_like_product.user_id = users.id").
+		Where("user_like_product.product_id = ?", productID).
+		Order("users.id").Find(&users).Error
+	return users, err
+}
+
+type WalletRepository struct {
+	*repository.Repository[Wallet, string]
+}
+
+func NewWalletRepository(db *gorm.DB) *WalletRepository {
+	return &WalletRepository{repository.New[Wallet, string](db)}
+}
+
+// FindByUser mengembalikan wallet milik user (satu user satu wallet)
+func (r *WalletRepository) FindByUser(ctx context.Context, userID string) (*Wallet, error) {
+	var wallet Wallet
+	if err := r.DB(ctx).Take(&wallet, "user_id = ?", userID).Error; err != nil {
+		return nil, notFound(err, ErrWalletNotFound, "of user "+userID)
+	}
+	return &wallet, nil
+}
+
+// FindBalanceAtLeast mengembalikan wallet dengan mata uang yang sama dan saldo minimal amount
+func (r *WalletRepository) FindBalanceAtLeast(ctx context.Context, amount money.Money) ([]Wallet, error) {
+	return r.FindAll(ctx,
+		repository.Where("balance_currency = ? AND balance_amount >= ?", amount.Currency, amount.Amount),
+		repository.Order("balance_amount DESC"),
+	)
+}
+
+// TotalBalance menjumlahkan saldo semua wallet dengan mata uang currency
+func (r *WalletRepository) TotalBalance(ctx context.Context, currency money.Currency) (money.Money, error) {
+	var total int64
+	err := r.DB(ctx).Select("COALESCE(SUM(balance_amount), 0)").Where("balance_currency = ?", currency).Scan(&total).Error
+	return money.New(total, currency), err
+}
+
+type ProductRepository struct {
+	*repository.Repository[Product, string]
+}
+
+func NewProductRepository(db *gorm.DB) *ProductRepository {
+	return &ProductRepository{repository.New[Product, string](db)}
+}
+
+// FindLikedBy mengembalikan product yang disukai user
+func (r *ProductRepository) FindLikedBy(ctx context.Context, userID string) ([]Product, error) {
+	var products []Product
+	err := r.DB(ctx).
+		Joins("JOIN user_like_product ON user_like_product.product_id = products.id").
+		Where("user_like_product.user_id = ?", userID).
+		Order("products.id").Find(&products).Error
+	return products, err
+}
+
+// ProductLikes adalah hasil MostLiked
+type ProductLikes struct {
+	Product
+	Likes int64
+}
+
+// MostLiked mengembalikan limit product dengan like terbanyak
+func (r *ProductRepository) MostLiked(ctx context.Context, limit int) ([]ProductLikes, error) {
+	var products []ProductLikes
+	err := r.DB(ctx).
+		Select("products.*", "COUNT(user_like_product.user_id) AS likes").
+		Joins("LEFT JOIN user_like_product ON user_like_product.product_id = products.id").
+		Group("products.id").
+		Order("likes DESC, products.id").
+		Limit(limit).Find(&products).Error
+	return products, err
+}
+
+type AddressRepository struct {
+	*repository.Repository[Address, int64]
+}
+
+func NewAddressRepository(db *gorm.DB) *AddressRepository {
+	return &AddressRepository{repository.New[Address, int64](db)}
+}
+
+func (r *AddressRepository) FindByUser(ctx context.Context, userID string) ([]Address, error) {
+	return r.FindAll(ctx, repository.Where("user_id = ?", userID), repository.Order("id"))
+}
+
+// TodoRepository: Todo memakai gorm.Model, jadi SoftDelete dan Restore bisa dipakai
+type TodoRepository struct {
+	*repository.Repository[Todo, uint]
+}
+
+func NewTodoRepository(db *gorm.DB) *TodoRepository {
+	return &TodoRepository{repository.New[Todo, uint](db)}
+}
+
+func (r *TodoRepository) FindByUser(ctx context.Context, userID string) ([]Todo, error) {
+	return r.FindAll(ctx, repository.Where("user_id = ?", userID), repository.Order("id"))
+}
+
+// FindDeleted mengembalikan todo milik user yang sudah di-soft delete (bisa di-Restore)
+func (r *TodoRepository) FindDeleted(ctx context.Context, userID string) ([]Todo, error) {
+	return r.FindAll(ctx, repository.WithDeleted(),
+		repository.Where("user_id = ? AND deleted_at IS NOT NULL", userID), repository.Order("id"))
+}
+
+type GuestBookRepository struct {
+	*repository.Repository[GuestBook, int64]
+}
+
+func NewGuestBookRepository(db *gorm.DB) *GuestBookRepository {
+	return &GuestBookRepository{repository.New[GuestBook, int64](db)}
+}
+
+func (r *GuestBookRepository) FindByEmail(ctx context.Context, email string) ([]GuestBook, error) {
+	return r.FindAll(ctx, repository.Where("email = ?", email), repository.Order("id"))
+}
+
+// Recent mengembalikan limit pesan terbaru sejak since
+func (r *GuestBookRepository) Recent(ctx context.Context, since time.Time, limit int) ([]GuestBook, error) {
+	return r.FindAll(ctx, repository.Where("created_at >= ?", since), repository.Order("created_at DESC, id DESC"), repository.Limit(limit))
+}
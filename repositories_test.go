@@ -0,0 +1,241 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"learn-golang-gorm/dberrors"
+	"learn-golang-gorm/money"
+	"learn-golang-gorm/pagination"
+	"learn-golang-gorm/repository"
+	"learn-golang-gorm/uow"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets", "products", "likes")
+	users := NewUserRepository(db)
+
+	user, err := users.FindWithWallet(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "Lev Tempest Vex", user.Name.Full())
+	assert.Equal(t, idr(1000000), user.Wallet.Balance)
+	_, err = users.FindWithWallet(ctx, "404")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	assert.ErrorIs(t, err, dberrors.ErrNotFound)
+	assert.Equal(t, "user not found: 404", err.Error())
+
+	found, err := users.SearchByName(ctx, "Tempest")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(found))
+	found, err = users.SearchByName(ctx, "User 2", repository.Order("id"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"20", "21"}, []string{found[0].ID, found[1].ID})
+
+	likers, err := users.FindLikers(ctx, "P001")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(likers))
+
+	// Update sebagian tetap melewati hook BeforeSave (password di-hash)
+	assert.Nil(t, users.Update(ctx, "2", map[string]interface{}{"password": "rahasia"}))
+	user, err = users.FindByID(ctx, "2")
+	assert.Nil(t, err)
+	assert.True(t, user.VerifyPassword("rahasia"))
+	assert.Equal(t, "User2", user.Name.FirstName)
+}
+
+func TestWalletRepository(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	wallets := NewWalletRepository(db)
+	assert.Nil(t, wallets.Create(ctx, &Wallet{ID: "3", UserId: "3", Balance: money.New(5000, money.USD)}))
+
+	wallet, err := wallets.FindByUser(ctx, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, "2", wallet.ID)
+	_, err = wallets.FindByUser(ctx, "404")
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+
+	total, err := wallets.TotalBalance(ctx, money.IDR)
+	assert.Nil(t, err)
+	assert.Equal(t, idr(4000000), total)
+	rich, err := wallets.FindBalanceAtLeast(ctx, money.New(1000, money.USD))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(rich))
+	assert.Equal(t, "3", rich[0].ID)
+
+	// di dalam transaction repository dibuat dari tx
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := NewWalletRepository(tx).Delete(ctx, "3"); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	assert.NotNil(t, err)
+	exists, err := wallets.Exists(ctx, "3")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+}
+
+func TestProductRepository(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "products", "likes")
+	products := NewProductRepository(db)
+	assert.Nil(t, products.Upsert(ctx, &Product{ID: "P002", Name: "Product 2", Price: idr(5000)}))
+	assert.Nil(t, products.Upsert(ctx, &Product{ID: "P002", Name: "Product 2 (baru)", Price: idr(7500)}))
+
+	product, err := products.FindByID(ctx, "P002")
+	assert.Nil(t, err)
+	assert.Equal(t, idr(7500), product.Price)
+
+	liked, err := products.FindLikedBy(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(liked))
+	assert.Equal(t, "P001", liked[0].ID)
+
+	top, err := products.MostLiked(ctx, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(top))
+	assert.Equal(t, []string{"P001", "P002"}, []string{top[0].ID, top[1].ID})
+	assert.Equal(t, []int64{2, 0}, []int64{top[0].Likes, top[1].Likes})
+	assert.Equal(t, idr(1000000), top[0].Price)
+}
+
+func TestAddressAndGuestBookRepository(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users")
+
+	addresses := NewAddressRepository(db)
+	assert.Nil(t, addresses.CreateInBatches(ctx, []Address{{UserId: "1", Address: "Jalan A"}, {UserId: "1", Address: "Jalan B"}, {UserId: "2", Address: "Jalan C"}}, 2))
+	found, err := addresses.FindByUser(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(found))
+	assert.Nil(t, addresses.Update(ctx, found[0].ID, map[string]interface{}{"address": "Jalan A1"}))
+	address, err := addresses.FindByID(ctx, found[0].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "Jalan A1", address.Address)
+
+	guestBooks := NewGuestBookRepository(db)
+	for _, message := range []string{"satu", "dua", "tiga"} {
+		assert.Nil(t, guestBooks.Create(ctx, &GuestBook{Name: "Guest", Email: "guest@example.com", Message: message}))
+	}
+	assert.Nil(t, guestBooks.Create(ctx, &GuestBook{Name: "Other", Email: "other@example.com", Message: "lain"}))
+	byEmail, err := guestBooks.FindByEmail(ctx, "guest@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(byEmail))
+	recent, err := guestBooks.Recent(ctx, time.Now().Add(-time.Hour), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"lain", "tiga"}, []string{recent[0].Message, recent[1].Message})
+}
+
+func TestTodoRepository(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "todos")
+	todos := NewTodoRepository(db)
+	assert.Nil(t, todos.Create(ctx, &Todo{UserId: "1", Title: "Title 2"}))
+
+	active, err := todos.FindByUser(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(active))
+
+	assert.Nil(t, todos.SoftDelete(ctx, active[0].ID))
+	deleted, err := todos.FindDeleted(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(deleted))
+
+	assert.Nil(t, todos.Restore(ctx, 1)) // todo dari fixture
+	active, err = todos.FindByUser(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Title 1"}, []string{active[0].Title})
+}
+
+func TestPaginateUsers(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets", "addresses")
+	users := NewUserRepository(db)
+
+	// seperti TestPreloadJoinOneToMany, tetapi per halaman
+	query := users.DB(ctx).Preload("Addresses").Joins("Wallet")
+	page, err := pagination.Offset[User](ctx, query, 1, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(16), page.Total)
+	assert.Equal(t, 4, page.TotalPages)
+	assert.Equal(t, []string{"1", "10", "11", "12", "13"}, []string{page.Items[0].ID, page.Items[1].ID, page.Items[2].ID, page.Items[3].ID, page.Items[4].ID})
+	assert.Equal(t, 2, len(page.Items[0].Addresses))
+	assert.Equal(t, idr(1000000), page.Items[0].Wallet.Balance)
+
+	rich := query.Where("Wallet.balance_amount > ?", 0)
+	sort := []pagination.Order{{Column: "id", Desc: true}}
+	first, err := pagination.Cursor[User](ctx, rich, pagination.CursorRequest{Sort: sort, Size: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"21", "20", "2"}, []string{first.Items[0].ID, first.Items[1].ID, first.Items[2].ID})
+	assert.True(t, first.HasMore)
+	last, err := pagination.Cursor[User](ctx, rich, pagination.CursorRequest{Sort: sort, Size: 3, After: first.Next})
+	assert.Nil(t, err)
+	assert.Equal(t, "1", last.Items[0].ID)
+	assert.Equal(t, "Lev Tempest Vex", last.Items[0].Name.Full())
+	assert.False(t, last.HasMore)
+	assert.NotEmpty(t, last.Prev)
+}
+
+func TestRepositoriesInUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	users, wallets := NewUserRepository(db), NewWalletRepository(db)
+	work := uow.New(db)
+	var welcomed []string
+
+	// user, wallet dan transfer saldo awal dalam satu transaction
+	register := func(ctx context.Context, id string, deposit int64) error {
+		return work.Do(ctx, func(ctx context.Context) error {
+			if err := users.Create(ctx, &User{ID: id, Name: Name{FirstName: "New " + id}, Password: "secret"}); err != nil {
+				return err
+			}
+			if err := wallets.Create(ctx, &Wallet{ID: id, UserId: id, Balance: idr(0)}); err != nil {
+				return err
+			}
+			uow.AfterCommit(ctx, func(context.Context) { welcomed = append(welcomed, id) })
+			_, err := NewTransferService(db).Transfer(ctx, "1", id, idr(deposit), "welcome-"+id)
+			return err
+		})
+	}
+
+	assert.Nil(t, register(ctx, "100", 400000))
+	assert.ErrorIs(t, register(ctx, "101", 700000), ErrInsufficientFunds)
+	assert.Equal(t, []string{"100"}, welcomed)
+
+	// transfer yang gagal membatalkan user dan wallet yang dibuat sebelumnya
+	exists, err := users.Exists(ctx, "101")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+	wallet, err := wallets.FindByID(ctx, "100")
+	assert.Nil(t, err)
+	assert.Equal(t, idr(400000), wallet.Balance)
+
+	// registrasi di dalam registrasi lain memakai savepoint
+	err = work.Do(ctx, func(ctx context.Context) error {
+		assert.NotNil(t, register(ctx, "102", 10000000))
+		return register(ctx, "103", 1000)
+	})
+	assert.Nil(t, err)
+	count, err := users.Count(ctx, repository.Where("id IN ?", []string{"102", "103"}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, []string{"100", "103"}, welcomed)
+
+	report, err := NewLedger(db).Reconcile(ctx, RepairNone)
+	assert.Nil(t, err)
+	assert.True(t, report.OK())
+}
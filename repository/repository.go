@@ -0,0 +1,261 @@
+// Package repository berisi Repository[T, ID], operasi CRUD yang bertipe untuk model GORM apa pun,
+// supaya pemanggil tidak perlu menulis kondisi string seperti "id = ?" berulang kali.
+//
+//	users := repository.New[User, string](db)
+//	user, err := users.FindByID(ctx, "1")
+//	active, err := users.FindAll(ctx, repository.Where("first_name LIKE ?", "User%"), repository.Limit(10))
+//
+// Repository otomatis ikut transaction yang dibawa ctx dari uow.UnitOfWork.Do. Dengan db.Transaction,
+// buat repository dari tx-nya:
+//
+//	db.Transaction(func(tx *gorm.DB) error {
+//		return repository.New[User, string](tx).Create(ctx, &user)
+//	})
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"learn-golang-gorm/uow"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	// ErrNotFound juga cocok dengan gorm.ErrRecordNotFound lewat errors.Is
+	ErrNotFound               = errors.New("repository: record not found")
+	ErrSoftDeleteNotSupported = errors.New("repository: model has no gorm.DeletedAt field")
+)
+
+// Spec mengubah query FindAll / Count, sama seperti fungsi untuk db.Scopes
+type Spec func(db *gorm.DB) *gorm.DB
+
+// Where sama seperti db.Where
+func Where(query interface{}, args ...interface{}) Spec {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(query, args...)
+	}
+}
+
+// Order sama seperti db.Order, misalnya Order("created_at DESC")
+func Order(value interface{}) Spec {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Order(value)
+	}
+}
+
+func Limit(limit int) Spec {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Limit(limit)
+	}
+}
+
+func Offset(offset int) Spec {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Offset(offset)
+	}
+}
+
+// Preload sama seperti db.Preload, misalnya Preload("Wallet")
+func Preload(association string, args ...interface{}) Spec {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Preload(association, args...)
+	}
+}
+
+// WithDeleted ikut membaca baris yang sudah di-soft delete (db.Unscoped)
+func WithDeleted() Spec {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}
+}
+
+// Repository berisi operasi umum untuk model T dengan primary key bertipe ID (satu kolom)
+type Repository[T any, ID comparable] struct {
+	db *gorm.DB
+}
+
+func New[T any, ID comparable](db *gorm.DB) *Repository[T, ID] {
+	return &Repository[T, ID]{db: db}
+}
+
+// DB mengembalikan *gorm.DB dengan ctx dan model T, untuk query yang belum ada di Repository
+func (r *Repository[T, ID]) DB(ctx context.Context) *gorm.DB {
+	return r.conn(ctx).Model(new(T))
+}
+
+// conn memakai transaction dari ctx jika ada (lihat package uow)
+func (r *Repository[T, ID]) conn(ctx context.Context) *gorm.DB {
+	return uow.DB(ctx, r.db)
+}
+
+// byID adalah kondisi "<primary key> = id", nama kolomnya diambil dari schema model
+func byID(id interface{}) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey}, Value: id}
+}
+
+func notFound[T any](err error, id interface{}) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %s %v: %w", ErrNotFound, reflect.TypeOf(new(T)).Elem().Name(), id, gorm.ErrRecordNotFound)
+	}
+	return err
+}
+
+func (r *Repository[T, ID]) FindByID(ctx context.Context, id ID, specs ...Spec) (*T, error) {
+	var record T
+	if err := r.conn(ctx).Scopes(toScopes(specs)...).Where(byID(id)).Take(&record).Error; err != nil {
+		return nil, notFound[T](err, id)
+	}
+	return &record, nil
+}
+
+// FindAll mengembalikan semua record yang cocok dengan specs (tanpa specs = semua record)
+func (r *Repository[T, ID]) FindAll(ctx context.Context, specs ...Spec) ([]T, error) {
+	var records []T
+	err := r.conn(ctx).Scopes(toScopes(specs)...).Find(&records).Error
+	return records, err
+}
+
+func (r *Repository[T, ID]) Create(ctx context.Context, record *T) error {
+	return r.conn(ctx).Create(record).Error
+}
+
+// CreateInBatches menyimpan records dengan beberapa INSERT, masing-masing batchSize baris
+func (r *Repository[T, ID]) CreateInBatches(ctx context.Context, records []T, batchSize int) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return r.conn(ctx).CreateInBatches(&records, batchSize).Error
+}
+
+// Update hanya mengubah kolom yang ada di fields (nama kolom atau nama field), hook BeforeSave tetap berjalan
+func (r *Repository[T, ID]) Update(ctx context.Context, id ID, fields map[string]interface{}) error {
+	result := r.conn(ctx).Model(new(T)).Where(byID(id)).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// MySQL menghitung 0 baris jika nilainya sama, jadi dicek ulang
+		exists, err := r.Exists(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return notFound[T](gorm.ErrRecordNotFound, id)
+		}
+	}
+	return nil
+}
+
+// Upsert menyimpan record, atau mengubah record dengan primary key yang sama.
+// Tanpa columns semua kolom diubah, dengan columns hanya kolom tersebut.
+func (r *Repository[T, ID]) Upsert(ctx context.Context, record *T, columns ...string) error {
+	onConflict := clause.OnConflict{UpdateAll: true}
+	if len(columns) > 0 {
+		// GORM hanya mengisi kolom konflik untuk UpdateAll, Postgres butuh ON CONFLICT (kolom)
+		modelSchema, err := r.schema()
+		if err != nil {
+			return err
+		}
+		onConflict = clause.OnConflict{DoUpdates: clause.AssignmentColumns(columns)}
+		for _, field := range modelSchema.PrimaryFields {
+			onConflict.Columns = append(onConflict.Columns, clause.Column{Name: field.DBName})
+		}
+	}
+	return r.conn(ctx).Clauses(onConflict).Create(record).Error
+}
+
+// Delete menghapus record secara permanen, termasuk model dengan gorm.DeletedAt
+func (r *Repository[T, ID]) Delete(ctx context.Context, id ID) error {
+	return r.delete(r.conn(ctx).Unscoped(), id)
+}
+
+// SoftDelete mengisi deleted_at, record tidak lagi terbaca kecuali dengan WithDeleted
+func (r *Repository[T, ID]) SoftDelete(ctx context.Context, id ID) error {
+	if err := r.requireSoftDelete(); err != nil {
+		return err
+	}
+	return r.delete(r.conn(ctx), id)
+}
+
+func (r *Repository[T, ID]) delete(db *gorm.DB, id ID) error {
+	result := db.Where(byID(id)).Delete(new(T))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notFound[T](gorm.ErrRecordNotFound, id)
+	}
+	return nil
+}
+
+// Restore mengembalikan record yang di-soft delete
+func (r *Repository[T, ID]) Restore(ctx context.Context, id ID) error {
+	field, err := r.deletedAtField()
+	if err != nil {
+		return err
+	}
+	result := r.conn(ctx).Unscoped().Model(new(T)).
+		Where(byID(id)).Where(clause.Neq{Column: field, Value: nil}).
+		Update(field, nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notFound[T](gorm.ErrRecordNotFound, id)
+	}
+	return nil
+}
+
+func (r *Repository[T, ID]) Exists(ctx context.Context, id ID) (bool, error) {
+	count, err := r.Count(ctx, Where(byID(id)))
+	return count > 0, err
+}
+
+func (r *Repository[T, ID]) Count(ctx context.Context, specs ...Spec) (int64, error) {
+	var count int64
+	err := r.conn(ctx).Model(new(T)).Scopes(toScopes(specs)...).Count(&count).Error
+	return count, err
+}
+
+func (r *Repository[T, ID]) requireSoftDelete() error {
+	_, err := r.deletedAtField()
+	return err
+}
+
+// deletedAtField mencari kolom bertipe gorm.DeletedAt pada model T
+func (r *Repository[T, ID]) deletedAtField() (string, error) {
+	modelSchema, err := r.schema()
+	if err != nil {
+		return "", err
+	}
+	for _, field := range modelSchema.Fields {
+		if field.DBName != "" && field.FieldType == deletedAtType {
+			return field.DBName, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrSoftDeleteNotSupported, modelSchema.Name)
+}
+
+func (r *Repository[T, ID]) schema() (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+func toScopes(specs []Spec) []func(*gorm.DB) *gorm.DB {
+	scopes := make([]func(*gorm.DB) *gorm.DB, len(specs))
+	for i, spec := range specs {
+		scopes[i] = spec
+	}
+	return scopes
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"learn-golang-gorm/internal/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type note struct {
+	Code      string `gorm:"primaryKey;size:20"`
+	Title     string
+	Views     int
+	DeletedAt gorm.DeletedAt
+}
+
+type tag struct {
+	ID   int `gorm:"primaryKey"`
+	Name string
+}
+
+func openSQLite(t *testing.T) *gorm.DB {
+	return testdb.Memory(t, &note{}, &tag{})
+}
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	notes := New[note, string](openSQLite(t))
+
+	assert.Nil(t, notes.Create(ctx, &note{Code: "a", Title: "A"}))
+	assert.Nil(t, notes.CreateInBatches(ctx, []note{{Code: "b", Title: "B", Views: 5}, {Code: "c", Title: "C", Views: 9}}, 1))
+	assert.Nil(t, notes.CreateInBatches(ctx, nil, 10))
+
+	found, err := notes.FindByID(ctx, "b")
+	assert.Nil(t, err)
+	assert.Equal(t, "B", found.Title)
+	_, err = notes.FindByID(ctx, "x")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	popular, err := notes.FindAll(ctx, Where("views > ?", 1), Order("views DESC"), Limit(1))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"c"}, []string{popular[0].Code})
+	page, err := notes.FindAll(ctx, Order("code"), Limit(2), Offset(1))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(page))
+	assert.Equal(t, "b", page[0].Code)
+
+	// update sebagian: kolom lain tidak berubah
+	assert.Nil(t, notes.Update(ctx, "a", map[string]interface{}{"views": 3}))
+	found, err = notes.FindByID(ctx, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, note{Code: "a", Title: "A", Views: 3}, *found)
+	assert.ErrorIs(t, notes.Update(ctx, "x", map[string]interface{}{"views": 1}), ErrNotFound)
+
+	// upsert: semua kolom, atau hanya kolom tertentu
+	assert.Nil(t, notes.Upsert(ctx, &note{Code: "a", Title: "A2", Views: 10}))
+	assert.Nil(t, notes.Upsert(ctx, &note{Code: "b", Title: "ignored", Views: 6}, "views"))
+	assert.Nil(t, notes.Upsert(ctx, &note{Code: "d", Title: "D"}))
+	a, _ := notes.FindByID(ctx, "a")
+	b, _ := notes.FindByID(ctx, "b")
+	assert.Equal(t, []string{"A2", "B"}, []string{a.Title, b.Title})
+	assert.Equal(t, 6, b.Views)
+
+	exists, err := notes.Exists(ctx, "d")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	count, err := notes.Count(ctx, Where("views >= ?", 6))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestRepositorySoftDelete(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	notes := New[note, string](db)
+	assert.Nil(t, notes.CreateInBatches(ctx, []note{{Code: "a"}, {Code: "b"}}, 10))
+
+	assert.Nil(t, notes.SoftDelete(ctx, "a"))
+	assert.ErrorIs(t, notes.SoftDelete(ctx, "a"), ErrNotFound)
+	_, err := notes.FindByID(ctx, "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+	deleted, err := notes.FindByID(ctx, "a", WithDeleted())
+	assert.Nil(t, err)
+	assert.True(t, deleted.DeletedAt.Valid)
+	count, _ := notes.Count(ctx)
+	assert.Equal(t, int64(1), count)
+
+	assert.Nil(t, notes.Restore(ctx, "a"))
+	assert.ErrorIs(t, notes.Restore(ctx, "a"), ErrNotFound)
+	count, _ = notes.Count(ctx)
+	assert.Equal(t, int64(2), count)
+
+	// Delete menghapus permanen, termasuk model dengan DeletedAt
+	assert.Nil(t, notes.Delete(ctx, "b"))
+	count, _ = notes.Count(ctx, WithDeleted())
+	assert.Equal(t, int64(1), count)
+	assert.ErrorIs(t, notes.Delete(ctx, "b"), ErrNotFound)
+
+	tags := New[tag, int](db)
+	assert.Nil(t, tags.Create(ctx, &tag{ID: 1, Name: "go"}))
+	assert.ErrorIs(t, tags.SoftDelete(ctx, 1), ErrSoftDeleteNotSupported)
+	assert.ErrorIs(t, tags.Restore(ctx, 1), ErrSoftDeleteNotSupported)
+	assert.Nil(t, tags.Delete(ctx, 1))
+}
+
+func TestRepositoryInTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := New[tag, int](tx).Create(ctx, &tag{ID: 1, Name: "go"}); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	assert.NotNil(t, err)
+	exists, err := New[tag, int](db).Exists(ctx, 1)
+	assert.Nil(t, err)
+	assert.False(t, exists)
+}
+
+func TestRepositoryUpdateExistsError(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	// update ke id yang tidak ada menghasilkan 0 baris, lalu Exists gagal: error-nya tidak boleh jadi ErrNotFound
+	broken := errors.New("connection reset")
+	assert.Nil(t, db.Callback().Query().Before("gorm:query").Register("test:broken", func(tx *gorm.DB) {
+		tx.AddError(broken)
+	}))
+
+	err := New[note, string](db).Update(ctx, "x", map[string]interface{}{"views": 1})
+	assert.ErrorIs(t, err, broken)
+	assert.False(t, errors.Is(err, ErrNotFound))
+}
+
+func TestRepositoryUpsertConflictColumns(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	var sql string
+	assert.Nil(t, db.Callback().Create().After("gorm:create").Register("test:sql", func(tx *gorm.DB) {
+		sql = tx.Statement.SQL.String()
+	}))
+	notes := New[note, string](db)
+
+	// Postgres menolak ON CONFLICT DO UPDATE tanpa kolom konflik
+	assert.Nil(t, notes.Upsert(ctx, &note{Code: "a", Title: "A"}, "title"))
+	assert.Contains(t, sql, "ON CONFLICT (`code`) DO UPDATE SET `title`=`excluded`.`title`")
+	assert.Nil(t, notes.Upsert(ctx, &note{Code: "a", Title: "A2"}, "title"))
+	found, err := notes.FindByID(ctx, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, "A2", found.Title)
+}
@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/internal/trusted"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ErrUntrustedActor dikembalikan jika metadata x-actor-id dikirim oleh peer yang tidak dipercaya
+var ErrUntrustedActor = errors.New(ActorMetadataKey + " is only accepted from a trusted proxy")
+
+// Authenticator menentukan user (actor) yang melakukan call, untuk audit log. Actor kosong berarti
+// call tanpa user, error membuat call ditolak dengan status Unauthenticated.
+type Authenticator func(ctx context.Context) (actor string, err error)
+
+// Option mengubah konfigurasi server gRPC, lihat New
+type Option func(*options)
+
+type options struct {
+	authenticate Authenticator
+	server       []grpc.ServerOption
+}
+
+// WithAuthenticator mengganti cara server menentukan actor. Tanpa option ini metadata x-actor-id selalu
+// ditolak, sama seperti header X-Actor-ID pada REST API.
+func WithAuthenticator(authenticate Authenticator) Option {
+	return func(o *options) {
+		o.authenticate = authenticate
+	}
+}
+
+// WithServerOptions menambahkan grpc.ServerOption, dipasang setelah interceptor milik package ini
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(o *options) {
+		o.server = append(o.server, opts...)
+	}
+}
+
+// TrustedPeers menerima metadata x-actor-id hanya dari koneksi yang datang langsung dari proxies (lihat
+// server.TrustedProxies). Proxy tersebut wajib mengisi atau menghapus x-actor-id dari client.
+func TrustedPeers(proxies ...netip.Prefix) Authenticator {
+	return func(ctx context.Context) (string, error) {
+		actor := metadataActor(ctx)
+		if actor == "" {
+			return "", nil
+		}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil && trusted.Addr(p.Addr.String(), proxies) {
+			return actor, nil
+		}
+		return "", ErrUntrustedActor
+	}
+}
+
+func metadataActor(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ActorMetadataKey); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// actor memasang actor dari authenticate ke ctx (learn_golang_gorm.WithActor)
+func actor(authenticate Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		actor, err := authenticate(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if actor != "" {
+			ctx = learn_golang_gorm.WithActor(ctx, actor)
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,9 @@
+// Package pb berisi message dan service gRPC hasil generate dari user.proto dan wallet.proto.
+// File *.pb.go jangan diubah manual, generate ulang setelah mengubah file .proto dengan:
+//
+//	go generate ./rpc/pb
+//
+// (membutuhkan protoc, protoc-gen-go dan protoc-gen-go-grpc di PATH)
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative user.proto wallet.proto
@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/internal/testapp"
+	"learn-golang-gorm/rpc/pb"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+)
+
+type testClient struct {
+	users   pb.UserServiceClient
+	wallets pb.WalletServiceClient
+	db      *gorm.DB
+}
+
+// newTestClient menjalankan server gRPC di atas bufconn (in-process, tanpa port TCP) dengan database
+// dari testapp.DB (sudah dimigrasi dan berisi fixture testdata/fixtures/<name>.yml)
+func newTestClient(t *testing.T, names ...string) *testClient {
+	t.Helper()
+	db := testapp.DB(t, names...)
+
+	lis := bufconn.Listen(1 << 20)
+	// bufconn tidak punya alamat IP, jadi TrustedPeers tidak bisa dipakai: semua client dianggap proxy
+	server := New(db, WithAuthenticator(func(ctx context.Context) (string, error) { return metadataActor(ctx), nil }))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &testClient{users: pb.NewUserServiceClient(conn), wallets: pb.NewWalletServiceClient(conn), db: db}
+}
+
+// assertCode memeriksa status gRPC dari err
+func assertCode(t *testing.T, code codes.Code, err error, msgAndArgs ...interface{}) bool {
+	t.Helper()
+	return assert.Equal(t, code, status.Code(err), msgAndArgs...)
+}
+
+func TestActorMetadataTrust(t *testing.T) {
+	call := func(authenticate Authenticator, addr net.Addr, header string) (string, error) {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+		if header != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(ActorMetadataKey, header))
+		}
+		res, err := actor(authenticate)(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			found, _ := learn_golang_gorm.ActorFromContext(ctx)
+			return found, nil
+		})
+		actorFound, _ := res.(string)
+		return actorFound, err
+	}
+	proxy := &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 5000}
+	client := &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 5000}
+
+	// default (tanpa WithAuthenticator) x-actor-id selalu ditolak
+	_, err := call(TrustedPeers(), proxy, "1")
+	assertCode(t, codes.Unauthenticated, err)
+	assert.Contains(t, status.Convert(err).Message(), "only accepted from a trusted proxy")
+	found, err := call(TrustedPeers(), client, "")
+	assert.Nil(t, err)
+	assert.Empty(t, found)
+
+	// hanya proxy di 10.0.0.0/8 yang boleh mengirimnya
+	behindProxy := TrustedPeers(netip.MustParsePrefix("10.0.0.0/8"))
+	found, err = call(behindProxy, proxy, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", found)
+	_, err = call(behindProxy, client, "1")
+	assertCode(t, codes.Unauthenticated, err)
+	_, err = call(behindProxy, bufconn.Listen(1).Addr(), "1")
+	assertCode(t, codes.Unauthenticated, err)
+}
@@ -0,0 +1,123 @@
+// Package rpc adalah API gRPC untuk service internal: UserService dan WalletService (lihat pb/*.proto),
+// di atas UserService, WalletService dan TransferService dari package utama.
+//
+//	lis, err := net.Listen("tcp", ":9090")
+//	err = rpc.New(db).Serve(lis)
+//
+// Error domain dan database diubah menjadi status gRPC: NotFound, AlreadyExists (duplicate key),
+// FailedPrecondition (foreign key, saldo tidak cukup, idempotency key dipakai ulang), InvalidArgument
+// (request, filter atau page_token yang tidak valid) dan Aborted (deadlock / lock wait timeout, boleh
+// dicoba lagi). Error lain menjadi Internal tanpa pesan aslinya.
+//
+// Metadata x-actor-id dipakai sebagai user yang tercatat di user_logs (lihat learn_golang_gorm.WithActor),
+// tetapi hanya dari peer yang dipercaya (WithAuthenticator dan TrustedPeers). Tanpa option tersebut
+// call yang membawa x-actor-id ditolak dengan Unauthenticated.
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/dberrors"
+	"learn-golang-gorm/filter"
+	"learn-golang-gorm/idempotency"
+	"learn-golang-gorm/internal/validate"
+	"learn-golang-gorm/money"
+	"learn-golang-gorm/pagination"
+	"learn-golang-gorm/rpc/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// ActorMetadataKey sama seperti header X-Actor-ID pada REST API (package server)
+const ActorMetadataKey = "x-actor-id"
+
+// New membuat server gRPC dengan UserService dan WalletService yang sudah didaftarkan
+func New(db *gorm.DB, opts ...Option) *grpc.Server {
+	o := options{authenticate: TrustedPeers()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	interceptors := grpc.ChainUnaryInterceptor(actor(o.authenticate), translateErrors(db))
+	server := grpc.NewServer(append([]grpc.ServerOption{interceptors}, o.server...)...)
+	pb.RegisterUserServiceServer(server, newUserServer(db))
+	pb.RegisterWalletServiceServer(server, newWalletServer(db))
+	return server
+}
+
+// translateErrors mengubah error dari handler menjadi status gRPC, error Internal dicatat ke logger GORM
+func translateErrors(db *gorm.DB) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		res, err := handler(ctx, req)
+		if err == nil {
+			return res, nil
+		}
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		code := statusCode(err)
+		if code == codes.Internal {
+			db.Logger.Error(ctx, "rpc %s: %v", info.FullMethod, err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		return nil, status.Error(code, err.Error())
+	}
+}
+
+func statusCode(err error) codes.Code {
+	err = dberrors.Translate(err)
+	switch {
+	case errors.Is(err, dberrors.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, dberrors.ErrDuplicateKey):
+		return codes.AlreadyExists
+	case errors.Is(err, dberrors.ErrForeignKeyViolation),
+		errors.Is(err, learn_golang_gorm.ErrInsufficientFunds),
+		errors.Is(err, learn_golang_gorm.ErrIdempotencyKeyConflict),
+		errors.Is(err, idempotency.ErrConflict),
+		errors.Is(err, money.ErrCurrencyMismatch):
+		return codes.FailedPrecondition
+	case errors.Is(err, dberrors.ErrCheckViolation),
+		errors.Is(err, dberrors.ErrDataTooLong),
+		errors.Is(err, filter.ErrInvalidFilter),
+		errors.Is(err, pagination.ErrInvalidCursor),
+		errors.Is(err, learn_golang_gorm.ErrInvalidAmount),
+		errors.Is(err, learn_golang_gorm.ErrSameWallet),
+		errors.Is(err, learn_golang_gorm.ErrMissingIdempotencyKey),
+		errors.Is(err, money.ErrOverflow):
+		return codes.InvalidArgument
+	case errors.Is(err, dberrors.ErrDeadlock), errors.Is(err, dberrors.ErrLockTimeout), errors.Is(err, dberrors.ErrSerialization):
+		return codes.Aborted
+	}
+	return codes.Internal
+}
+
+// validation mengumpulkan pesan error request (InvalidArgument), aturannya sama dengan validasi DTO di
+// package server (package validate)
+type validation struct {
+	validate.Errors
+}
+
+// money mengubah pb.Money menjadi money.Money, currency kosong berarti DefaultCurrency
+func (v *validation) money(field string, m *pb.Money) money.Money {
+	if m == nil {
+		v.Check(false, "%s is required", field)
+		return money.Money{}
+	}
+	return money.New(m.Amount, v.Currency(field+".currency", m.Currency))
+}
+
+func (v validation) err() error {
+	if len(v.Errors) == 0 {
+		return nil
+	}
+	return status.Error(codes.InvalidArgument, v.Message())
+}
+
+func newMoney(m money.Money) *pb.Money {
+	return &pb.Money{Amount: m.Amount, Currency: string(m.Currency), Display: m.String()}
+}
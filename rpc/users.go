@@ -0,0 +1,119 @@
+package rpc
+
+import (
+	"context"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/rpc/pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+type userServer struct {
+	pb.UnimplementedUserServiceServer
+	users *learn_golang_gorm.UserService
+}
+
+func newUserServer(db *gorm.DB) *userServer {
+	return &userServer{users: learn_golang_gorm.NewUserService(db)}
+}
+
+func (s *userServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.User, error) {
+	var v validation
+	v.MaxLength("id", req.Id, 100)
+	name := v.name("name", req.Name)
+	v.Required("password", req.Password)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	user := learn_golang_gorm.User{ID: req.Id, Name: name, Password: req.Password}
+	if err := s.users.Create(ctx, &user); err != nil {
+		return nil, err
+	}
+	return newUser(&user), nil
+}
+
+func (s *userServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	user, err := s.users.Get(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return newUser(user), nil
+}
+
+// UpdateUser: path "name" berarti name.first, name.middle dan name.last sekaligus
+func (s *userServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.User, error) {
+	var v validation
+	v.Check(len(req.UpdateMask.GetPaths()) > 0, "update_mask is required")
+	name := req.Name
+	if name == nil {
+		name = &pb.Name{}
+	}
+	var update learn_golang_gorm.UserUpdate
+	for _, path := range req.UpdateMask.GetPaths() {
+		switch path {
+		case "name":
+			v.name("name", name)
+			update.FirstName, update.MiddleName, update.LastName = &name.First, &name.Middle, &name.Last
+		case "name.first":
+			v.Required("name.first", name.First)
+			v.MaxLength("name.first", name.First, 255)
+			update.FirstName = &name.First
+		case "name.middle":
+			v.MaxLength("name.middle", name.Middle, 100)
+			update.MiddleName = &name.Middle
+		case "name.last":
+			v.MaxLength("name.last", name.Last, 100)
+			update.LastName = &name.Last
+		case "password":
+			v.Required("password", req.Password)
+			update.Password = &req.Password
+		default:
+			v.Check(false, "update_mask path %q is not supported", path)
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.Update(ctx, req.Id, update)
+	if err != nil {
+		return nil, err
+	}
+	return newUser(user), nil
+}
+
+func (s *userServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	page, err := s.users.List(ctx, req.Filter, int(req.PageSize), req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.ListUsersResponse{Users: make([]*pb.User, len(page.Items)), NextPageToken: page.Next}
+	for i := range page.Items {
+		res.Users[i] = newUser(&page.Items[i])
+	}
+	return res, nil
+}
+
+// name mengubah pb.Name menjadi Name (embedded di User) lalu memeriksanya
+func (v *validation) name(field string, n *pb.Name) learn_golang_gorm.Name {
+	name := learn_golang_gorm.Name{FirstName: n.GetFirst(), MiddleName: n.GetMiddle(), LastName: n.GetLast()}
+	v.Name(field, name)
+	return name
+}
+
+func newUser(u *learn_golang_gorm.User) *pb.User {
+	user := &pb.User{
+		Id:        u.ID,
+		Name:      &pb.Name{First: u.Name.FirstName, Middle: u.Name.MiddleName, Last: u.Name.LastName},
+		FullName:  u.Name.Full(),
+		CreatedAt: timestamppb.New(u.CreatedAt),
+		UpdatedAt: timestamppb.New(u.UpdatedAt),
+	}
+	if u.Wallet.ID != "" {
+		user.Wallet = newWallet(&u.Wallet)
+	}
+	return user
+}
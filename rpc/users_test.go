@@ -0,0 +1,124 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/rpc/pb"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestUserService(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, "users", "wallet_owners", "wallets")
+
+	user, err := client.users.GetUser(ctx, &pb.GetUserRequest{Id: "1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Lev Tempest Vex", user.FullName)
+	assert.Equal(t, "Tempest", user.Name.Middle)
+	assert.Equal(t, int64(1000000), user.Wallet.Balance.Amount)
+	assert.Equal(t, "IDR 1000000", user.Wallet.Balance.Display)
+
+	_, err = client.users.GetUser(ctx, &pb.GetUserRequest{Id: "404"})
+	assertCode(t, codes.NotFound, err)
+
+	// user baru tanpa wallet
+	user, err = client.users.CreateUser(ctx, &pb.CreateUserRequest{Id: "50", Name: &pb.Name{First: "Budi"}, Password: "rahasia"})
+	assert.Nil(t, err)
+	assert.Nil(t, user.Wallet)
+	var created learn_golang_gorm.User
+	assert.Nil(t, client.db.Take(&created, "id = ?", "50").Error)
+	assert.True(t, created.VerifyPassword("rahasia"))
+
+	_, err = client.users.CreateUser(ctx, &pb.CreateUserRequest{Id: "50", Name: &pb.Name{First: "Budi"}, Password: "rahasia"})
+	assertCode(t, codes.AlreadyExists, err)
+
+	_, err = client.users.CreateUser(ctx, &pb.CreateUserRequest{Name: &pb.Name{Last: strings.Repeat("x", 101)}})
+	assertCode(t, codes.InvalidArgument, err)
+	assert.Equal(t, "name.first is required; name.last must be at most 100 characters; password is required",
+		status.Convert(err).Message())
+
+	// sama seperti REST API, nilai yang hanya berisi spasi dianggap kosong
+	_, err = client.users.CreateUser(ctx, &pb.CreateUserRequest{Name: &pb.Name{First: "  "}, Password: " "})
+	assertCode(t, codes.InvalidArgument, err)
+	assert.Equal(t, "name.first is required; password is required", status.Convert(err).Message())
+
+	// update hanya path yang ada di update_mask, actor dari metadata
+	actorCtx := metadata.AppendToOutgoingContext(ctx, ActorMetadataKey, "1")
+	user, err = client.users.UpdateUser(actorCtx, &pb.UpdateUserRequest{
+		Id:         "50",
+		Name:       &pb.Name{First: "diabaikan", Last: "Santoso"},
+		Password:   "baru",
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name.last", "password"}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Budi Santoso", user.FullName)
+	assert.Nil(t, client.db.Take(&created, "id = ?", "50").Error)
+	assert.True(t, created.VerifyPassword("baru"))
+
+	var log learn_golang_gorm.UserLog
+	assert.Nil(t, client.db.Where("table_name = ? AND action = ?", "users", learn_golang_gorm.AuditUpdate).Last(&log).Error)
+	assert.Equal(t, "1", log.UserId)
+	assert.Equal(t, "50", log.RecordId)
+
+	// path "name" mengganti ketiga kolom nama
+	user, err = client.users.UpdateUser(ctx, &pb.UpdateUserRequest{
+		Id: "50", Name: &pb.Name{First: "Ani"}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Ani", user.FullName)
+
+	for _, req := range []*pb.UpdateUserRequest{
+		{Id: "50"}, // tanpa update_mask
+		{Id: "50", UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name.first"}}},
+		{Id: "50", UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"id"}}},
+	} {
+		_, err = client.users.UpdateUser(ctx, req)
+		assertCode(t, codes.InvalidArgument, err, req.String())
+	}
+	_, err = client.users.UpdateUser(ctx, &pb.UpdateUserRequest{
+		Id: "404", Password: "x", UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"password"}},
+	})
+	assertCode(t, codes.NotFound, err)
+}
+
+func TestListUsers(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, "users", "wallet_owners", "wallets")
+
+	ids := func(res *pb.ListUsersResponse) []string {
+		var ids []string
+		for _, user := range res.Users {
+			ids = append(ids, user.Id)
+		}
+		return ids
+	}
+
+	req := &pb.ListUsersRequest{Filter: "wallet.balance=gte:1000000&sort=-id", PageSize: 3}
+	res, err := client.users.ListUsers(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"21", "20", "2"}, ids(res))
+	assert.NotNil(t, res.Users[0].Wallet)
+
+	req.PageToken = res.NextPageToken
+	res, err = client.users.ListUsers(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1"}, ids(res))
+	assert.Empty(t, res.NextPageToken)
+
+	for _, req := range []*pb.ListUsersRequest{
+		{Filter: "password=secret"},
+		{Filter: "sort=-wallet.balance"}, // kolom relasi tidak bisa dipakai untuk cursor
+		{PageToken: "rusak"},
+	} {
+		_, err = client.users.ListUsers(ctx, req)
+		assertCode(t, codes.InvalidArgument, err, req.String())
+	}
+}
@@ -0,0 +1,111 @@
+package rpc
+
+import (
+	"context"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/money"
+	"learn-golang-gorm/rpc/pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+type walletServer struct {
+	pb.UnimplementedWalletServiceServer
+	wallets   *learn_golang_gorm.WalletService
+	transfers *learn_golang_gorm.TransferService
+}
+
+func newWalletServer(db *gorm.DB) *walletServer {
+	return &walletServer{
+		wallets:   learn_golang_gorm.NewWalletService(db),
+		transfers: learn_golang_gorm.NewTransferService(db),
+	}
+}
+
+func (s *walletServer) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb.Balance, error) {
+	balance, err := s.wallets.Balance(ctx, req.WalletId)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Balance{
+		WalletId:  balance.WalletID,
+		UserId:    balance.UserID,
+		Balance:   newMoney(balance.Balance),
+		Held:      newMoney(balance.Held),
+		Available: newMoney(balance.Available),
+	}, nil
+}
+
+// Transfer: amount harus positif dan mata uangnya sama dengan kedua wallet (lihat TransferService.Transfer)
+func (s *walletServer) Transfer(ctx context.Context, req *pb.TransferRequest) (*pb.TransferResponse, error) {
+	var v validation
+	v.Required("from_wallet_id", req.FromWalletId)
+	v.Required("to_wallet_id", req.ToWalletId)
+	amount := v.money("amount", req.Amount)
+	v.Required("idempotency_key", req.IdempotencyKey)
+	v.MaxLength("idempotency_key", req.IdempotencyKey, 100)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.transfers.Transfer(ctx, req.FromWalletId, req.ToWalletId, amount, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.TransferResponse{
+		TransferId: result.TransferID,
+		Debit:      newTransaction(&result.Debit, amount.Currency),
+		Credit:     newTransaction(&result.Credit, amount.Currency),
+		Replayed:   result.Replayed,
+	}, nil
+}
+
+func (s *walletServer) ListTransactions(ctx context.Context, req *pb.ListTransactionsRequest) (*pb.ListTransactionsResponse, error) {
+	balance, err := s.wallets.Balance(ctx, req.WalletId) // untuk mata uang wallet
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.wallets.Transactions(ctx, req.WalletId, int(req.PageSize), req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.ListTransactionsResponse{
+		Transactions:  make([]*pb.Transaction, len(page.Items)),
+		NextPageToken: page.Next,
+	}
+	for i := range page.Items {
+		res.Transactions[i] = newTransaction(&page.Items[i], balance.Balance.Currency)
+	}
+	return res, nil
+}
+
+func newWallet(w *learn_golang_gorm.Wallet) *pb.Wallet {
+	return &pb.Wallet{
+		Id:        w.ID,
+		UserId:    w.UserId,
+		Balance:   newMoney(w.Balance),
+		CreatedAt: timestamppb.New(w.CreatedAt),
+		UpdatedAt: timestamppb.New(w.UpdatedAt),
+	}
+}
+
+var transactionTypes = map[string]pb.TransactionType{
+	learn_golang_gorm.WalletTransactionDebit:  pb.TransactionType_TRANSACTION_TYPE_DEBIT,
+	learn_golang_gorm.WalletTransactionCredit: pb.TransactionType_TRANSACTION_TYPE_CREDIT,
+}
+
+// newTransaction: wallet_transactions tidak menyimpan mata uang, jadi diambil dari wallet-nya
+func newTransaction(t *learn_golang_gorm.WalletTransaction, currency money.Currency) *pb.Transaction {
+	return &pb.Transaction{
+		Id:                   t.ID,
+		TransferId:           t.TransferID,
+		WalletId:             t.WalletID,
+		CounterpartyWalletId: t.CounterpartyWalletID,
+		Type:                 transactionTypes[t.Type],
+		Amount:               newMoney(money.New(t.Amount, currency)),
+		BalanceAfter:         newMoney(money.New(t.BalanceAfter, currency)),
+		CreatedAt:            timestamppb.New(t.CreatedAt),
+	}
+}
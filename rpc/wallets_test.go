@@ -0,0 +1,87 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/money"
+	"learn-golang-gorm/rpc/pb"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWalletService(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, "users", "wallet_owners", "wallets")
+
+	_, err := learn_golang_gorm.NewHoldService(client.db).Hold(ctx, "1", money.New(100000, "IDR"), "order-1", time.Hour)
+	assert.Nil(t, err)
+	balance, err := client.wallets.GetBalance(ctx, &pb.GetBalanceRequest{WalletId: "1"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000000), balance.Balance.Amount)
+	assert.Equal(t, int64(100000), balance.Held.Amount)
+	assert.Equal(t, "IDR 900000", balance.Available.Display)
+	_, err = client.wallets.GetBalance(ctx, &pb.GetBalanceRequest{WalletId: "404"})
+	assertCode(t, codes.NotFound, err)
+
+	req := &pb.TransferRequest{FromWalletId: "1", ToWalletId: "2", Amount: &pb.Money{Amount: 250000}, IdempotencyKey: "trx-1"}
+	res, err := client.wallets.Transfer(ctx, req)
+	assert.Nil(t, err)
+	assert.False(t, res.Replayed)
+	assert.Equal(t, pb.TransactionType_TRANSACTION_TYPE_DEBIT, res.Debit.Type)
+	assert.Equal(t, int64(750000), res.Debit.BalanceAfter.Amount)
+	assert.Equal(t, "IDR 1250000", res.Credit.BalanceAfter.Display)
+
+	// retry dengan key yang sama tidak memindahkan saldo lagi
+	replay, err := client.wallets.Transfer(ctx, req)
+	assert.Nil(t, err)
+	assert.True(t, replay.Replayed)
+	assert.Equal(t, res.TransferId, replay.TransferId)
+
+	for code, req := range map[codes.Code]*pb.TransferRequest{
+		codes.FailedPrecondition: {FromWalletId: "1", ToWalletId: "2", Amount: &pb.Money{Amount: 10}, IdempotencyKey: "trx-1"},
+		codes.NotFound:           {FromWalletId: "1", ToWalletId: "404", Amount: &pb.Money{Amount: 10}, IdempotencyKey: "trx-2"},
+		codes.InvalidArgument:    {FromWalletId: "1", ToWalletId: "1", Amount: &pb.Money{Amount: 10}, IdempotencyKey: "trx-3"},
+	} {
+		_, err = client.wallets.Transfer(ctx, req)
+		assertCode(t, code, err, req.String())
+	}
+	_, err = client.wallets.Transfer(ctx, &pb.TransferRequest{FromWalletId: "1", ToWalletId: "2", Amount: &pb.Money{Amount: 800000}, IdempotencyKey: "trx-4"})
+	assertCode(t, codes.FailedPrecondition, err) // sisa 750000, 100000 di antaranya di-hold
+	_, err = client.wallets.Transfer(ctx, &pb.TransferRequest{FromWalletId: "1"})
+	assertCode(t, codes.InvalidArgument, err)
+	assert.Equal(t, "to_wallet_id is required; amount is required; idempotency_key is required", status.Convert(err).Message())
+
+	_, err = client.wallets.Transfer(ctx, &pb.TransferRequest{FromWalletId: "2", ToWalletId: "20", Amount: &pb.Money{Amount: 1000}, IdempotencyKey: "trx-5"})
+	assert.Nil(t, err)
+
+	// mutasi wallet 2, terbaru lebih dulu
+	list, err := client.wallets.ListTransactions(ctx, &pb.ListTransactionsRequest{WalletId: "2", PageSize: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(list.Transactions))
+	assert.Equal(t, pb.TransactionType_TRANSACTION_TYPE_DEBIT, list.Transactions[0].Type)
+	assert.Equal(t, "20", list.Transactions[0].CounterpartyWalletId)
+	list, err = client.wallets.ListTransactions(ctx, &pb.ListTransactionsRequest{WalletId: "2", PageSize: 1, PageToken: list.NextPageToken})
+	assert.Nil(t, err)
+	assert.Equal(t, pb.TransactionType_TRANSACTION_TYPE_CREDIT, list.Transactions[0].Type)
+	assert.Equal(t, "IDR 250000", list.Transactions[0].Amount.Display)
+	assert.Empty(t, list.NextPageToken)
+
+	_, err = client.wallets.ListTransactions(ctx, &pb.ListTransactionsRequest{WalletId: "404"})
+	assertCode(t, codes.NotFound, err)
+}
+
+func TestInternalErrors(t *testing.T) {
+	client := newTestClient(t)
+	sqlDB, _ := client.db.DB()
+	sqlDB.Close()
+
+	// error database lain tidak dikirim ke client
+	_, err := client.users.GetUser(context.Background(), &pb.GetUserRequest{Id: "1"})
+	assertCode(t, codes.Internal, err)
+	assert.Equal(t, "internal error", status.Convert(err).Message())
+}
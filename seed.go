@@ -0,0 +1,25 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"embed"
+
+	"learn-golang-gorm/fixtures"
+
+	"gorm.io/gorm"
+)
+
+//go:embed seed/*.yml
+var seedFiles embed.FS
+
+// Seed mengisi database dengan data contoh untuk development (lihat seed/data.yml).
+// Aman dijalankan berkali-kali karena data yang sudah ada dilewati.
+func Seed(ctx context.Context, db *gorm.DB) error {
+	loader, err := fixtures.New(db, Models()...)
+	if err != nil {
+		return err
+	}
+	loader.IgnoreExisting = true
+	_, err = loader.LoadFS(ctx, seedFiles, "seed/data.yml")
+	return err
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/netip"
+
+	"learn-golang-gorm/internal/trusted"
+)
+
+// ErrUntrustedActor dikembalikan jika header X-Actor-ID dikirim oleh alamat yang tidak dipercaya
+var ErrUntrustedActor = errors.New(ActorHeader + " is only accepted from a trusted proxy")
+
+// Authenticator menentukan user (actor) yang melakukan request, untuk audit log. Actor kosong berarti
+// request tanpa user, error membuat request ditolak dengan 401.
+type Authenticator func(r *http.Request) (actor string, err error)
+
+// Option mengubah konfigurasi Server, lihat New
+type Option func(*Server)
+
+// WithAuthenticator mengganti cara Server menentukan actor. Tanpa option ini header X-Actor-ID selalu
+// ditolak, karena siapa pun bisa mengisinya dan mengaku sebagai user lain di audit log.
+func WithAuthenticator(authenticate Authenticator) Option {
+	return func(s *Server) {
+		s.authenticate = authenticate
+	}
+}
+
+// TrustedProxies menerima header X-Actor-ID hanya dari request yang datang langsung dari proxies, misalnya
+// API gateway yang sudah memverifikasi token user lalu mengisi header ini (dan menghapus header dari client).
+// Header dari alamat lain ditolak dengan ErrUntrustedActor. Jangan pasang server ini di belakang proxy yang
+// meneruskan header X-Actor-ID dari client apa adanya.
+func TrustedProxies(proxies ...netip.Prefix) Authenticator {
+	return func(r *http.Request) (string, error) {
+		actor := r.Header.Get(ActorHeader)
+		if actor == "" || trusted.Addr(r.RemoteAddr, proxies) {
+			return actor, nil
+		}
+		return "", ErrUntrustedActor
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	learn_golang_gorm "learn-golang-gorm"
+)
+
+func (s *Server) listAddresses(w http.ResponseWriter, r *http.Request) error {
+	return list(w, r, s.addresses.DB(r.Context()), learn_golang_gorm.AddressFilter, newAddressResponse)
+}
+
+func (s *Server) createAddress(w http.ResponseWriter, r *http.Request) error {
+	var req CreateAddressRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+	if err := req.validate(); err != nil {
+		return err
+	}
+	if err := s.requireUser(r.Context(), req.UserID, http.StatusUnprocessableEntity); err != nil {
+		return err
+	}
+
+	address := learn_golang_gorm.Address{UserId: req.UserID, Address: req.Address}
+	if err := s.addresses.Create(r.Context(), &address); err != nil {
+		return err
+	}
+	w.Header().Set("Location", "/addresses/"+strconv.FormatInt(address.ID, 10))
+	return writeJSON(w, http.StatusCreated, newAddressResponse(&address))
+}
+
+func (s *Server) getAddress(w http.ResponseWriter, r *http.Request) error {
+	id, err := pathInt64(r)
+	if err != nil {
+		return err
+	}
+	address, err := s.addresses.FindByID(r.Context(), id)
+	if err != nil {
+		return notFound(err, "address", id)
+	}
+	return writeJSON(w, http.StatusOK, newAddressResponse(address))
+}
+
+func (s *Server) updateAddress(w http.ResponseWriter, r *http.Request) error {
+	id, err := pathInt64(r)
+	if err != nil {
+		return err
+	}
+	var req UpdateAddressRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+	columns, err := req.columns()
+	if err != nil {
+		return err
+	}
+	if len(columns) > 0 {
+		if err := s.addresses.Update(r.Context(), id, columns); err != nil {
+			return notFound(err, "address", id)
+		}
+	}
+	return s.getAddress(w, r)
+}
+
+func (s *Server) deleteAddress(w http.ResponseWriter, r *http.Request) error {
+	id, err := pathInt64(r)
+	if err != nil {
+		return err
+	}
+	if err := s.addresses.Delete(r.Context(), id); err != nil {
+		return notFound(err, "address", id)
+	}
+	return noContent(w)
+}
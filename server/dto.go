@@ -0,0 +1,368 @@
+package server
+
+import (
+	"net/mail"
+	"time"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/money"
+)
+
+// Request dan response API sengaja dipisah dari model GORM: Password tidak pernah ikut di response,
+// dan field yang diisi database (created_at, ledger, relasi) tidak bisa diubah lewat request.
+// Request Update* memakai pointer, field yang tidak dikirim tidak diubah (PATCH).
+//
+// Tag model:"<field>" menunjuk field model GORM-nya (lihat dtoModels di openapi.go): kolom, size,
+// primary key dan relasinya ikut ditulis ke openapi.json. Field dianggap wajib, kecuali pointer, omitempty
+// (bisa tidak ada di JSON) atau diberi tag openapi:"optional" (boleh kosong / tidak dikirim di request).
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// ===== money =====
+
+// Money adalah jumlah uang dalam minor unit beserta teksnya, misalnya {1250, "USD", "USD 12.50"}
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Display  string `json:"display"`
+}
+
+func newMoney(m money.Money) Money {
+	return Money{Amount: m.Amount, Currency: string(m.Currency), Display: m.String()}
+}
+
+// MoneyRequest adalah jumlah uang dalam minor unit, Currency kosong berarti DefaultCurrency
+type MoneyRequest struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+}
+
+// money memeriksa mata uang dan jumlahnya (tidak boleh negatif)
+func (v *validation) money(field string, m MoneyRequest) money.Money {
+	v.Check(m.Amount >= 0, "%s.amount must not be negative", field)
+	return money.New(m.Amount, v.Currency(field+".currency", m.Currency))
+}
+
+// ===== user =====
+
+type Name struct {
+	First  string `json:"first" model:"FirstName"`
+	Middle string `json:"middle" model:"MiddleName" openapi:"optional"`
+	Last   string `json:"last" model:"LastName" openapi:"optional"`
+}
+
+func (n Name) model() learn_golang_gorm.Name {
+	return learn_golang_gorm.Name{FirstName: n.First, MiddleName: n.Middle, LastName: n.Last}
+}
+
+type NamePatch struct {
+	First  *string `json:"first,omitempty" model:"FirstName"`
+	Middle *string `json:"middle,omitempty" model:"MiddleName"`
+	Last   *string `json:"last,omitempty" model:"LastName"`
+}
+
+type CreateUserRequest struct {
+	ID       string `json:"id,omitempty" model:"ID"` // kosong berarti dibuat oleh IDGenerators
+	Name     Name   `json:"name" model:"Name"`
+	Password string `json:"password"`
+}
+
+func (r *CreateUserRequest) validate() error {
+	var v validation
+	v.MaxLength("id", r.ID, 100)
+	v.Name("name", r.Name.model())
+	v.Required("password", r.Password)
+	return v.err()
+}
+
+type UpdateUserRequest struct {
+	Name     *NamePatch `json:"name,omitempty" model:"Name"`
+	Password *string    `json:"password,omitempty"`
+}
+
+// columns mengembalikan kolom yang diubah, password di-hash oleh User.BeforeSave
+func (r *UpdateUserRequest) columns() (map[string]interface{}, error) {
+	var v validation
+	columns := map[string]interface{}{}
+	if r.Name != nil {
+		if r.Name.First != nil {
+			v.Required("name.first", *r.Name.First)
+			v.MaxLength("name.first", *r.Name.First, 255)
+			columns["first_name"] = *r.Name.First
+		}
+		if r.Name.Middle != nil {
+			v.MaxLength("name.middle", *r.Name.Middle, 100)
+			columns["middle_name"] = *r.Name.Middle
+		}
+		if r.Name.Last != nil {
+			v.MaxLength("name.last", *r.Name.Last, 100)
+			columns["last_name"] = *r.Name.Last
+		}
+	}
+	if r.Password != nil {
+		v.Required("password", *r.Password)
+		columns["password"] = *r.Password
+	}
+	return columns, v.err()
+}
+
+// UserResponse tidak punya field password. Wallet hanya diisi jika user punya wallet.
+type UserResponse struct {
+	ID        string          `json:"id" model:"ID"`
+	Name      Name            `json:"name" model:"Name"`
+	FullName  string          `json:"full_name"`
+	Wallet    *WalletResponse `json:"wallet,omitempty" model:"Wallet"`
+	CreatedAt time.Time       `json:"created_at" model:"CreatedAt"`
+	UpdatedAt time.Time       `json:"updated_at" model:"UpdatedAt"`
+}
+
+func newUserResponse(u *learn_golang_gorm.User) UserResponse {
+	response := UserResponse{
+		ID:        u.ID,
+		Name:      Name{First: u.Name.FirstName, Middle: u.Name.MiddleName, Last: u.Name.LastName},
+		FullName:  u.Name.Full(),
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+	if u.Wallet.ID != "" {
+		wallet := newWalletResponse(&u.Wallet)
+		response.Wallet = &wallet
+	}
+	return response
+}
+
+// ===== wallet =====
+
+// CreateWalletRequest: wallet selalu dibuat dengan saldo 0. Saldo hanya bisa bertambah lewat top up / transfer
+// supaya setiap perubahan tercatat di ledger.
+type CreateWalletRequest struct {
+	UserID   string `json:"user_id" model:"UserId"`
+	Currency string `json:"currency" model:"balance_currency" openapi:"optional"` // kosong berarti DefaultCurrency
+}
+
+// validate mengembalikan saldo awal (0) dengan mata uang yang sudah dinormalisasi
+func (r *CreateWalletRequest) validate() (money.Money, error) {
+	var v validation
+	v.Required("user_id", r.UserID)
+	return money.New(0, v.Currency("currency", r.Currency)), v.err()
+}
+
+type WalletResponse struct {
+	ID        string    `json:"id" model:"ID"`
+	UserID    string    `json:"user_id" model:"UserId"`
+	Balance   Money     `json:"balance" model:"Balance"`
+	CreatedAt time.Time `json:"created_at" model:"CreatedAt"`
+	UpdatedAt time.Time `json:"updated_at" model:"UpdatedAt"`
+}
+
+func newWalletResponse(w *learn_golang_gorm.Wallet) WalletResponse {
+	return WalletResponse{ID: w.ID, UserID: w.UserId, Balance: newMoney(w.Balance), CreatedAt: w.CreatedAt, UpdatedAt: w.UpdatedAt}
+}
+
+// ===== address =====
+
+type CreateAddressRequest struct {
+	UserID  string `json:"user_id" model:"UserId"`
+	Address string `json:"address" model:"Address"`
+}
+
+func (r *CreateAddressRequest) validate() error {
+	var v validation
+	v.Required("user_id", r.UserID)
+	v.Required("address", r.Address)
+	v.MaxLength("address", r.Address, 100)
+	return v.err()
+}
+
+type UpdateAddressRequest struct {
+	Address *string `json:"address,omitempty" model:"Address"`
+}
+
+func (r *UpdateAddressRequest) columns() (map[string]interface{}, error) {
+	var v validation
+	columns := map[string]interface{}{}
+	if r.Address != nil {
+		v.Required("address", *r.Address)
+		v.MaxLength("address", *r.Address, 100)
+		columns["address"] = *r.Address
+	}
+	return columns, v.err()
+}
+
+type AddressResponse struct {
+	ID        int64     `json:"id" model:"ID"`
+	UserID    string    `json:"user_id" model:"UserId"`
+	Address   string    `json:"address" model:"Address"`
+	CreatedAt time.Time `json:"created_at" model:"CreatedAt"`
+	UpdatedAt time.Time `json:"updated_at" model:"UpdatedAt"`
+}
+
+func newAddressResponse(a *learn_golang_gorm.Address) AddressResponse {
+	return AddressResponse{ID: a.ID, UserID: a.UserId, Address: a.Address, CreatedAt: a.CreatedAt, UpdatedAt: a.UpdatedAt}
+}
+
+// ===== product =====
+
+type CreateProductRequest struct {
+	ID    string       `json:"id,omitempty" model:"ID"` // kosong berarti dibuat oleh IDGenerators
+	Name  string       `json:"name" model:"Name"`
+	Price MoneyRequest `json:"price" model:"Price" openapi:"optional"` // kosong berarti gratis
+}
+
+func (r *CreateProductRequest) validate() (money.Money, error) {
+	var v validation
+	v.MaxLength("id", r.ID, 100)
+	v.Required("name", r.Name)
+	v.MaxLength("name", r.Name, 100)
+	price := v.money("price", r.Price)
+	return price, v.err()
+}
+
+type UpdateProductRequest struct {
+	Name  *string       `json:"name,omitempty" model:"Name"`
+	Price *MoneyRequest `json:"price,omitempty" model:"Price"`
+}
+
+func (r *UpdateProductRequest) columns() (map[string]interface{}, error) {
+	var v validation
+	columns := map[string]interface{}{}
+	if r.Name != nil {
+		v.Required("name", *r.Name)
+		v.MaxLength("name", *r.Name, 100)
+		columns["name"] = *r.Name
+	}
+	if r.Price != nil {
+		columns["price"] = v.money("price", *r.Price)
+	}
+	return columns, v.err()
+}
+
+type ProductResponse struct {
+	ID        string    `json:"id" model:"ID"`
+	Name      string    `json:"name" model:"Name"`
+	Price     Money     `json:"price" model:"Price"`
+	Likes     *int64    `json:"likes,omitempty" model:"LikedByUsers"` // hanya diisi oleh GET /products/{id}
+	CreatedAt time.Time `json:"created_at" model:"CreatedAt"`
+	UpdatedAt time.Time `json:"updated_at" model:"UpdatedAt"`
+}
+
+func newProductResponse(p *learn_golang_gorm.Product) ProductResponse {
+	return ProductResponse{ID: p.ID, Name: p.Name, Price: newMoney(p.Price), CreatedAt: p.CreatedAt, UpdatedAt: p.UpdatedAt}
+}
+
+type LikeResponse struct {
+	ProductID string `json:"product_id"`
+	UserID    string `json:"user_id"`
+	Likes     int64  `json:"likes"` // jumlah user yang menyukai product
+}
+
+// ===== todo =====
+
+type CreateTodoRequest struct {
+	UserID      string `json:"user_id" model:"UserId"`
+	Title       string `json:"title" model:"Title"`
+	Description string `json:"description" model:"Description" openapi:"optional"`
+}
+
+func (r *CreateTodoRequest) validate() error {
+	var v validation
+	v.Required("user_id", r.UserID)
+	v.Required("title", r.Title)
+	v.MaxLength("title", r.Title, 100)
+	return v.err()
+}
+
+type UpdateTodoRequest struct {
+	Title       *string `json:"title,omitempty" model:"Title"`
+	Description *string `json:"description,omitempty" model:"Description"`
+}
+
+func (r *UpdateTodoRequest) columns() (map[string]interface{}, error) {
+	var v validation
+	columns := map[string]interface{}{}
+	if r.Title != nil {
+		v.Required("title", *r.Title)
+		v.MaxLength("title", *r.Title, 100)
+		columns["title"] = *r.Title
+	}
+	if r.Description != nil {
+		columns["description"] = *r.Description
+	}
+	return columns, v.err()
+}
+
+type TodoResponse struct {
+	ID          uint      `json:"id" model:"ID"`
+	UserID      string    `json:"user_id" model:"UserId"`
+	Title       string    `json:"title" model:"Title"`
+	Description string    `json:"description" model:"Description"`
+	CreatedAt   time.Time `json:"created_at" model:"CreatedAt"`
+	UpdatedAt   time.Time `json:"updated_at" model:"UpdatedAt"`
+}
+
+func newTodoResponse(t *learn_golang_gorm.Todo) TodoResponse {
+	return TodoResponse{ID: t.ID, UserID: t.UserId, Title: t.Title, Description: t.Description, CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt}
+}
+
+// ===== guest book =====
+
+type CreateGuestBookRequest struct {
+	Name    string `json:"name" model:"Name"`
+	Email   string `json:"email" model:"Email"`
+	Message string `json:"message" model:"Message"`
+}
+
+func (v *validation) email(field, value string) {
+	_, err := mail.ParseAddress(value)
+	v.Check(err == nil, "%s %q is not a valid email address", field, value)
+	v.MaxLength(field, value, 100)
+}
+
+func (r *CreateGuestBookRequest) validate() error {
+	var v validation
+	v.Required("name", r.Name)
+	v.MaxLength("name", r.Name, 100)
+	v.email("email", r.Email)
+	v.Required("message", r.Message)
+	return v.err()
+}
+
+type UpdateGuestBookRequest struct {
+	Name    *string `json:"name,omitempty" model:"Name"`
+	Email   *string `json:"email,omitempty" model:"Email"`
+	Message *string `json:"message,omitempty" model:"Message"`
+}
+
+func (r *UpdateGuestBookRequest) columns() (map[string]interface{}, error) {
+	var v validation
+	columns := map[string]interface{}{}
+	if r.Name != nil {
+		v.Required("name", *r.Name)
+		v.MaxLength("name", *r.Name, 100)
+		columns["name"] = *r.Name
+	}
+	if r.Email != nil {
+		v.email("email", *r.Email)
+		columns["email"] = *r.Email
+	}
+	if r.Message != nil {
+		v.Required("message", *r.Message)
+		columns["message"] = *r.Message
+	}
+	return columns, v.err()
+}
+
+type GuestBookResponse struct {
+	ID        int64     `json:"id" model:"ID"`
+	Name      string    `json:"name" model:"Name"`
+	Email     string    `json:"email" model:"Email"`
+	Message   string    `json:"message" model:"Message"`
+	CreatedAt time.Time `json:"created_at" model:"CreatedAt"`
+	UpdatedAt time.Time `json:"updated_at" model:"UpdatedAt"`
+}
+
+func newGuestBookResponse(g *learn_golang_gorm.GuestBook) GuestBookResponse {
+	return GuestBookResponse{ID: g.ID, Name: g.Name, Email: g.Email, Message: g.Message, CreatedAt: g.CreatedAt, UpdatedAt: g.UpdatedAt}
+}
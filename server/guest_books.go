@@ -0,0 +1,73 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	learn_golang_gorm "learn-golang-gorm"
+)
+
+func (s *Server) listGuestBooks(w http.ResponseWriter, r *http.Request) error {
+	return list(w, r, s.guestBooks.DB(r.Context()), learn_golang_gorm.GuestBookFilter, newGuestBookResponse)
+}
+
+func (s *Server) createGuestBook(w http.ResponseWriter, r *http.Request) error {
+	var req CreateGuestBookRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+	if err := req.validate(); err != nil {
+		return err
+	}
+
+	guestBook := learn_golang_gorm.GuestBook{Name: req.Name, Email: req.Email, Message: req.Message}
+	if err := s.guestBooks.Create(r.Context(), &guestBook); err != nil {
+		return err
+	}
+	w.Header().Set("Location", "/guest-books/"+strconv.FormatInt(guestBook.ID, 10))
+	return writeJSON(w, http.StatusCreated, newGuestBookResponse(&guestBook))
+}
+
+func (s *Server) getGuestBook(w http.ResponseWriter, r *http.Request) error {
+	id, err := pathInt64(r)
+	if err != nil {
+		return err
+	}
+	guestBook, err := s.guestBooks.FindByID(r.Context(), id)
+	if err != nil {
+		return notFound(err, "guest book", id)
+	}
+	return writeJSON(w, http.StatusOK, newGuestBookResponse(guestBook))
+}
+
+func (s *Server) updateGuestBook(w http.ResponseWriter, r *http.Request) error {
+	id, err := pathInt64(r)
+	if err != nil {
+		return err
+	}
+	var req UpdateGuestBookRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+	columns, err := req.columns()
+	if err != nil {
+		return err
+	}
+	if len(columns) > 0 {
+		if err := s.guestBooks.Update(r.Context(), id, columns); err != nil {
+			return notFound(err, "guest book", id)
+		}
+	}
+	return s.getGuestBook(w, r)
+}
+
+func (s *Server) deleteGuestBook(w http.ResponseWriter, r *http.Request) error {
+	id, err := pathInt64(r)
+	if err != nil {
+		return err
+	}
+	if err := s.guestBooks.Delete(r.Context(), id); err != nil {
+		return notFound(err, "guest book", id)
+	}
+	return noContent(w)
+}
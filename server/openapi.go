@@ -0,0 +1,497 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/filter"
+	"learn-golang-gorm/pagination"
+
+	"gorm.io/gorm/schema"
+)
+
+// openapi.json adalah hasil OpenAPI() yang di-commit dan dikirim oleh GET /openapi.json.
+// TestOpenAPI gagal jika isinya berbeda dengan kode, tulis ulang dengan:
+//
+//	go test ./server -run TestOpenAPI -update
+//
+//go:embed openapi.json
+var openAPIJSON []byte
+
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIJSON)
+}
+
+// dtoModels menghubungkan DTO dengan model GORM-nya, dipakai oleh tag model:"<field>" di dto.go
+var dtoModels = map[reflect.Type]interface{}{
+	reflect.TypeOf(Name{}):                   &learn_golang_gorm.User{},
+	reflect.TypeOf(NamePatch{}):              &learn_golang_gorm.User{},
+	reflect.TypeOf(CreateUserRequest{}):      &learn_golang_gorm.User{},
+	reflect.TypeOf(UpdateUserRequest{}):      &learn_golang_gorm.User{},
+	reflect.TypeOf(UserResponse{}):           &learn_golang_gorm.User{},
+	reflect.TypeOf(CreateWalletRequest{}):    &learn_golang_gorm.Wallet{},
+	reflect.TypeOf(WalletResponse{}):         &learn_golang_gorm.Wallet{},
+	reflect.TypeOf(CreateAddressRequest{}):   &learn_golang_gorm.Address{},
+	reflect.TypeOf(UpdateAddressRequest{}):   &learn_golang_gorm.Address{},
+	reflect.TypeOf(AddressResponse{}):        &learn_golang_gorm.Address{},
+	reflect.TypeOf(CreateProductRequest{}):   &learn_golang_gorm.Product{},
+	reflect.TypeOf(UpdateProductRequest{}):   &learn_golang_gorm.Product{},
+	reflect.TypeOf(ProductResponse{}):        &learn_golang_gorm.Product{},
+	reflect.TypeOf(CreateTodoRequest{}):      &learn_golang_gorm.Todo{},
+	reflect.TypeOf(UpdateTodoRequest{}):      &learn_golang_gorm.Todo{},
+	reflect.TypeOf(TodoResponse{}):           &learn_golang_gorm.Todo{},
+	reflect.TypeOf(CreateGuestBookRequest{}): &learn_golang_gorm.GuestBook{},
+	reflect.TypeOf(UpdateGuestBookRequest{}): &learn_golang_gorm.GuestBook{},
+	reflect.TypeOf(GuestBookResponse{}):      &learn_golang_gorm.GuestBook{},
+}
+
+// pathModels menentukan tipe parameter path dari primary key model: {id} milik segmen pertama
+// path (/users/{id}), {user_id} milik users
+var pathModels = map[string]interface{}{
+	"users":       &learn_golang_gorm.User{},
+	"wallets":     &learn_golang_gorm.Wallet{},
+	"addresses":   &learn_golang_gorm.Address{},
+	"products":    &learn_golang_gorm.Product{},
+	"todos":       &learn_golang_gorm.Todo{},
+	"guest-books": &learn_golang_gorm.GuestBook{},
+}
+
+// ===== dokumen OpenAPI 3, hanya bagian yang dipakai =====
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `json:"schemas"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Parameters  []openAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref         string                    `json:"$ref,omitempty"`
+	AllOf       []*openAPISchema          `json:"allOf,omitempty"`
+	Type        string                    `json:"type,omitempty"`
+	Format      string                    `json:"format,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Properties  map[string]*openAPISchema `json:"properties,omitempty"`
+	Required    []string                  `json:"required,omitempty"`
+	Items       *openAPISchema            `json:"items,omitempty"`
+	MaxLength   int                       `json:"maxLength,omitempty"`
+	Minimum     *int                      `json:"minimum,omitempty"`
+	Maximum     *int                      `json:"maximum,omitempty"`
+	ReadOnly    bool                      `json:"readOnly,omitempty"`
+}
+
+// ===== generator =====
+
+// OpenAPI membuat dokumen OpenAPI 3 dari tabel routes, DTO di dto.go dan model GORM-nya (tipe kolom,
+// size, primary key, relasi dan struct embedded seperti Name). Hasilnya sama dengan openapi.json.
+func (s *Server) OpenAPI() ([]byte, error) {
+	g := &openAPIGenerator{
+		namer:   s.db.NamingStrategy,
+		schemas: map[string]*openAPISchema{},
+	}
+	doc := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:       "learn-golang-gorm REST API",
+			Description: "Dibuat oleh Server.OpenAPI dari DTO dan model GORM, lihat server/openapi.go.",
+			Version:     "1.0.0",
+		},
+		Paths:      map[string]map[string]*openAPIOperation{},
+		Components: openAPIComponents{Schemas: g.schemas},
+	}
+	for _, route := range s.routes() {
+		operation, err := g.operation(route)
+		if err != nil {
+			return nil, fmt.Errorf("server: openapi %s %s: %w", route.method, route.path, err)
+		}
+		if doc.Paths[route.path] == nil {
+			doc.Paths[route.path] = map[string]*openAPIOperation{}
+		}
+		doc.Paths[route.path][strings.ToLower(route.method)] = operation
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+type openAPIGenerator struct {
+	namer   schema.Namer
+	cache   sync.Map
+	schemas map[string]*openAPISchema
+}
+
+func (g *openAPIGenerator) operation(route route) (*openAPIOperation, error) {
+	operation := &openAPIOperation{
+		OperationID: handlerName(route.handler),
+		Summary:     route.summary,
+		Responses:   map[string]*openAPIResponse{},
+	}
+
+	// parameter path, misalnya {id} dan {user_id}
+	segments := strings.Split(strings.Trim(route.path, "/"), "/")
+	for _, segment := range segments {
+		if !strings.HasPrefix(segment, "{") {
+			continue
+		}
+		name := strings.Trim(segment, "{}")
+		resource := segments[0]
+		if name != "id" {
+			resource = strings.TrimSuffix(name, "_id") + "s"
+		}
+		param, err := g.pathParameter(name, resource)
+		if err != nil {
+			return nil, err
+		}
+		operation.Parameters = append(operation.Parameters, param)
+	}
+
+	if route.method != http.MethodGet {
+		operation.Parameters = append(operation.Parameters, openAPIParameter{
+			Name: ActorHeader, In: "header", Description: "user yang dicatat di audit log (WithActor), hanya diterima dari proxy terpercaya (TrustedProxies)",
+			Schema: &openAPISchema{Type: "string"},
+		})
+	}
+	for _, header := range route.headers {
+		operation.Parameters = append(operation.Parameters, openAPIParameter{
+			Name: header, In: "header", Description: headerDescriptions[header],
+			Schema: &openAPISchema{Type: "string", MaxLength: 100},
+		})
+	}
+	if route.filter != nil {
+		operation.Parameters = append(operation.Parameters, listParameters(*route.filter)...)
+	}
+
+	if route.request != nil {
+		body, err := g.schemaOf(reflect.TypeOf(route.request))
+		if err != nil {
+			return nil, err
+		}
+		operation.RequestBody = &openAPIRequestBody{Required: true, Content: jsonContent(body)}
+	}
+
+	success := &openAPIResponse{Description: http.StatusText(route.status)}
+	if route.response != nil {
+		body, err := g.schemaOf(reflect.TypeOf(route.response))
+		if err != nil {
+			return nil, err
+		}
+		success.Content = jsonContent(body)
+	}
+	operation.Responses[fmt.Sprint(route.status)] = success
+
+	errorBody, err := g.schemaOf(reflect.TypeOf(ErrorResponse{}))
+	if err != nil {
+		return nil, err
+	}
+	operation.Responses["default"] = &openAPIResponse{
+		Description: "400 request / filter / cursor salah, 404 tidak ada, 409 duplicate atau foreign key, 422 tidak valid",
+		Content:     jsonContent(errorBody),
+	}
+	return operation, nil
+}
+
+var headerDescriptions = map[string]string{
+	IdempotencyKeyHeader: "request dengan key yang sama mengembalikan hasil yang sama (header Idempotent-Replayed: true)",
+}
+
+func (g *openAPIGenerator) pathParameter(name, resource string) (openAPIParameter, error) {
+	model, ok := pathModels[resource]
+	if !ok {
+		return openAPIParameter{}, fmt.Errorf("no model for path parameter %q", name)
+	}
+	sch, err := g.parse(model)
+	if err != nil {
+		return openAPIParameter{}, err
+	}
+	field := sch.PrioritizedPrimaryField
+	if field == nil {
+		return openAPIParameter{}, fmt.Errorf("%s has no primary key", sch.Name)
+	}
+	return openAPIParameter{
+		Name: name, In: "path", Required: true,
+		Description: "primary key " + sch.Table + "." + field.DBName,
+		Schema:      columnSchema(field),
+	}, nil
+}
+
+// listParameters menjelaskan parameter pagination dan filter endpoint list (lihat list)
+func listParameters(spec filter.Spec) []openAPIParameter {
+	minPage, minSize, maxSize := 1, 1, pagination.MaxSize
+	params := []openAPIParameter{
+		{Name: "page", In: "query", Description: "halaman (offset), mulai dari 1",
+			Schema: &openAPISchema{Type: "integer", Minimum: &minPage}},
+		{Name: "size", In: "query", Description: fmt.Sprintf("jumlah item per halaman, default %d", pagination.DefaultSize),
+			Schema: &openAPISchema{Type: "integer", Minimum: &minSize, Maximum: &maxSize}},
+		{Name: "after", In: "query", Description: "next_cursor halaman sebelumnya, kosong untuk halaman pertama (cursor)",
+			Schema: &openAPISchema{Type: "string"}},
+		{Name: "before", In: "query", Description: "prev_cursor halaman sesudahnya (cursor)",
+			Schema: &openAPISchema{Type: "string"}},
+	}
+
+	names := make([]string, 0, len(spec.Fields))
+	var sortable []string
+	for name, field := range spec.Fields {
+		names = append(names, name)
+		if field.Sortable {
+			sortable = append(sortable, name)
+		}
+	}
+	slices.Sort(names)
+	slices.Sort(sortable)
+	params = append(params, openAPIParameter{
+		Name: filter.SortKey, In: "query",
+		Description: "field dipisah koma, awalan - berarti DESC: " + strings.Join(sortable, ", "),
+		Schema:      &openAPISchema{Type: "string"},
+	})
+	for _, name := range names {
+		var ops []string
+		for _, op := range spec.Fields[name].Operators() {
+			ops = append(ops, string(op))
+		}
+		params = append(params, openAPIParameter{
+			Name: name, In: "query",
+			Description: fmt.Sprintf("operator:nilai (%s), tanpa operator berarti eq", strings.Join(ops, ", ")),
+			Schema:      &openAPISchema{Type: "string"},
+		})
+	}
+	return params
+}
+
+func jsonContent(schema *openAPISchema) map[string]openAPIMediaType {
+	return map[string]openAPIMediaType{"application/json": {Schema: schema}}
+}
+
+// handlerName mengambil nama method handler, misalnya "listUsers" dari (*Server).listUsers-fm
+func handlerName(h handlerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+	name = name[strings.LastIndex(name, ".")+1:]
+	return strings.TrimSuffix(name, "-fm")
+}
+
+func (g *openAPIGenerator) parse(model interface{}) (*schema.Schema, error) {
+	return schema.Parse(model, &g.cache, g.namer)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf mengembalikan schema untuk tipe Go, struct didaftarkan ke components dan dirujuk dengan $ref
+func (g *openAPIGenerator) schemaOf(t reflect.Type) (*openAPISchema, error) {
+	switch {
+	case t == timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}, nil
+	case t.Kind() == reflect.Pointer:
+		return g.schemaOf(t.Elem())
+	case t.Kind() == reflect.Slice:
+		items, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &openAPISchema{Type: "array", Items: items}, nil
+	case t.Kind() == reflect.Struct:
+		name := componentName(t)
+		if _, ok := g.schemas[name]; !ok {
+			g.schemas[name] = nil // tanda sedang dibuat, untuk tipe yang merujuk dirinya sendiri
+			component, err := g.structSchema(t)
+			if err != nil {
+				return nil, err
+			}
+			g.schemas[name] = component
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + name}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &openAPISchema{Type: "string"}, nil
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openAPISchema{Type: "integer", Format: "int32"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number"}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// componentName: nama tipe Go, tipe generic pagination.Page[UserResponse] menjadi UserResponsePage
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.Index(name, "["); i >= 0 {
+		arg := strings.TrimSuffix(name[i+1:], "]")
+		name = arg[strings.LastIndex(arg, ".")+1:] + name[:i]
+	}
+	return name
+}
+
+func (g *openAPIGenerator) structSchema(t reflect.Type) (*openAPISchema, error) {
+	var sch *schema.Schema
+	if model, ok := dtoModels[t]; ok {
+		var err error
+		if sch, err = g.parse(model); err != nil {
+			return nil, err
+		}
+	}
+
+	component := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property, err := g.schemaOf(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+		if modelField := field.Tag.Get("model"); modelField != "" {
+			if sch == nil {
+				return nil, fmt.Errorf("%s.%s: no model registered in dtoModels", t.Name(), field.Name)
+			}
+			if property, err = describe(property, sch, modelField); err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+			}
+		}
+		component.Properties[name] = property
+		if !optional(field, options) {
+			component.Required = append(component.Required, name)
+		}
+	}
+	return component, nil
+}
+
+// optional: pointer dan omitempty bisa tidak ada di JSON, openapi:"optional" untuk field request yang boleh
+// tidak dikirim tetapi tetap selalu ada di response (misalnya Name.Middle)
+func optional(field reflect.StructField, jsonOptions string) bool {
+	return field.Type.Kind() == reflect.Pointer || slices.Contains(strings.Split(jsonOptions, ","), "omitempty") ||
+		field.Tag.Get("openapi") == "optional"
+}
+
+// describe melengkapi property dari field model: relasi, struct embedded atau kolom biasa
+func describe(property *openAPISchema, sch *schema.Schema, name string) (*openAPISchema, error) {
+	if rel, ok := sch.Relationships.Relations[name]; ok {
+		description := fmt.Sprintf("relasi %s %s ke %s", rel.Type, name, rel.FieldSchema.Table)
+		if rel.JoinTable != nil {
+			description += " lewat " + rel.JoinTable.Table
+		}
+		return withDescription(property, description), nil
+	}
+
+	// struct embedded, misalnya User.Name: kolom-kolomnya ada di tabel model itu sendiri
+	var columns []string
+	for _, field := range sch.Fields {
+		if len(field.BindNames) > 1 && field.BindNames[0] == name && field.DBName != "" {
+			columns = append(columns, sch.Table+"."+field.DBName)
+		}
+	}
+	if len(columns) > 0 {
+		return withDescription(property, "embedded "+name+": "+strings.Join(columns, ", ")), nil
+	}
+
+	field := sch.LookUpField(name)
+	if field == nil || field.DBName == "" {
+		return nil, fmt.Errorf("%s has no field %s", sch.Name, name)
+	}
+	column := columnSchema(field)
+	// DTO object untuk kolom serializer (misalnya Product.Price) tidak dibandingkan
+	mismatch := column.Type != property.Type || column.Format == "date-time" && property.Format != column.Format
+	if property.Ref == "" && column.Type != "" && mismatch {
+		return nil, fmt.Errorf("%s is %s but column %s.%s is %s", name, property.Type, sch.Table, field.DBName, field.DataType)
+	}
+	description := "kolom " + sch.Table + "." + field.DBName
+	if field.PrimaryKey {
+		description += " (primary key)"
+	}
+	if property.Ref != "" {
+		return withDescription(property, description), nil
+	}
+	property.Description = description
+	if property.Type == "string" && property.Format == "" {
+		property.MaxLength = field.Size
+	}
+	property.ReadOnly = field.AutoIncrement || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0
+	return property, nil
+}
+
+// withDescription: property $ref dibungkus allOf, karena OpenAPI 3.0 mengabaikan field lain di samping $ref
+func withDescription(property *openAPISchema, description string) *openAPISchema {
+	if property.Ref != "" {
+		return &openAPISchema{AllOf: []*openAPISchema{property}, Description: description}
+	}
+	property.Description = description
+	return property
+}
+
+// columnSchema mengubah tipe kolom GORM menjadi schema, kosong untuk tipe lain (misalnya serializer)
+func columnSchema(field *schema.Field) *openAPISchema {
+	switch field.DataType {
+	case schema.String:
+		return &openAPISchema{Type: "string", MaxLength: field.Size}
+	case schema.Int, schema.Uint:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case schema.Float:
+		return &openAPISchema{Type: "number"}
+	case schema.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case schema.Time:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	}
+	return &openAPISchema{}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "tulis ulang openapi.json dari kode")
+
+// TestOpenAPI gagal jika openapi.json tidak sama dengan hasil OpenAPI(), misalnya setelah DTO, route
+// atau gorm tag model berubah. Jalankan dengan -update untuk menulis ulang openapi.json.
+func TestOpenAPI(t *testing.T) {
+	server, db := newTestServer(t)
+	generated, err := New(db).OpenAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile("openapi.json", generated, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Skip("openapi.json ditulis ulang")
+	}
+	if string(generated) != string(openAPIJSON) {
+		t.Fatal("openapi.json tidak sama dengan kode, jalankan: go test ./server -run TestOpenAPI -update")
+	}
+
+	res := call(t, server, "GET", "/openapi.json", nil)
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Equal(t, "application/json", res.header.Get("Content-Type"))
+	assert.Equal(t, string(openAPIJSON), string(res.body))
+
+	var doc openAPIDocument
+	assert.Nil(t, json.Unmarshal(res.body, &doc))
+	schemas := doc.Components.Schemas
+
+	// tipe, kolom dan size dari gorm tag model
+	user := schemas["UserResponse"]
+	assert.Equal(t, []string{"id", "name", "full_name", "created_at", "updated_at"}, user.Required)
+	assert.Equal(t, &openAPISchema{Type: "string", Description: "kolom users.id (primary key)", MaxLength: 100}, user.Properties["id"])
+	assert.Equal(t, "relasi has_one Wallet ke wallets", user.Properties["wallet"].Description)
+	assert.Equal(t, "#/components/schemas/WalletResponse", user.Properties["wallet"].AllOf[0].Ref)
+	assert.Equal(t, "embedded Name: users.first_name, users.middle_name, users.last_name", user.Properties["name"].Description)
+	assert.True(t, user.Properties["created_at"].ReadOnly)
+	assert.Equal(t, 255, schemas["Name"].Properties["first"].MaxLength)
+	assert.Equal(t, []string{"first"}, schemas["Name"].Required) // openapi:"optional", bukan omitempty
+	assert.Equal(t, []string{"user_id"}, schemas["CreateWalletRequest"].Required)
+	assert.Equal(t, "relasi many_to_many LikedByUsers ke users lewat user_like_product", schemas["ProductResponse"].Properties["likes"].Description)
+	assert.Equal(t, &openAPISchema{Type: "integer", Format: "int64", Description: "kolom todos.id (primary key)", ReadOnly: true},
+		schemas["TodoResponse"].Properties["id"])
+	assert.NotContains(t, schemas["UserResponse"].Properties, "password")
+
+	// route: parameter path dari primary key, filter dari filter.Spec
+	like := doc.Paths["/products/{id}/likes/{user_id}"]["put"]
+	assert.Equal(t, "likeProduct", like.OperationID)
+	var names []string
+	for _, param := range like.Parameters {
+		names = append(names, param.In+":"+param.Name)
+	}
+	assert.Equal(t, []string{"path:id", "path:user_id", "header:X-Actor-ID", "header:Idempotency-Key"}, names)
+	assert.Equal(t, "integer", doc.Paths["/addresses/{id}"]["get"].Parameters[0].Schema.Type)
+
+	list := doc.Paths["/users"]["get"]
+	assert.Equal(t, "#/components/schemas/UserResponsePage", list.Responses["200"].Content["application/json"].Schema.Ref)
+	var balance *openAPIParameter
+	for i, param := range list.Parameters {
+		if param.Name == "wallet.balance" {
+			balance = &list.Parameters[i]
+		}
+	}
+	if assert.NotNil(t, balance) {
+		assert.True(t, strings.Contains(balance.Description, "gte"))
+	}
+
+	// setiap route terdaftar di dokumen
+	for _, route := range New(db).routes() {
+		assert.Contains(t, doc.Paths[route.path], strings.ToLower(route.method), route.path)
+	}
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	learn_golang_gorm "learn-golang-gorm"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyHeader dipakai oleh PUT /products/{id}/likes/{user_id} (lihat ProductService.Like)
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+func (s *Server) listProducts(w http.ResponseWriter, r *http.Request) error {
+	return list(w, r, s.products.DB(r.Context()), learn_golang_gorm.ProductFilter, newProductResponse)
+}
+
+func (s *Server) createProduct(w http.ResponseWriter, r *http.Request) error {
+	var req CreateProductRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+	price, err := req.validate()
+	if err != nil {
+		return err
+	}
+
+	product := learn_golang_gorm.Product{ID: req.ID, Name: req.Name, Price: price}
+	if err := s.products.Create(r.Context(), &product); err != nil {
+		return err
+	}
+	w.Header().Set("Location", "/products/"+product.ID)
+	return writeJSON(w, http.StatusCreated, newProductResponse(&product))
+}
+
+// getProduct juga mengembalikan jumlah like
+func (s *Server) getProduct(w http.ResponseWriter, r *http.Request) error {
+	product, err := s.products.FindByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return notFound(err, "product", r.PathValue("id"))
+	}
+	association := s.db.WithContext(r.Context()).Model(product).Association("LikedByUsers")
+	likes := association.Count()
+	if association.Error != nil {
+		return association.Error
+	}
+
+	response := newProductResponse(product)
+	response.Likes = &likes
+	return writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) updateProduct(w http.ResponseWriter, r *http.Request) error {
+	var req UpdateProductRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+	columns, err := req.columns()
+	if err != nil {
+		return err
+	}
+	id := r.PathValue("id")
+	if len(columns) > 0 {
+		if err := s.products.Update(r.Context(), id, columns); err != nil {
+			return notFound(err, "product", id)
+		}
+	}
+	return s.getProduct(w, r)
+}
+
+// deleteProduct ikut menghapus like product tersebut (baris user_like_product), tetapi tidak menghapus user-nya
+func (s *Server) deleteProduct(w http.ResponseWriter, r *http.Request) error {
+	id := r.PathValue("id")
+	err := s.db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+		products := learn_golang_gorm.NewProductRepository(tx)
+		product, err := products.FindByID(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(product).Association("LikedByUsers").Clear(); err != nil {
+			return err
+		}
+		return products.Delete(r.Context(), id)
+	})
+	if err != nil {
+		return notFound(err, "product", id)
+	}
+	return noContent(w)
+}
+
+// listProductLikes mengembalikan user yang menyukai product (relasi Product.LikedByUsers)
+func (s *Server) listProductLikes(w http.ResponseWriter, r *http.Request) error {
+	id := r.PathValue("id")
+	exists, err := s.products.Exists(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &httpError{status: http.StatusNotFound, message: "product " + id + " not found"}
+	}
+	users, err := s.users.FindLikers(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, responses(users, newUserResponse))
+}
+
+// likeProduct idempotent: like yang sudah ada tidak menambah jumlah like. Request yang diulang dengan
+// header Idempotency-Key yang sama mengembalikan hasil yang sama dengan header Idempotent-Replayed: true.
+// Tanpa header, setiap request memakai key baru.
+func (s *Server) likeProduct(w http.ResponseWriter, r *http.Request) error {
+	key := r.Header.Get(IdempotencyKeyHeader)
+	switch {
+	case key == "":
+		key = randomKey()
+	case len(key) > 100:
+		return badRequest("%s must be at most 100 characters", IdempotencyKeyHeader)
+	}
+	result, err := s.likes.Like(r.Context(), r.PathValue("id"), r.PathValue("user_id"), key)
+	if err != nil {
+		return err
+	}
+	if result.Replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	return writeJSON(w, http.StatusOK, LikeResponse{ProductID: result.ProductID, UserID: result.UserID, Likes: result.Likes})
+}
+
+func (s *Server) unlikeProduct(w http.ResponseWriter, r *http.Request) error {
+	productID, userID := r.PathValue("id"), r.PathValue("user_id")
+	likes, err := s.likes.Unlike(r.Context(), productID, userID)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, LikeResponse{ProductID: productID, UserID: userID, Likes: likes})
+}
+
+func randomKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"learn-golang-gorm/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProducts(t *testing.T) {
+	server, _ := newTestServer(t, "users", "products", "likes")
+
+	res := call(t, server, "GET", "/products/P001", nil)
+	assert.Equal(t, http.StatusOK, res.status)
+	product := decodeAs[ProductResponse](t, res)
+	assert.Equal(t, "Product 1", product.Name)
+	assert.Equal(t, int64(2), *product.Likes)
+
+	res = call(t, server, "POST", "/products", CreateProductRequest{ID: "P002", Name: "Product 2", Price: MoneyRequest{Amount: 50000}})
+	assert.Equal(t, http.StatusCreated, res.status)
+	assert.Equal(t, Money{Amount: 50000, Currency: "IDR", Display: "IDR 50000"}, decodeAs[ProductResponse](t, res).Price)
+	res = call(t, server, "POST", "/products", CreateProductRequest{ID: "P002", Name: "Product 2"})
+	assert.Equal(t, http.StatusConflict, res.status)
+	res = call(t, server, "POST", "/products", CreateProductRequest{Name: "Product 3"})
+	assert.Equal(t, http.StatusCreated, res.status)
+	assert.NotEmpty(t, decodeAs[ProductResponse](t, res).ID)
+
+	res = call(t, server, "PATCH", "/products/P002", `{"price": {"amount": 1050, "currency": "USD"}}`)
+	assert.Equal(t, http.StatusOK, res.status)
+	product = decodeAs[ProductResponse](t, res)
+	assert.Equal(t, "Product 2", product.Name)
+	assert.Equal(t, "USD 10.50", product.Price.Display)
+	assert.Equal(t, int64(0), *product.Likes)
+
+	res = call(t, server, "GET", "/products?name=like:Product%25&sort=-name", nil)
+	page := decodeAs[pagination.Page[ProductResponse]](t, res)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, "P002", page.Items[1].ID)
+	assert.Nil(t, page.Items[1].Likes)
+
+	// product yang disukai tetap bisa dihapus, like-nya ikut dihapus
+	res = call(t, server, "DELETE", "/products/P001", nil)
+	assert.Equal(t, http.StatusNoContent, res.status)
+	res = call(t, server, "GET", "/users/1/likes", nil)
+	assert.Equal(t, "[]\n", string(res.body))
+	res = call(t, server, "DELETE", "/products/P001", nil)
+	assert.Equal(t, http.StatusNotFound, res.status)
+}
+
+func TestProductLikes(t *testing.T) {
+	server, _ := newTestServer(t, "users", "products", "likes")
+
+	res := call(t, server, "PUT", "/products/P001/likes/3", nil, IdempotencyKeyHeader, "like-1")
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Equal(t, LikeResponse{ProductID: "P001", UserID: "3", Likes: 3}, decodeAs[LikeResponse](t, res))
+	assert.Empty(t, res.header.Get("Idempotent-Replayed"))
+
+	res = call(t, server, "PUT", "/products/P001/likes/3", nil, IdempotencyKeyHeader, "like-1")
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Equal(t, "true", res.header.Get("Idempotent-Replayed"))
+	res = call(t, server, "PUT", "/products/P001/likes/4", nil, IdempotencyKeyHeader, "like-1")
+	assert.Equal(t, http.StatusUnprocessableEntity, res.status)
+
+	// tanpa Idempotency-Key, like ulang tetap tidak dobel
+	res = call(t, server, "PUT", "/products/P001/likes/3", nil)
+	assert.Equal(t, int64(3), decodeAs[LikeResponse](t, res).Likes)
+
+	res = call(t, server, "GET", "/products/P001/likes", nil)
+	users := decodeAs[[]UserResponse](t, res)
+	assert.Equal(t, []string{"1", "2", "3"}, []string{users[0].ID, users[1].ID, users[2].ID})
+	res = call(t, server, "GET", "/users/3/likes", nil)
+	assert.Equal(t, "P001", decodeAs[[]ProductResponse](t, res)[0].ID)
+
+	res = call(t, server, "DELETE", "/products/P001/likes/1", nil)
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Equal(t, int64(2), decodeAs[LikeResponse](t, res).Likes)
+
+	for _, path := range []string{"/products/P404/likes/1", "/products/P001/likes/404"} {
+		res = call(t, server, "PUT", path, nil)
+		assert.Equal(t, http.StatusNotFound, res.status, path)
+	}
+	res = call(t, server, "GET", "/products/P404/likes", nil)
+	assert.Equal(t, http.StatusNotFound, res.status)
+	res = call(t, server, "DELETE", "/products/P404/likes/1", nil)
+	assert.Equal(t, http.StatusNotFound, res.status)
+	assert.Equal(t, "product not found: P404", errorMessage(t, res))
+}
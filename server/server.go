@@ -0,0 +1,367 @@
+// Package server adalah REST API (net/http) untuk user, wallet, address, product, todo dan guest book.
+//
+//	db, err := learn_golang_gorm.Open(cfg)
+//	http.ListenAndServe(":8080", server.New(db))
+//
+// Semua request dan response berupa JSON. Error ditulis sebagai {"error": "..."} dengan status dari
+// dberrors.StatusCode: 404 untuk record yang tidak ada, 409 untuk duplicate key / foreign key,
+// 400 untuk JSON, filter atau cursor yang tidak valid, dan 422 untuk isi request yang tidak valid.
+//
+// Endpoint list (GET /users, /wallets, ...) menerima filter dan sort dari package filter
+// (misalnya ?name.first=like:User%25&sort=-created_at) dan dibagi per halaman dengan package pagination:
+// ?page=2&size=20 (offset, beserta total), atau ?after=<next_cursor> / ?before=<prev_cursor> (cursor).
+// Halaman pertama mode cursor diminta dengan ?after= (kosong).
+//
+// Header X-Actor-ID dipakai sebagai user yang tercatat di user_logs (lihat learn_golang_gorm.WithActor),
+// tetapi hanya jika dikirim oleh proxy terpercaya (lihat TrustedProxies). Tanpa WithAuthenticator,
+// request dengan header tersebut ditolak dengan 401.
+//
+// Dokumen OpenAPI 3 semua endpoint ada di openapi.json dan GET /openapi.json (lihat openapi.go).
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/dberrors"
+	"learn-golang-gorm/filter"
+	"learn-golang-gorm/idempotency"
+	"learn-golang-gorm/internal/validate"
+	"learn-golang-gorm/pagination"
+
+	"gorm.io/gorm"
+)
+
+// ActorHeader berisi ID user yang melakukan perubahan, untuk audit log
+const ActorHeader = "X-Actor-ID"
+
+// maxBodySize membatasi ukuran body JSON
+const maxBodySize = 1 << 20
+
+// Server adalah http.Handler untuk semua endpoint REST API
+type Server struct {
+	db  *gorm.DB
+	mux *http.ServeMux
+
+	users      *learn_golang_gorm.UserRepository
+	wallets    *learn_golang_gorm.WalletRepository
+	addresses  *learn_golang_gorm.AddressRepository
+	products   *learn_golang_gorm.ProductRepository
+	todos      *learn_golang_gorm.TodoRepository
+	guestBooks *learn_golang_gorm.GuestBookRepository
+	likes      *learn_golang_gorm.ProductService
+
+	authenticate Authenticator
+}
+
+func New(db *gorm.DB, opts ...Option) *Server {
+	s := &Server{
+		db:         db,
+		mux:        http.NewServeMux(),
+		users:      learn_golang_gorm.NewUserRepository(db),
+		wallets:    learn_golang_gorm.NewWalletRepository(db),
+		addresses:  learn_golang_gorm.NewAddressRepository(db),
+		products:   learn_golang_gorm.NewProductRepository(db),
+		todos:      learn_golang_gorm.NewTodoRepository(db),
+		guestBooks: learn_golang_gorm.NewGuestBookRepository(db),
+		likes:      learn_golang_gorm.NewProductService(db),
+
+		authenticate: TrustedProxies(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	for _, route := range s.routes() {
+		s.handle(route.method+" "+route.path, route.handler)
+	}
+	s.mux.HandleFunc("GET /openapi.json", serveOpenAPI)
+	return s
+}
+
+// route adalah satu endpoint beserta keterangannya untuk dokumen OpenAPI (lihat openapi.go)
+type route struct {
+	method, path string
+	handler      handlerFunc
+	summary      string
+	status       int          // status jika berhasil
+	request      interface{}  // body request, nil jika tanpa body
+	response     interface{}  // body response, nil untuk 204 No Content
+	filter       *filter.Spec // filter query string untuk endpoint list
+	headers      []string     // header request selain ActorHeader
+}
+
+func (s *Server) routes() []route {
+	return []route{
+		{method: "GET", path: "/users", handler: s.listUsers, summary: "List user beserta wallet-nya",
+			status: http.StatusOK, response: pagination.Page[UserResponse]{}, filter: &learn_golang_gorm.UserFilter},
+		{method: "POST", path: "/users", handler: s.createUser, summary: "Buat user",
+			status: http.StatusCreated, request: CreateUserRequest{}, response: UserResponse{}},
+		{method: "GET", path: "/users/{id}", handler: s.getUser, summary: "Ambil user beserta wallet-nya",
+			status: http.StatusOK, response: UserResponse{}},
+		{method: "PATCH", path: "/users/{id}", handler: s.updateUser, summary: "Ubah nama dan / atau password user",
+			status: http.StatusOK, request: UpdateUserRequest{}, response: UserResponse{}},
+		{method: "DELETE", path: "/users/{id}", handler: s.deleteUser, summary: "Hapus user yang tidak punya wallet, address dan like",
+			status: http.StatusNoContent},
+		{method: "GET", path: "/users/{id}/wallet", handler: s.getUserWallet, summary: "Ambil wallet milik user",
+			status: http.StatusOK, response: WalletResponse{}},
+		{method: "GET", path: "/users/{id}/addresses", handler: s.listUserAddresses, summary: "List address milik user",
+			status: http.StatusOK, response: []AddressResponse{}},
+		{method: "GET", path: "/users/{id}/likes", handler: s.listUserLikes, summary: "List product yang disukai user",
+			status: http.StatusOK, response: []ProductResponse{}},
+
+		{method: "GET", path: "/wallets", handler: s.listWallets, summary: "List wallet",
+			status: http.StatusOK, response: pagination.Page[WalletResponse]{}, filter: &learn_golang_gorm.WalletFilter},
+		{method: "POST", path: "/wallets", handler: s.createWallet, summary: "Buat wallet dengan saldo 0 (satu user satu wallet)",
+			status: http.StatusCreated, request: CreateWalletRequest{}, response: WalletResponse{}},
+		{method: "GET", path: "/wallets/{id}", handler: s.getWallet, summary: "Ambil wallet",
+			status: http.StatusOK, response: WalletResponse{}},
+		{method: "DELETE", path: "/wallets/{id}", handler: s.deleteWallet, summary: "Hapus wallet yang belum punya posting ledger",
+			status: http.StatusNoContent},
+
+		{method: "GET", path: "/addresses", handler: s.listAddresses, summary: "List address",
+			status: http.StatusOK, response: pagination.Page[AddressResponse]{}, filter: &learn_golang_gorm.AddressFilter},
+		{method: "POST", path: "/addresses", handler: s.createAddress, summary: "Buat address",
+			status: http.StatusCreated, request: CreateAddressRequest{}, response: AddressResponse{}},
+		{method: "GET", path: "/addresses/{id}", handler: s.getAddress, summary: "Ambil address",
+			status: http.StatusOK, response: AddressResponse{}},
+		{method: "PATCH", path: "/addresses/{id}", handler: s.updateAddress, summary: "Ubah address",
+			status: http.StatusOK, request: UpdateAddressRequest{}, response: AddressResponse{}},
+		{method: "DELETE", path: "/addresses/{id}", handler: s.deleteAddress, summary: "Hapus address",
+			status: http.StatusNoContent},
+
+		{method: "GET", path: "/products", handler: s.listProducts, summary: "List product",
+			status: http.StatusOK, response: pagination.Page[ProductResponse]{}, filter: &learn_golang_gorm.ProductFilter},
+		{method: "POST", path: "/products", handler: s.createProduct, summary: "Buat product",
+			status: http.StatusCreated, request: CreateProductRequest{}, response: ProductResponse{}},
+		{method: "GET", path: "/products/{id}", handler: s.getProduct, summary: "Ambil product beserta jumlah like",
+			status: http.StatusOK, response: ProductResponse{}},
+		{method: "PATCH", path: "/products/{id}", handler: s.updateProduct, summary: "Ubah nama dan / atau harga product",
+			status: http.StatusOK, request: UpdateProductRequest{}, response: ProductResponse{}},
+		{method: "DELETE", path: "/products/{id}", handler: s.deleteProduct, summary: "Hapus product beserta like-nya",
+			status: http.StatusNoContent},
+		{method: "GET", path: "/products/{id}/likes", handler: s.listProductLikes, summary: "List user yang menyukai product",
+			status: http.StatusOK, response: []UserResponse{}},
+		{method: "PUT", path: "/products/{id}/likes/{user_id}", handler: s.likeProduct, summary: "Like product (idempotent)",
+			status: http.StatusOK, response: LikeResponse{}, headers: []string{IdempotencyKeyHeader}},
+		{method: "DELETE", path: "/products/{id}/likes/{user_id}", handler: s.unlikeProduct, summary: "Batalkan like product",
+			status: http.StatusOK, response: LikeResponse{}},
+
+		{method: "GET", path: "/todos", handler: s.listTodos, summary: "List todo (tanpa yang sudah dihapus)",
+			status: http.StatusOK, response: pagination.Page[TodoResponse]{}, filter: &learn_golang_gorm.TodoFilter},
+		{method: "POST", path: "/todos", handler: s.createTodo, summary: "Buat todo",
+			status: http.StatusCreated, request: CreateTodoRequest{}, response: TodoResponse{}},
+		{method: "GET", path: "/todos/{id}", handler: s.getTodo, summary: "Ambil todo",
+			status: http.StatusOK, response: TodoResponse{}},
+		{method: "PATCH", path: "/todos/{id}", handler: s.updateTodo, summary: "Ubah todo",
+			status: http.StatusOK, request: UpdateTodoRequest{}, response: TodoResponse{}},
+		{method: "DELETE", path: "/todos/{id}", handler: s.deleteTodo, summary: "Hapus todo (soft delete)",
+			status: http.StatusNoContent},
+
+		{method: "GET", path: "/guest-books", handler: s.listGuestBooks, summary: "List pesan guest book",
+			status: http.StatusOK, response: pagination.Page[GuestBookResponse]{}, filter: &learn_golang_gorm.GuestBookFilter},
+		{method: "POST", path: "/guest-books", handler: s.createGuestBook, summary: "Tulis pesan guest book",
+			status: http.StatusCreated, request: CreateGuestBookRequest{}, response: GuestBookResponse{}},
+		{method: "GET", path: "/guest-books/{id}", handler: s.getGuestBook, summary: "Ambil pesan guest book",
+			status: http.StatusOK, response: GuestBookResponse{}},
+		{method: "PATCH", path: "/guest-books/{id}", handler: s.updateGuestBook, summary: "Ubah pesan guest book",
+			status: http.StatusOK, request: UpdateGuestBookRequest{}, response: GuestBookResponse{}},
+		{method: "DELETE", path: "/guest-books/{id}", handler: s.deleteGuestBook, summary: "Hapus pesan guest book",
+			status: http.StatusNoContent},
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	actor, err := s.authenticate(r)
+	if err != nil {
+		s.writeError(w, r, &httpError{status: http.StatusUnauthorized, message: err.Error()})
+		return
+	}
+	if actor != "" {
+		r = r.WithContext(learn_golang_gorm.WithActor(r.Context(), actor))
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// handlerFunc sama seperti http.HandlerFunc, error-nya ditulis oleh handle sebagai response JSON
+type handlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+func (s *Server) handle(pattern string, h handlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if err := h(w, r); err != nil {
+			s.writeError(w, r, err)
+		}
+	})
+}
+
+// ===== error =====
+
+// httpError adalah error yang status dan pesannya sudah ditentukan oleh handler
+type httpError struct {
+	status  int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+func conflict(format string, args ...interface{}) error {
+	return &httpError{status: http.StatusConflict, message: fmt.Sprintf(format, args...)}
+}
+
+// notFound mengganti error not found dari repository dengan pesan yang lebih pendek, misalnya "user 9 not found"
+func notFound(err error, resource string, id interface{}) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, dberrors.ErrNotFound) {
+		return &httpError{status: http.StatusNotFound, message: fmt.Sprintf("%s %v not found", resource, id)}
+	}
+	return err
+}
+
+func statusCode(err error) int {
+	var httpErr *httpError
+	switch {
+	case errors.As(err, &httpErr):
+		return httpErr.status
+	case errors.Is(err, filter.ErrInvalidFilter), errors.Is(err, pagination.ErrInvalidCursor):
+		return http.StatusBadRequest
+	case errors.Is(err, idempotency.ErrConflict):
+		return http.StatusUnprocessableEntity
+	}
+	return dberrors.StatusCode(err)
+}
+
+// writeError menulis err sebagai JSON. Pesan error 5xx tidak dikirim ke client, hanya ditulis ke log.
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status := statusCode(err)
+	message := err.Error()
+	if status >= http.StatusInternalServerError {
+		s.db.Logger.Error(r.Context(), "server: %s %s: %v", r.Method, r.URL.Path, err)
+		message = strings.ToLower(http.StatusText(status))
+	}
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
+
+// ===== request / response =====
+
+// writeJSON selalu mengembalikan nil supaya bisa langsung dipakai sebagai return handler. Error saat menulis
+// body (misalnya client sudah memutus koneksi) tidak bisa dikirim lagi ke client, jadi diabaikan.
+func writeJSON(w http.ResponseWriter, status int, body interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+	return nil
+}
+
+func noContent(w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// decode membaca body JSON ke dst. Field yang tidak dikenal ditolak, misalnya "password" pada wallet.
+func decode(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return &httpError{status: http.StatusRequestEntityTooLarge, message: "request body too large"}
+		}
+		return badRequest("invalid JSON body: %v", err)
+	}
+	if decoder.More() {
+		return badRequest("invalid JSON body: more than one value")
+	}
+	return nil
+}
+
+// pathInt64 membaca {id} untuk model dengan primary key int64 (address, guest book)
+func pathInt64(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return 0, badRequest("invalid id %q", r.PathValue("id"))
+	}
+	return id, nil
+}
+
+func queryInt(values url.Values, name string) (int, error) {
+	if values.Get(name) == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(values.Get(name))
+	if err != nil || n < 0 {
+		return 0, badRequest("invalid %s %q", name, values.Get(name))
+	}
+	return n, nil
+}
+
+// validation mengumpulkan semua kesalahan isi request supaya dikirim sekaligus (422), aturannya sama
+// dengan API gRPC (package validate)
+type validation struct {
+	validate.Errors
+}
+
+func (v validation) err() error {
+	if len(v.Errors) == 0 {
+		return nil
+	}
+	return &httpError{status: http.StatusUnprocessableEntity, message: v.Message()}
+}
+
+// ===== list =====
+
+// list menulis satu halaman hasil query sesuai filter dan parameter paging di query string.
+// Pada mode cursor, sort hanya boleh memakai kolom tabel T sendiri (bukan relasi).
+func list[T, R any](w http.ResponseWriter, r *http.Request, query *gorm.DB, spec filter.Spec, response func(*T) R) error {
+	values := r.URL.Query()
+	f, err := spec.Parse(values)
+	if err != nil {
+		return err
+	}
+	size, err := queryInt(values, "size")
+	if err != nil {
+		return err
+	}
+
+	var page *pagination.Page[T]
+	if values.Has("after") || values.Has("before") {
+		var req pagination.CursorRequest
+		if req, err = f.CursorRequest(size, values.Get("after"), values.Get("before")); err != nil {
+			return err
+		}
+		page, err = pagination.Cursor[T](r.Context(), query.Scopes(f.Scope), req)
+	} else {
+		var number int
+		if number, err = queryInt(values, "page"); err != nil {
+			return err
+		}
+		page, err = pagination.Offset[T](r.Context(), query.Scopes(f.Scope), number, size)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, pagination.Page[R]{
+		Items: responses(page.Items, response), HasMore: page.HasMore, Size: page.Size,
+		Next: page.Next, Prev: page.Prev,
+		Number: page.Number, Total: page.Total, TotalPages: page.TotalPages,
+	})
+}
+
+// responses mengubah slice model menjadi slice response (bukan nil, supaya ditulis [] dan bukan null)
+func responses[T, R any](records []T, response func(*T) R) []R {
+	items := make([]R, len(records))
+	for i := range records {
+		items[i] = response(&records[i])
+	}
+	return items
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/internal/testapp"
+	"learn-golang-gorm/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// newTestServer membuat server dengan database dari testapp.DB (sudah dimigrasi dan berisi fixture
+// testdata/fixtures/<name>.yml dari package utama)
+func newTestServer(t *testing.T, names ...string) (*httptest.Server, *gorm.DB) {
+	t.Helper()
+	db := testapp.DB(t, names...)
+	// client test dianggap proxy terpercaya, jadi boleh mengirim X-Actor-ID
+	server := httptest.NewServer(New(db, WithAuthenticator(TrustedProxies(netip.MustParsePrefix("127.0.0.1/32")))))
+	t.Cleanup(server.Close)
+	return server, db
+}
+
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// decodeAs membaca body response ke tipe T
+func decodeAs[T any](t *testing.T, res response) T {
+	t.Helper()
+	var value T
+	if err := json.Unmarshal(res.body, &value); err != nil {
+		t.Fatalf("decode %s: %v", res.body, err)
+	}
+	return value
+}
+
+// call mengirim request dengan body JSON (string apa adanya, atau nilai lain di-encode)
+func call(t *testing.T, server *httptest.Server, method, path string, body interface{}, headers ...string) response {
+	t.Helper()
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, server.URL+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response{status: res.StatusCode, header: res.Header, body: data}
+}
+
+func errorMessage(t *testing.T, res response) string {
+	t.Helper()
+	return decodeAs[ErrorResponse](t, res).Error
+}
+
+func TestUsers(t *testing.T) {
+	server, db := newTestServer(t, "users", "wallet_owners", "wallets", "addresses")
+
+	res := call(t, server, "GET", "/users/1", nil)
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.NotContains(t, string(res.body), "password")
+	user := decodeAs[UserResponse](t, res)
+	assert.Equal(t, Name{First: "Lev", Middle: "Tempest", Last: "Vex"}, user.Name)
+	assert.Equal(t, "Lev Tempest Vex", user.FullName)
+	assert.Equal(t, int64(1000000), user.Wallet.Balance.Amount)
+	assert.Equal(t, "IDR 1000000", user.Wallet.Balance.Display)
+
+	res = call(t, server, "GET", "/users/404", nil)
+	assert.Equal(t, http.StatusNotFound, res.status)
+	assert.Equal(t, "user 404 not found", errorMessage(t, res))
+
+	// create: password di-hash dan tidak dikembalikan
+	res = call(t, server, "POST", "/users", CreateUserRequest{ID: "50", Name: Name{First: "Budi"}, Password: "rahasia"})
+	assert.Equal(t, http.StatusCreated, res.status)
+	assert.Equal(t, "/users/50", res.header.Get("Location"))
+	assert.NotContains(t, string(res.body), "rahasia")
+	assert.Contains(t, string(res.body), `"middle":"","last":""`) // nama yang kosong tetap dikirim
+	assert.Nil(t, decodeAs[UserResponse](t, res).Wallet)
+	var created learn_golang_gorm.User
+	assert.Nil(t, db.Take(&created, "id = ?", "50").Error)
+	assert.True(t, created.VerifyPassword("rahasia"))
+
+	res = call(t, server, "POST", "/users", CreateUserRequest{ID: "50", Name: Name{First: "Budi"}, Password: "rahasia"})
+	assert.Equal(t, http.StatusConflict, res.status)
+
+	res = call(t, server, "POST", "/users", CreateUserRequest{Name: Name{Last: strings.Repeat("x", 101)}})
+	assert.Equal(t, http.StatusUnprocessableEntity, res.status)
+	assert.Equal(t, "name.first is required; name.last must be at most 100 characters; password is required", errorMessage(t, res))
+
+	// field yang tidak dikenal dan JSON rusak ditolak
+	res = call(t, server, "POST", "/users", `{"name": {"first": "Budi"}, "password": "x", "admin": true}`)
+	assert.Equal(t, http.StatusBadRequest, res.status)
+	res = call(t, server, "POST", "/users", `{"name": `)
+	assert.Equal(t, http.StatusBadRequest, res.status)
+
+	// update sebagian, kolom yang tidak dikirim tidak berubah
+	res = call(t, server, "PATCH", "/users/50", `{"name": {"last": "Santoso"}, "password": "baru"}`, ActorHeader, "1")
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Equal(t, "Budi Santoso", decodeAs[UserResponse](t, res).FullName)
+	assert.Nil(t, db.Take(&created, "id = ?", "50").Error)
+	assert.True(t, created.VerifyPassword("baru"))
+
+	var log learn_golang_gorm.UserLog
+	assert.Nil(t, db.Where("table_name = ? AND action = ?", "users", learn_golang_gorm.AuditUpdate).Last(&log).Error)
+	assert.Equal(t, "1", log.UserId) // dari header X-Actor-ID
+	assert.Equal(t, "50", log.RecordId)
+
+	res = call(t, server, "PATCH", "/users/404", `{"name": {"last": "Santoso"}}`)
+	assert.Equal(t, http.StatusNotFound, res.status)
+
+	// relasi
+	res = call(t, server, "GET", "/users/1/addresses", nil)
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Equal(t, 2, len(decodeAs[[]AddressResponse](t, res)))
+	res = call(t, server, "GET", "/users/50/addresses", nil)
+	assert.Equal(t, "[]\n", string(res.body))
+	res = call(t, server, "GET", "/users/50/wallet", nil)
+	assert.Equal(t, http.StatusNotFound, res.status)
+	res = call(t, server, "GET", "/users/2/wallet", nil)
+	assert.Equal(t, "2", decodeAs[WalletResponse](t, res).UserID)
+
+	// user yang masih punya wallet / address tidak bisa dihapus
+	res = call(t, server, "DELETE", "/users/1", nil)
+	assert.Equal(t, http.StatusConflict, res.status)
+	res = call(t, server, "DELETE", "/users/50", nil)
+	assert.Equal(t, http.StatusNoContent, res.status)
+	res = call(t, server, "DELETE", "/users/50", nil)
+	assert.Equal(t, http.StatusNotFound, res.status)
+}
+
+func TestListUsers(t *testing.T) {
+	server, _ := newTestServer(t, "users", "wallet_owners", "wallets")
+
+	ids := func(page pagination.Page[UserResponse]) []string {
+		var ids []string
+		for _, user := range page.Items {
+			ids = append(ids, user.ID)
+		}
+		return ids
+	}
+
+	res := call(t, server, "GET", "/users?wallet.balance=gte:1000000&sort=-id&size=3&page=1", nil)
+	assert.Equal(t, http.StatusOK, res.status)
+	page := decodeAs[pagination.Page[UserResponse]](t, res)
+	assert.Equal(t, []string{"21", "20", "2"}, ids(page))
+	assert.Equal(t, int64(4), page.Total)
+	assert.Equal(t, 2, page.TotalPages)
+	assert.True(t, page.HasMore)
+	assert.NotNil(t, page.Items[0].Wallet)
+
+	// cursor: halaman pertama dengan after kosong
+	res = call(t, server, "GET", "/users?wallet.balance=gte:1000000&sort=-id&size=3&after=", nil)
+	page = decodeAs[pagination.Page[UserResponse]](t, res)
+	assert.Equal(t, []string{"21", "20", "2"}, ids(page))
+	assert.Equal(t, int64(0), page.Total)
+	res = call(t, server, "GET", "/users?wallet.balance=gte:1000000&sort=-id&size=3&after="+page.Next, nil)
+	page = decodeAs[pagination.Page[UserResponse]](t, res)
+	assert.Equal(t, []string{"1"}, ids(page))
+	assert.False(t, page.HasMore)
+
+	for _, query := range []string{
+		"password=secret",
+		"page=abc",
+		"after=rusak",
+		"sort=-wallet.balance&after=", // kolom relasi tidak bisa dipakai untuk cursor
+	} {
+		res = call(t, server, "GET", "/users?"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, res.status, query)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	res := call(t, server, "POST", "/guest-books", strings.Repeat(" ", maxBodySize+1))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.status)
+
+	res = call(t, server, "GET", "/addresses/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, res.status)
+	assert.Equal(t, `invalid id "abc"`, errorMessage(t, res))
+
+	// error database lain tidak dikirim ke client
+	closed, db := newTestServer(t)
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+	res = call(t, closed, "GET", "/users/1", nil)
+	assert.Equal(t, http.StatusInternalServerError, res.status)
+	assert.Equal(t, "internal server error", errorMessage(t, res))
+}
+
+func TestActorHeaderTrust(t *testing.T) {
+	_, db := newTestServer(t)
+	serve := func(handler http.Handler, remoteAddr, actor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/users/404", nil)
+		req.RemoteAddr = remoteAddr
+		if actor != "" {
+			req.Header.Set(ActorHeader, actor)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// tanpa WithAuthenticator header X-Actor-ID selalu ditolak
+	res := serve(New(db), "127.0.0.1:5000", "1")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+	assert.Contains(t, res.Body.String(), "only accepted from a trusted proxy")
+	assert.Equal(t, http.StatusNotFound, serve(New(db), "127.0.0.1:5000", "").Code)
+
+	// hanya proxy di 10.0.0.0/8 yang boleh mengirimnya
+	behindProxy := New(db, WithAuthenticator(TrustedProxies(netip.MustParsePrefix("10.0.0.0/8"))))
+	assert.Equal(t, http.StatusNotFound, serve(behindProxy, "10.1.2.3:5000", "1").Code)
+	assert.Equal(t, http.StatusNotFound, serve(behindProxy, "[::ffff:10.1.2.3]:5000", "1").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(behindProxy, "192.168.1.1:5000", "1").Code)
+	assert.Equal(t, http.StatusNotFound, serve(behindProxy, "192.168.1.1:5000", "").Code)
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	learn_golang_gorm "learn-golang-gorm"
+)
+
+func (s *Server) listTodos(w http.ResponseWriter, r *http.Request) error {
+	return list(w, r, s.todos.DB(r.Context()), learn_golang_gorm.TodoFilter, newTodoResponse)
+}
+
+func (s *Server) createTodo(w http.ResponseWriter, r *http.Request) error {
+	var req CreateTodoRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+	if err := req.validate(); err != nil {
+		return err
+	}
+	// tabel todos tidak punya foreign key ke users, jadi user-nya dicek di sini
+	if err := s.requireUser(r.Context(), req.UserID, http.StatusUnprocessableEntity); err != nil {
+		return err
+	}
+
+	todo := learn_golang_gorm.Todo{UserId: req.UserID, Title: req.Title, Description: req.Description}
+	if err := s.todos.Create(r.Context(), &todo); err != nil {
+		return err
+	}
+	w.Header().Set("Location", "/todos/"+strconv.FormatUint(uint64(todo.ID), 10))
+	return writeJSON(w, http.StatusCreated, newTodoResponse(&todo))
+}
+
+func (s *Server) getTodo(w http.ResponseWriter, r *http.Request) error {
+	id, err := pathID(r)
+	if err != nil {
+		return err
+	}
+	todo, err := s.todos.FindByID(r.Context(), id)
+	if err != nil {
+		return notFound(err, "todo", id)
+	}
+	return writeJSON(w, http.StatusOK, newTodoResponse(todo))
+}
+
+func (s *Server) updateTodo(w http.ResponseWriter, r *http.Request) error {
+	id, err := pathID(r)
+	if err != nil {
+		return err
+	}
+	var req UpdateTodoRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+	columns, err := req.columns()
+	if err != nil {
+		return err
+	}
+	if len(columns) > 0 {
+		if err := s.todos.Update(r.Context(), id, columns); err != nil {
+			return notFound(err, "todo", id)
+		}
+	}
+	return s.getTodo(w, r)
+}
+
+// deleteTodo hanya soft delete, todo masih bisa di-Restore lewat TodoRepository
+func (s *Server) deleteTodo(w http.ResponseWriter, r *http.Request) error {
+	id, err := pathID(r)
+	if err != nil {
+		return err
+	}
+	if err := s.todos.SoftDelete(r.Context(), id); err != nil {
+		return notFound(err, "todo", id)
+	}
+	return noContent(w)
+}
+
+// pathID membaca {id} untuk model dengan gorm.Model (primary key uint)
+func pathID(r *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+	if err != nil {
+		return 0, badRequest("invalid id %q", r.PathValue("id"))
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	learn_golang_gorm "learn-golang-gorm"
+	"learn-golang-gorm/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodos(t *testing.T) {
+	server, db := newTestServer(t, "users", "todos")
+
+	res := call(t, server, "POST", "/todos", CreateTodoRequest{UserID: "1", Title: "Belajar GORM"})
+	assert.Equal(t, http.StatusCreated, res.status)
+	todo := decodeAs[TodoResponse](t, res)
+
+	res = call(t, server, "POST", "/todos", CreateTodoRequest{UserID: "404", Title: "Belajar GORM"})
+	assert.Equal(t, http.StatusUnprocessableEntity, res.status)
+
+	res = call(t, server, "PATCH", "/todos/"+itoa(todo.ID), `{"description": "pagination"}`)
+	assert.Equal(t, http.StatusOK, res.status)
+	todo = decodeAs[TodoResponse](t, res)
+	assert.Equal(t, "Belajar GORM", todo.Title)
+	assert.Equal(t, "pagination", todo.Description)
+
+	// todo yang sudah di-soft delete tidak terlihat
+	res = call(t, server, "GET", "/todos/1", nil)
+	assert.Equal(t, http.StatusNotFound, res.status)
+	res = call(t, server, "GET", "/todos?user_id=1", nil)
+	page := decodeAs[pagination.Page[TodoResponse]](t, res)
+	assert.Equal(t, int64(len(page.Items)), page.Total)
+	for _, item := range page.Items {
+		assert.NotEqual(t, uint(1), item.ID)
+	}
+
+	res = call(t, server, "DELETE", "/todos/"+itoa(todo.ID), nil)
+	assert.Equal(t, http.StatusNoContent, res.status)
+	deleted, err := learn_golang_gorm.NewTodoRepository(db).FindDeleted(t.Context(), "1")
+	assert.Nil(t, err)
+	assert.Equal(t, todo.ID, deleted[len(deleted)-1].ID)
+	res = call(t, server, "DELETE", "/todos/"+itoa(todo.ID), nil)
+	assert.Equal(t, http.StatusNotFound, res.status)
+}
+
+func TestGuestBooks(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	res := call(t, server, "POST", "/guest-books", CreateGuestBookRequest{Name: "Budi", Email: "budi@example.com", Message: "Halo"})
+	assert.Equal(t, http.StatusCreated, res.status)
+	guestBook := decodeAs[GuestBookResponse](t, res)
+	assert.Equal(t, "/guest-books/"+itoa(uint(guestBook.ID)), res.header.Get("Location"))
+
+	res = call(t, server, "POST", "/guest-books", CreateGuestBookRequest{Name: "Budi", Email: "budi", Message: "Halo"})
+	assert.Equal(t, http.StatusUnprocessableEntity, res.status)
+	assert.Equal(t, `email "budi" is not a valid email address`, errorMessage(t, res))
+
+	res = call(t, server, "PATCH", "/guest-books/"+itoa(uint(guestBook.ID)), `{"message": "Halo juga"}`)
+	assert.Equal(t, "Halo juga", decodeAs[GuestBookResponse](t, res).Message)
+
+	res = call(t, server, "GET", "/guest-books?email=budi@example.com", nil)
+	assert.Equal(t, 1, len(decodeAs[pagination.Page[GuestBookResponse]](t, res).Items))
+
+	res = call(t, server, "DELETE", "/guest-books/"+itoa(uint(guestBook.ID)), nil)
+	assert.Equal(t, http.StatusNoContent, res.status)
+	res = call(t, server, "GET", "/guest-books/"+itoa(uint(guestBook.ID)), nil)
+	assert.Equal(t, http.StatusNotFound, res.status)
+}
+
+func itoa(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
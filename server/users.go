@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	learn_golang_gorm "learn-golang-gorm"
+)
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) error {
+	query := s.users.DB(r.Context()).Joins("Wallet")
+	return list(w, r, query, learn_golang_gorm.UserFilter, newUserResponse)
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) error {
+	var req CreateUserRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+	if err := req.validate(); err != nil {
+		return err
+	}
+
+	user := learn_golang_gorm.User{
+		ID:       req.ID,
+		Name:     req.Name.model(),
+		Password: req.Password,
+	}
+	if err := s.users.Create(r.Context(), &user); err != nil {
+		return err
+	}
+	w.Header().Set("Location", "/users/"+user.ID)
+	return writeJSON(w, http.StatusCreated, newUserResponse(&user))
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) error {
+	user, err := s.users.FindWithWallet(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return notFound(err, "user", r.PathValue("id"))
+	}
+	return writeJSON(w, http.StatusOK, newUserResponse(user))
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request) error {
+	var req UpdateUserRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+	columns, err := req.columns()
+	if err != nil {
+		return err
+	}
+	id := r.PathValue("id")
+	if len(columns) > 0 {
+		if err := s.users.Update(r.Context(), id, columns); err != nil {
+			return notFound(err, "user", id)
+		}
+	}
+	return s.getUser(w, r)
+}
+
+// deleteUser gagal dengan 409 jika user masih punya wallet, address atau like (foreign key)
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) error {
+	if err := s.users.Delete(r.Context(), r.PathValue("id")); err != nil {
+		return notFound(err, "user", r.PathValue("id"))
+	}
+	return noContent(w)
+}
+
+func (s *Server) getUserWallet(w http.ResponseWriter, r *http.Request) error {
+	id := r.PathValue("id")
+	if err := s.requireUser(r.Context(), id, http.StatusNotFound); err != nil {
+		return err
+	}
+	wallet, err := s.wallets.FindByUser(r.Context(), id)
+	if err != nil {
+		return notFound(err, "wallet of user", id)
+	}
+	return writeJSON(w, http.StatusOK, newWalletResponse(wallet))
+}
+
+func (s *Server) listUserAddresses(w http.ResponseWriter, r *http.Request) error {
+	id := r.PathValue("id")
+	if err := s.requireUser(r.Context(), id, http.StatusNotFound); err != nil {
+		return err
+	}
+	addresses, err := s.addresses.FindByUser(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, responses(addresses, newAddressResponse))
+}
+
+// listUserLikes mengembalikan product yang disukai user (relasi User.LikeProducts)
+func (s *Server) listUserLikes(w http.ResponseWriter, r *http.Request) error {
+	id := r.PathValue("id")
+	if err := s.requireUser(r.Context(), id, http.StatusNotFound); err != nil {
+		return err
+	}
+	products, err := s.products.FindLikedBy(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, responses(products, newProductResponse))
+}
+
+// requireUser mengembalikan error dengan status jika user tidak ada: 404 jika user adalah resource
+// di path, 422 jika user hanya direferensikan oleh body request (misalnya user_id pada address baru)
+func (s *Server) requireUser(ctx context.Context, id string, status int) error {
+	exists, err := s.users.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &httpError{status: status, message: "user " + id + " not found"}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"net/http"
+
+	learn_golang_gorm "learn-golang-gorm"
+)
+
+func (s *Server) listWallets(w http.ResponseWriter, r *http.Request) error {
+	return list(w, r, s.wallets.DB(r.Context()), learn_golang_gorm.WalletFilter, newWalletResponse)
+}
+
+// createWallet membuat wallet dengan saldo 0. User yang sudah punya wallet ditolak oleh unique index
+// wallets.user_id (duplicate key, 409), karena satu user hanya punya satu wallet.
+func (s *Server) createWallet(w http.ResponseWriter, r *http.Request) error {
+	var req CreateWalletRequest
+	if err := decode(w, r, &req); err != nil {
+		return err
+	}
+	balance, err := req.validate()
+	if err != nil {
+		return err
+	}
+	if err := s.requireUser(r.Context(), req.UserID, http.StatusUnprocessableEntity); err != nil {
+		return err
+	}
+
+	wallet := learn_golang_gorm.Wallet{UserId: req.UserID, Balance: balance}
+	if err := s.wallets.Create(r.Context(), &wallet); err != nil {
+		return err
+	}
+	w.Header().Set("Location", "/wallets/"+wallet.ID)
+	return writeJSON(w, http.StatusCreated, newWalletResponse(&wallet))
+}
+
+func (s *Server) getWallet(w http.ResponseWriter, r *http.Request) error {
+	wallet, err := s.wallets.FindByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return notFound(err, "wallet", r.PathValue("id"))
+	}
+	return writeJSON(w, http.StatusOK, newWalletResponse(wallet))
+}
+
+// deleteWallet gagal dengan 409 jika wallet sudah punya transaksi atau posting ledger
+func (s *Server) deleteWallet(w http.ResponseWriter, r *http.Request) error {
+	if err := s.wallets.Delete(r.Context(), r.PathValue("id")); err != nil {
+		return notFound(err, "wallet", r.PathValue("id"))
+	}
+	return noContent(w)
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"learn-golang-gorm/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWallets(t *testing.T) {
+	server, _ := newTestServer(t, "users", "wallet_owners", "wallets")
+
+	res := call(t, server, "POST", "/wallets", CreateWalletRequest{UserID: "3", Currency: "usd"})
+	assert.Equal(t, http.StatusCreated, res.status)
+	wallet := decodeAs[WalletResponse](t, res)
+	assert.Equal(t, Money{Amount: 0, Currency: "USD", Display: "USD 0.00"}, wallet.Balance)
+	assert.Equal(t, "/wallets/"+wallet.ID, res.header.Get("Location"))
+
+	res = call(t, server, "GET", "/wallets/"+wallet.ID, nil)
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Equal(t, "3", decodeAs[WalletResponse](t, res).UserID)
+
+	// satu user satu wallet, dijaga unique index wallets.user_id
+	res = call(t, server, "POST", "/wallets", CreateWalletRequest{UserID: "3"})
+	assert.Equal(t, http.StatusConflict, res.status)
+
+	res = call(t, server, "POST", "/wallets", CreateWalletRequest{UserID: "404"})
+	assert.Equal(t, http.StatusUnprocessableEntity, res.status)
+	res = call(t, server, "POST", "/wallets", CreateWalletRequest{UserID: "4", Currency: "XXX"})
+	assert.Equal(t, http.StatusUnprocessableEntity, res.status)
+	assert.Equal(t, `currency "XXX" is not supported`, errorMessage(t, res))
+
+	// saldo hanya bisa diisi lewat top up / transfer
+	res = call(t, server, "POST", "/wallets", `{"user_id": "4", "balance": {"amount": 1}}`)
+	assert.Equal(t, http.StatusBadRequest, res.status)
+	res = call(t, server, "PATCH", "/wallets/"+wallet.ID, `{"balance": {"amount": 1}}`)
+	assert.Equal(t, http.StatusMethodNotAllowed, res.status)
+
+	res = call(t, server, "GET", "/wallets?currency=IDR&sort=-user.name.first", nil)
+	assert.Equal(t, http.StatusOK, res.status)
+	page := decodeAs[pagination.Page[WalletResponse]](t, res)
+	assert.Equal(t, int64(4), page.Total)
+	assert.Equal(t, "2", page.Items[0].UserID) // "User2" setelah "User 21" dan "User 20"
+
+	// saldo awal wallet fixture sudah tercatat di ledger, jadi tidak bisa dihapus
+	res = call(t, server, "DELETE", "/wallets/1", nil)
+	assert.Equal(t, http.StatusConflict, res.status)
+	res = call(t, server, "DELETE", "/wallets/"+wallet.ID, nil)
+	assert.Equal(t, http.StatusNoContent, res.status)
+	res = call(t, server, "DELETE", "/wallets/404", nil)
+	assert.Equal(t, http.StatusNotFound, res.status)
+}
+
+func TestAddresses(t *testing.T) {
+	server, _ := newTestServer(t, "users", "addresses")
+
+	res := call(t, server, "POST", "/addresses", CreateAddressRequest{UserID: "3", Address: "Jalan 3"})
+	assert.Equal(t, http.StatusCreated, res.status)
+	address := decodeAs[AddressResponse](t, res)
+	assert.Equal(t, int64(5), address.ID)
+	assert.Equal(t, "/addresses/5", res.header.Get("Location"))
+
+	res = call(t, server, "POST", "/addresses", CreateAddressRequest{UserID: "404", Address: "Jalan 3"})
+	assert.Equal(t, http.StatusUnprocessableEntity, res.status)
+	assert.Equal(t, "user 404 not found", errorMessage(t, res))
+
+	res = call(t, server, "PATCH", "/addresses/5", UpdateAddressRequest{Address: new(string)})
+	assert.Equal(t, http.StatusUnprocessableEntity, res.status)
+	res = call(t, server, "PATCH", "/addresses/5", `{"address": "Jalan 4"}`)
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Equal(t, "Jalan 4", decodeAs[AddressResponse](t, res).Address)
+
+	res = call(t, server, "GET", "/addresses?address=like:Jalan%25&user_id=in:1,3&sort=-address,id", nil)
+	page := decodeAs[pagination.Page[AddressResponse]](t, res)
+	assert.Equal(t, 3, len(page.Items))
+	assert.Equal(t, []int64{5, 2, 1}, []int64{page.Items[0].ID, page.Items[1].ID, page.Items[2].ID})
+
+	res = call(t, server, "DELETE", "/addresses/5", nil)
+	assert.Equal(t, http.StatusNoContent, res.status)
+	res = call(t, server, "GET", "/addresses/5", nil)
+	assert.Equal(t, http.StatusNotFound, res.status)
+	assert.Equal(t, "address 5 not found", errorMessage(t, res))
+}
@@ -0,0 +1,345 @@
+package learn_golang_gorm
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"learn-golang-gorm/money"
+
+	"gorm.io/gorm"
+)
+
+// Format file statement
+type StatementFormat string
+
+const (
+	StatementCSV  StatementFormat = "csv"
+	StatementJSON StatementFormat = "json"
+	StatementText StatementFormat = "text" // kolom dengan lebar tetap, untuk dicetak / dibaca di terminal
+)
+
+var (
+	ErrUnknownStatementFormat = errors.New("unknown statement format")
+	ErrInvalidStatementPeriod = errors.New("statement period must end after it starts")
+)
+
+// Statement adalah ringkasan mutasi satu wallet pada periode [From, To)
+type Statement struct {
+	WalletID string
+	Owner    string // nama lengkap pemilik wallet
+	Currency money.Currency
+	From     time.Time
+	To       time.Time
+	Opening  money.Money // saldo sebelum From
+	Closing  money.Money // saldo sebelum To
+	Credits  money.Money // total uang masuk
+	Debits   money.Money // total uang keluar (positif)
+	Lines    int
+}
+
+// StatementLine adalah satu mutasi (satu posting ledger milik wallet)
+type StatementLine struct {
+	Time      time.Time
+	JournalID string
+	Kind      string // jenis journal, misalnya transfer atau top_up
+	Reference string
+	Amount    money.Money // positif = masuk, negatif = keluar
+	Balance   money.Money // saldo setelah mutasi ini
+}
+
+// StatementMonth mengembalikan periode satu bulan penuh pada zona waktu loc
+func StatementMonth(year int, month time.Month, loc *time.Location) (from, to time.Time) {
+	from = time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	return from, from.AddDate(0, 1, 0)
+}
+
+// StatementService membuat rekening koran wallet dari ledger (lihat ledger.go)
+type StatementService struct {
+	db *gorm.DB
+}
+
+func NewStatementService(db *gorm.DB) *StatementService {
+	return &StatementService{db: db}
+}
+
+// Export menulis statement wallet untuk periode [from, to) ke w dalam format tersebut.
+//
+// Mutasi dibaca baris per baris dengan Rows() dan langsung ditulis, jadi histori yang panjang
+// tidak pernah dimuat sekaligus ke memory. Waktu ditulis dalam zona waktu milik from.
+func (s *StatementService) Export(ctx context.Context, w io.Writer, format StatementFormat, walletID string, from, to time.Time) (*Statement, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidStatementPeriod
+	}
+	out, err := newStatementWriter(format, w)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &Statement{WalletID: walletID, From: from, To: to}
+	// dalam satu transaction supaya saldo awal dan mutasi dibaca dari data yang sama
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var wallet Wallet
+		if err := tx.Preload("User").Take(&wallet, "id = ?", walletID).Error; err != nil {
+			return notFound(err, ErrWalletNotFound, walletID)
+		}
+		if wallet.User != nil {
+			statement.Owner = wallet.User.Name.Full()
+		}
+		currency := wallet.Balance.Currency
+		statement.Currency = currency
+
+		var opening int64
+		err := tx.Model(&LedgerPosting{}).Select("COALESCE(SUM(amount), 0)").
+			Where("wallet_id = ? AND created_at < ?", walletID, from).Scan(&opening).Error
+		if err != nil {
+			return err
+		}
+		statement.Opening = money.New(opening, currency)
+		statement.Closing, statement.Credits, statement.Debits = statement.Opening, money.New(0, currency), money.New(0, currency)
+		if err := out.Header(statement); err != nil {
+			return err
+		}
+
+		rows, err := tx.Model(&LedgerPosting{}).
+			Select("ledger_postings.created_at AS time", "ledger_postings.journal_id", "ledger_postings.amount",
+				"ledger_journals.kind", "ledger_journals.reference").
+			Joins("JOIN ledger_journals ON ledger_journals.id = ledger_postings.journal_id").
+			Where("ledger_postings.wallet_id = ? AND ledger_postings.created_at >= ? AND ledger_postings.created_at < ?", walletID, from, to).
+			Order("ledger_postings.created_at, ledger_postings.id").
+			Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var row struct {
+				Time      time.Time
+				JournalID string
+				Amount    int64
+				Kind      string
+				Reference string
+			}
+			if err := tx.ScanRows(rows, &row); err != nil {
+				return err
+			}
+
+			amount := money.New(row.Amount, currency)
+			if statement.Closing, err = statement.Closing.Add(amount); err != nil {
+				return err
+			}
+			if amount.IsNegative() {
+				statement.Debits, err = statement.Debits.Sub(amount)
+			} else {
+				statement.Credits, err = statement.Credits.Add(amount)
+			}
+			if err != nil {
+				return err
+			}
+			statement.Lines++
+
+			line := StatementLine{
+				Time:      row.Time.In(from.Location()),
+				JournalID: row.JournalID,
+				Kind:      row.Kind,
+				Reference: row.Reference,
+				Amount:    amount,
+				Balance:   statement.Closing,
+			}
+			if err := out.Line(&line); err != nil {
+				return err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return out.Footer(statement)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statement, nil
+}
+
+// statementWriter menulis statement secara bertahap: header, setiap baris, lalu footer
+type statementWriter interface {
+	Header(statement *Statement) error
+	Line(line *StatementLine) error
+	Footer(statement *Statement) error
+}
+
+func newStatementWriter(format StatementFormat, w io.Writer) (statementWriter, error) {
+	switch format {
+	case StatementCSV:
+		return &csvStatement{w: csv.NewWriter(w)}, nil
+	case StatementJSON:
+		return &jsonStatement{w: bufio.NewWriter(w)}, nil
+	case StatementText:
+		return &textStatement{w: bufio.NewWriter(w)}, nil
+	}
+	return nil, fmt.Errorf("%w %q, use csv, json or text", ErrUnknownStatementFormat, format)
+}
+
+// debitCredit memisahkan mutasi menjadi kolom debit (keluar) dan credit (masuk)
+func debitCredit(amount money.Money) (debit, credit string) {
+	if amount.IsNegative() {
+		return amount.Neg().Decimal(), ""
+	}
+	return "", amount.Decimal()
+}
+
+// ===== csv =====
+
+// csvStatement menulis satu baris per mutasi, diapit baris saldo awal dan saldo akhir
+type csvStatement struct {
+	w *csv.Writer
+}
+
+func (c *csvStatement) Header(statement *Statement) error {
+	if err := c.w.Write([]string{"date", "description", "reference", "journal_id", "debit", "credit", "balance", "currency"}); err != nil {
+		return err
+	}
+	return c.w.Write([]string{statement.From.Format(time.RFC3339), "opening balance", "", "", "", "",
+		statement.Opening.Decimal(), string(statement.Currency)})
+}
+
+func (c *csvStatement) Line(line *StatementLine) error {
+	debit, credit := debitCredit(line.Amount)
+	return c.w.Write([]string{line.Time.Format(time.RFC3339), line.Kind, line.Reference, line.JournalID,
+		debit, credit, line.Balance.Decimal(), string(line.Amount.Currency)})
+}
+
+func (c *csvStatement) Footer(statement *Statement) error {
+	err := c.w.Write([]string{statement.To.Format(time.RFC3339), "closing balance", "", "",
+		statement.Debits.Decimal(), statement.Credits.Decimal(), statement.Closing.Decimal(), string(statement.Currency)})
+	if err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ===== json =====
+
+// jsonStatement menulis satu object JSON; array "lines" ditulis per elemen supaya tetap streaming
+type jsonStatement struct {
+	w     *bufio.Writer
+	lines int
+}
+
+type jsonStatementLine struct {
+	Time      time.Time   `json:"time"`
+	JournalID string      `json:"journal_id"`
+	Kind      string      `json:"kind"`
+	Reference string      `json:"reference"`
+	Amount    json.Number `json:"amount"`
+	Balance   json.Number `json:"balance"`
+}
+
+func (j *jsonStatement) Header(statement *Statement) error {
+	header, err := json.Marshal(struct {
+		WalletID string         `json:"wallet_id"`
+		Owner    string         `json:"owner"`
+		Currency money.Currency `json:"currency"`
+		From     time.Time      `json:"from"`
+		To       time.Time      `json:"to"`
+		Opening  json.Number    `json:"opening"`
+	}{statement.WalletID, statement.Owner, statement.Currency, statement.From, statement.To, json.Number(statement.Opening.Decimal())})
+	if err != nil {
+		return err
+	}
+	// buang "}" penutup, object dilanjutkan dengan "lines" dan ditutup di Footer
+	j.w.Write(header[:len(header)-1])
+	_, err = j.w.WriteString(`,"lines":[`)
+	return err
+}
+
+func (j *jsonStatement) Line(line *StatementLine) error {
+	data, err := json.Marshal(jsonStatementLine{
+		Time:      line.Time,
+		JournalID: line.JournalID,
+		Kind:      line.Kind,
+		Reference: line.Reference,
+		Amount:    json.Number(line.Amount.Decimal()),
+		Balance:   json.Number(line.Balance.Decimal()),
+	})
+	if err != nil {
+		return err
+	}
+	if j.lines > 0 {
+		j.w.WriteByte(',')
+	}
+	j.lines++
+	j.w.WriteString("\n  ")
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonStatement) Footer(statement *Statement) error {
+	footer, err := json.Marshal(struct {
+		Debits  json.Number `json:"debits"`
+		Credits json.Number `json:"credits"`
+		Closing json.Number `json:"closing"`
+		Lines   int         `json:"line_count"`
+	}{json.Number(statement.Debits.Decimal()), json.Number(statement.Credits.Decimal()), json.Number(statement.Closing.Decimal()), statement.Lines})
+	if err != nil {
+		return err
+	}
+	j.w.WriteString("\n],")
+	j.w.Write(footer[1:]) // buang "{" pembuka
+	j.w.WriteByte('\n')
+	return j.w.Flush()
+}
+
+// ===== text (fixed-width) =====
+
+const (
+	textStatementRow   = "%-19s  %-16s  %-26s  %15s  %15s  %15s\n"
+	textStatementWidth = 19 + 16 + 26 + 15*3 + 2*5
+)
+
+type textStatement struct {
+	w *bufio.Writer
+}
+
+// fixed memotong teks yang lebih panjang dari kolomnya, dihitung per rune (sama seperti padding fmt)
+// supaya karakter multi-byte tidak terpotong di tengah
+func fixed(text string, width int) string {
+	if utf8.RuneCountInString(text) > width {
+		return string([]rune(text)[:width-1]) + "~"
+	}
+	return text
+}
+
+func (t *textStatement) Header(statement *Statement) error {
+	fmt.Fprintf(t.w, "STATEMENT WALLET %s  %s\n", statement.WalletID, statement.Owner)
+	fmt.Fprintf(t.w, "PERIOD %s - %s  CURRENCY %s\n\n",
+		statement.From.Format("2006-01-02 15:04:05"), statement.To.Format("2006-01-02 15:04:05"), statement.Currency)
+	fmt.Fprintf(t.w, textStatementRow, "DATE", "DESCRIPTION", "REFERENCE", "DEBIT", "CREDIT", "BALANCE")
+	_, err := fmt.Fprintf(t.w, textStatementRow, statement.From.Format("2006-01-02 15:04:05"), "opening balance", "", "", "",
+		fixed(statement.Opening.Decimal(), 15))
+	return err
+}
+
+func (t *textStatement) Line(line *StatementLine) error {
+	debit, credit := debitCredit(line.Amount)
+	_, err := fmt.Fprintf(t.w, textStatementRow, line.Time.Format("2006-01-02 15:04:05"), fixed(line.Kind, 16),
+		fixed(line.Reference, 26), fixed(debit, 15), fixed(credit, 15), fixed(line.Balance.Decimal(), 15))
+	return err
+}
+
+func (t *textStatement) Footer(statement *Statement) error {
+	fmt.Fprintf(t.w, textStatementRow, statement.To.Format("2006-01-02 15:04:05"), "closing balance", "",
+		fixed(statement.Debits.Decimal(), 15), fixed(statement.Credits.Decimal(), 15), fixed(statement.Closing.Decimal(), 15))
+	fmt.Fprintf(t.w, "%s\n", strings.Repeat("-", textStatementWidth))
+	fmt.Fprintf(t.w, "%d movement(s)\n", statement.Lines)
+	return t.w.Flush()
+}
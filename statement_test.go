@@ -0,0 +1,141 @@
+package learn_golang_gorm
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// newStatementDB menyiapkan wallet 1 dengan saldo awal 40 hari yang lalu, lalu dua mutasi hari ini
+func newStatementDB(t *testing.T) *gorm.DB {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	assert.Nil(t, db.Model(&LedgerPosting{}).Where("1 = 1").Update("created_at", time.Now().AddDate(0, 0, -40)).Error)
+
+	_, err := NewTransferService(db).Transfer(ctx, "1", "2", idr(250000), "rent")
+	assert.Nil(t, err)
+	_, err = NewWalletService(db).TopUp(ctx, "1", idr(50000), "salary")
+	assert.Nil(t, err)
+	return db
+}
+
+func TestStatementCSV(t *testing.T) {
+	db := newStatementDB(t)
+	now := time.Now()
+
+	var out bytes.Buffer
+	statement, err := NewStatementService(db).Export(context.Background(), &out, StatementCSV, "1", now.AddDate(0, 0, -1), now.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, "Lev Tempest Vex", statement.Owner)
+	assert.Equal(t, idr(1000000), statement.Opening)
+	assert.Equal(t, idr(800000), statement.Closing)
+	assert.Equal(t, idr(250000), statement.Debits)
+	assert.Equal(t, idr(50000), statement.Credits)
+	assert.Equal(t, 2, statement.Lines)
+
+	records, err := csv.NewReader(&out).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(records)) // header, saldo awal, 2 mutasi, saldo akhir
+	assert.Equal(t, []string{"opening balance", "1000000"}, []string{records[1][1], records[1][6]})
+	assert.Equal(t, []string{LedgerJournalTransfer, "250000", "", "750000", "IDR"}, append([]string{records[2][1]}, records[2][4:]...))
+	assert.Equal(t, []string{LedgerJournalTopUp, "", "50000", "800000", "IDR"}, append([]string{records[3][1]}, records[3][4:]...))
+	assert.Equal(t, []string{"closing balance", "250000", "50000", "800000"}, append([]string{records[4][1]}, records[4][4:7]...))
+
+	// periode sebelum mutasi hanya berisi saldo awal
+	out.Reset()
+	statement, err = NewStatementService(db).Export(context.Background(), &out, StatementCSV, "1", now.AddDate(0, 0, -50), now.AddDate(0, 0, -1))
+	assert.Nil(t, err)
+	assert.Equal(t, idr(0), statement.Opening)
+	assert.Equal(t, idr(1000000), statement.Closing)
+	assert.Equal(t, 1, statement.Lines)
+}
+
+func TestStatementJSON(t *testing.T) {
+	db := newStatementDB(t)
+	now := time.Now()
+
+	var out bytes.Buffer
+	_, err := NewStatementService(db).Export(context.Background(), &out, StatementJSON, "1", now.AddDate(0, 0, -1), now.Add(time.Hour))
+	assert.Nil(t, err)
+
+	var document struct {
+		WalletID string `json:"wallet_id"`
+		Opening  int64  `json:"opening"`
+		Closing  int64  `json:"closing"`
+		Lines    []struct {
+			Kind    string `json:"kind"`
+			Amount  int64  `json:"amount"`
+			Balance int64  `json:"balance"`
+		} `json:"lines"`
+		LineCount int `json:"line_count"`
+	}
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &document))
+	assert.Equal(t, "1", document.WalletID)
+	assert.Equal(t, int64(1000000), document.Opening)
+	assert.Equal(t, int64(800000), document.Closing)
+	assert.Equal(t, 2, document.LineCount)
+	assert.Equal(t, int64(-250000), document.Lines[0].Amount)
+	assert.Equal(t, int64(800000), document.Lines[1].Balance)
+
+	// tanpa mutasi, "lines" tetap array kosong
+	out.Reset()
+	_, err = NewStatementService(db).Export(context.Background(), &out, StatementJSON, "1", now.Add(time.Hour), now.Add(2*time.Hour))
+	assert.Nil(t, err)
+	assert.True(t, json.Valid(out.Bytes()))
+	assert.Contains(t, out.String(), `"lines":[`)
+}
+
+func TestStatementText(t *testing.T) {
+	db := newStatementDB(t)
+	now := time.Now()
+
+	var out bytes.Buffer
+	_, err := NewStatementService(db).Export(context.Background(), &out, StatementText, "1", now.AddDate(0, 0, -1), now.Add(time.Hour))
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Equal(t, "STATEMENT WALLET 1  Lev Tempest Vex", lines[0])
+	// semua baris tabel punya lebar yang sama
+	for _, line := range lines[3:8] {
+		assert.Equal(t, textStatementWidth, len(line), line)
+	}
+	fields := strings.Fields(lines[5]) // tanggal, jam, jenis, reference, debit, balance
+	assert.Equal(t, []string{LedgerJournalTransfer, "250000", "750000"}, []string{fields[2], fields[4], fields[5]})
+	assert.Equal(t, "2 movement(s)", lines[len(lines)-1])
+}
+
+func TestFixed(t *testing.T) {
+	assert.Equal(t, "payout", fixed("payout", 8))
+	assert.Equal(t, "pembayar~", fixed("pembayaran", 9))
+	// dipotong per rune: hasilnya UTF-8 yang valid dan lebarnya sama dengan kolom
+	cut := fixed("pembayaran café ☕☕☕", 16)
+	assert.True(t, utf8.ValidString(cut))
+	assert.Equal(t, "pembayaran café~", cut)
+}
+
+func TestStatementRejected(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewStatementService(db)
+	from, to := StatementMonth(2024, time.February, time.UTC)
+	assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), to)
+
+	var out bytes.Buffer
+	_, err := service.Export(ctx, &out, "pdf", "1", from, to)
+	assert.ErrorIs(t, err, ErrUnknownStatementFormat)
+	_, err = service.Export(ctx, &out, StatementCSV, "1", to, from)
+	assert.ErrorIs(t, err, ErrInvalidStatementPeriod)
+	_, err = service.Export(ctx, &out, StatementCSV, "404", from, to)
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+	assert.Equal(t, 0, out.Len())
+}
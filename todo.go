@@ -7,8 +7,8 @@ import (
 // model todos
 type Todo struct {
 	gorm.Model
-	UserId      string			`gorm:"column:user_id"`
-	Title       string			`gorm:"column:title"`
+	UserId      string			`gorm:"column:user_id;size:100"`
+	Title       string			`gorm:"column:title;size:100"`
 	Description string			`gorm:"column:description"`
 }
 
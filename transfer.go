@@ -0,0 +1,205 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"learn-golang-gorm/dberrors"
+	"learn-golang-gorm/money"
+	"learn-golang-gorm/txretry"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidAmount          = errors.New("transfer amount must be greater than zero")
+	ErrSameWallet             = errors.New("cannot transfer to the same wallet")
+	ErrWalletNotFound         = errors.New("wallet not found")
+	ErrInsufficientFunds      = errors.New("insufficient funds")
+	ErrMissingIdempotencyKey  = errors.New("idempotency key is required")
+	ErrIdempotencyKeyConflict = errors.New("idempotency key was already used for a different transfer")
+)
+
+// TransferService memindahkan saldo antar wallet
+type TransferService struct {
+	db    *gorm.DB
+	retry *txretry.Runner
+}
+
+func NewTransferService(db *gorm.DB) *TransferService {
+	return &TransferService{db: db, retry: txretry.New(db, txretry.DefaultPolicy)}
+}
+
+// RetryStats mengembalikan jumlah transfer yang diulang karena deadlock atau lock wait timeout
+func (s *TransferService) RetryStats() txretry.Stats {
+	return s.retry.Stats()
+}
+
+// TransferResult berisi baris wallet_transactions yang dibuat oleh satu transfer.
+// Replayed bernilai true jika transfer dengan idempotency key yang sama sudah pernah dijalankan.
+type TransferResult struct {
+	TransferID string
+	Debit      WalletTransaction
+	Credit     WalletTransaction
+	Replayed   bool
+}
+
+// Transfer memindahkan amount dari wallet fromWalletID ke toWalletID dalam satu transaction.
+// Mata uang amount harus sama dengan mata uang kedua wallet (money.ErrCurrencyMismatch),
+// transfer antar mata uang tidak dikonversi otomatis.
+//
+// Perubahan saldo juga dicatat sebagai journal di ledger (lihat ledger.go).
+// Kedua wallet dikunci (SELECT ... FOR UPDATE) dengan urutan ID yang sama untuk setiap pemanggilan,
+// jadi dua transfer berlawanan arah tidak saling deadlock. Saldo tidak boleh minus dan
+// dana yang sedang di-hold tidak bisa ditransfer.
+// Memanggil ulang dengan idempotencyKey yang sama (misalnya retry dari client) tidak memindahkan
+// saldo lagi, hanya mengembalikan hasil transfer sebelumnya.
+// Transaction yang dibatalkan database karena deadlock atau lock wait timeout diulang otomatis (txretry).
+func (s *TransferService) Transfer(ctx context.Context, fromWalletID, toWalletID string, amount money.Money, idempotencyKey string) (*TransferResult, error) {
+	switch {
+	case !amount.IsPositive():
+		return nil, ErrInvalidAmount
+	case fromWalletID == toWalletID:
+		return nil, ErrSameWallet
+	case idempotencyKey == "":
+		return nil, ErrMissingIdempotencyKey
+	}
+
+	var result *TransferResult
+	err := s.retry.Transaction(ctx, func(tx *gorm.DB) error {
+		wallets, err := lockWallets(tx, fromWalletID, toWalletID)
+		if err != nil {
+			return err
+		}
+
+		from, to := wallets[fromWalletID], wallets[toWalletID]
+		for _, wallet := range []*Wallet{from, to} {
+			if err := wallet.Balance.SameCurrency(amount); err != nil {
+				return fmt.Errorf("wallet %s: %w", wallet.ID, err)
+			}
+		}
+
+		// dicek setelah wallet dikunci, jadi request kedua dengan key yang sama menunggu request pertama selesai
+		if result, err = findTransfer(tx, idempotencyKey); err != nil || result != nil {
+			return err
+		}
+
+		// dana yang sedang di-hold (lihat wallet_hold.go) tidak boleh ikut ditransfer
+		available, err := availableBalance(tx, from, time.Now())
+		if err != nil {
+			return err
+		}
+		if cmp, _ := available.Cmp(amount); cmp < 0 {
+			return fmt.Errorf("%w: wallet %s has %s available, need %s", ErrInsufficientFunds, from.ID, available, amount)
+		}
+		fromBalance, err := from.Balance.Sub(amount)
+		if err != nil {
+			return err
+		}
+		toBalance, err := to.Balance.Add(amount)
+		if err != nil {
+			return err
+		}
+
+		transferID, err := IDGenerators.NewID(&WalletTransaction{})
+		if err != nil {
+			return err
+		}
+		from.Balance, to.Balance = fromBalance, toBalance
+		result = &TransferResult{
+			TransferID: transferID,
+			Debit: WalletTransaction{
+				TransferID: transferID, WalletID: from.ID, CounterpartyWalletID: to.ID, Type: WalletTransactionDebit,
+				Amount: amount.Amount, BalanceAfter: from.Balance.Amount, IdempotencyKey: idempotencyKey,
+			},
+			Credit: WalletTransaction{
+				TransferID: transferID, WalletID: to.ID, CounterpartyWalletID: from.ID, Type: WalletTransactionCredit,
+				Amount: amount.Amount, BalanceAfter: to.Balance.Amount, IdempotencyKey: idempotencyKey,
+			},
+		}
+
+		for _, wallet := range []*Wallet{from, to} {
+			if err := tx.Model(wallet).Update("balance_amount", wallet.Balance.Amount).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&[]*WalletTransaction{&result.Debit, &result.Credit}).Error; err != nil {
+			// key yang sama dipakai bersamaan untuk pasangan wallet lain: lock-nya berbeda, jadi tidak saling
+			// menunggu dan baru ditolak oleh index unique (idempotency_key, type)
+			if idempotencyKeyTaken(err) {
+				return ErrIdempotencyKeyConflict
+			}
+			return err
+		}
+		_, err = PostJournal(tx, LedgerJournalTransfer, transferID,
+			WalletPosting(from.ID, -amount.Amount),
+			WalletPosting(to.ID, amount.Amount),
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Replayed && !result.matches(fromWalletID, toWalletID, amount.Amount) {
+		return nil, ErrIdempotencyKeyConflict
+	}
+	return result, nil
+}
+
+// lockWallets mengunci wallet satu per satu, diurutkan berdasarkan ID
+func lockWallets(tx *gorm.DB, ids ...string) (map[string]*Wallet, error) {
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+
+	wallets := make(map[string]*Wallet, len(sorted))
+	for _, id := range sorted {
+		var wallet Wallet
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&wallet, "id = ?", id).Error
+		if err != nil {
+			return nil, notFound(err, ErrWalletNotFound, id)
+		}
+		wallets[id] = &wallet
+	}
+	return wallets, nil
+}
+
+// findTransfer mengembalikan transfer yang sudah ada dengan idempotency key tersebut, atau nil
+func findTransfer(tx *gorm.DB, idempotencyKey string) (*TransferResult, error) {
+	var rows []WalletTransaction
+	if err := tx.Where("idempotency_key = ?", idempotencyKey).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	result := &TransferResult{TransferID: rows[0].TransferID, Replayed: true}
+	for _, row := range rows {
+		if row.Type == WalletTransactionDebit {
+			result.Debit = row
+		} else {
+			result.Credit = row
+		}
+	}
+	return result, nil
+}
+
+// idempotencyKeyTaken mengenali duplicate key dari index uq_wallet_transactions_idempotency_key
+// (MySQL hanya menyebut nama index, SQLite hanya nama kolom)
+func idempotencyKeyTaken(err error) bool {
+	var dbErr *dberrors.Error
+	if !errors.As(err, &dbErr) || !errors.Is(err, dberrors.ErrDuplicateKey) {
+		return false
+	}
+	return dbErr.Constraint == "uq_wallet_transactions_idempotency_key" || slices.Contains(dbErr.Columns, "idempotency_key")
+}
+
+func (r *TransferResult) matches(fromWalletID, toWalletID string, amount int64) bool {
+	return r.Debit.WalletID == fromWalletID && r.Credit.WalletID == toWalletID && r.Debit.Amount == amount
+}
@@ -0,0 +1,202 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"learn-golang-gorm/txretry"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func walletBalance(t *testing.T, service *TransferService, id string) int {
+	t.Helper()
+	var wallet Wallet
+	assert.Nil(t, service.db.Take(&wallet, "id = ?", id).Error)
+	return int(wallet.Balance.Amount)
+}
+
+func TestTransfer(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewTransferService(db)
+
+	result, err := service.Transfer(ctx, "1", "2", idr(250000), "payout-1")
+	assert.Nil(t, err)
+	assert.False(t, result.Replayed)
+	assert.Equal(t, 750000, walletBalance(t, service, "1"))
+	assert.Equal(t, 1250000, walletBalance(t, service, "2"))
+	assert.Equal(t, int64(750000), result.Debit.BalanceAfter)
+	assert.Equal(t, int64(1250000), result.Credit.BalanceAfter)
+
+	var rows []WalletTransaction
+	assert.Nil(t, db.Where("transfer_id = ?", result.TransferID).Order("type desc").Find(&rows).Error)
+	assert.Equal(t, 2, len(rows))
+	assert.Equal(t, WalletTransactionDebit, rows[0].Type)
+	assert.Equal(t, "1", rows[0].WalletID)
+	assert.Equal(t, WalletTransactionCredit, rows[1].Type)
+	assert.Equal(t, "2", rows[1].WalletID)
+
+	// retry dengan key yang sama tidak memindahkan saldo lagi
+	replay, err := service.Transfer(ctx, "1", "2", idr(250000), "payout-1")
+	assert.Nil(t, err)
+	assert.True(t, replay.Replayed)
+	assert.Equal(t, result.TransferID, replay.TransferID)
+	assert.Equal(t, 750000, walletBalance(t, service, "1"))
+
+	_, err = service.Transfer(ctx, "1", "2", idr(1), "payout-1")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyConflict)
+}
+
+func TestTransferIdempotencyKeyRace(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewTransferService(db)
+
+	// transfer 20 -> 21 dengan key yang sama tersimpan setelah findTransfer, seperti request bersamaan
+	// yang mengunci wallet lain
+	raced := false
+	err := db.Callback().Create().Before("gorm:create").Register("test:race", func(tx *gorm.DB) {
+		if tx.Statement.Table != "wallet_transactions" || raced {
+			return
+		}
+		raced = true
+		other := []WalletTransaction{
+			{TransferID: "other", WalletID: "20", CounterpartyWalletID: "21", Type: WalletTransactionDebit, Amount: 10, IdempotencyKey: "race-1"},
+			{TransferID: "other", WalletID: "21", CounterpartyWalletID: "20", Type: WalletTransactionCredit, Amount: 10, IdempotencyKey: "race-1"},
+		}
+		tx.AddError(tx.Session(&gorm.Session{NewDB: true}).Create(&other).Error)
+	})
+	assert.Nil(t, err)
+
+	_, err = service.Transfer(ctx, "1", "2", idr(100), "race-1")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyConflict)
+	assert.Equal(t, 1000000, walletBalance(t, service, "1"))
+	assert.Equal(t, 1000000, walletBalance(t, service, "2"))
+}
+
+func TestTransferRetriedOnDeadlock(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewTransferService(db)
+
+	// update saldo pertama dibatalkan seperti deadlock di MySQL, seluruh transaction diulang
+	deadlocks := 1
+	err := db.Callback().Update().Before("gorm:update").Register("test:deadlock", func(tx *gorm.DB) {
+		if tx.Statement.Table == "wallets" && deadlocks > 0 {
+			deadlocks--
+			tx.AddError(&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})
+		}
+	})
+	assert.Nil(t, err)
+
+	result, err := service.Transfer(ctx, "1", "2", idr(100000), "retry-1")
+	assert.Nil(t, err)
+	assert.False(t, result.Replayed)
+	assert.Equal(t, 900000, walletBalance(t, service, "1"))
+	assert.Equal(t, 1100000, walletBalance(t, service, "2"))
+	assert.Equal(t, map[string]int64{txretry.ReasonDeadlock: 1}, service.RetryStats().Retries)
+
+	var count int64
+	assert.Nil(t, db.Model(&WalletTransaction{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+	report, err := NewLedger(db).Reconcile(ctx, RepairNone)
+	assert.Nil(t, err)
+	assert.True(t, report.OK())
+}
+
+func TestTransferRejected(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewTransferService(db)
+
+	_, err := service.Transfer(ctx, "1", "2", idr(1000001), "overdraft")
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	_, err = service.Transfer(ctx, "1", "2", idr(0), "zero")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = service.Transfer(ctx, "1", "1", idr(10), "same")
+	assert.ErrorIs(t, err, ErrSameWallet)
+	_, err = service.Transfer(ctx, "1", "404", idr(10), "missing")
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+	_, err = service.Transfer(ctx, "1", "2", idr(10), "")
+	assert.ErrorIs(t, err, ErrMissingIdempotencyKey)
+
+	// tidak ada saldo yang berubah dan tidak ada baris yang tertulis
+	assert.Equal(t, 1000000, walletBalance(t, service, "1"))
+	assert.Equal(t, 1000000, walletBalance(t, service, "2"))
+	var count int64
+	assert.Nil(t, db.Model(&WalletTransaction{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestConcurrentTransfers(t *testing.T) {
+	ctx := context.Background()
+	db := newConcurrentTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewTransferService(db)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded, insufficient := 0, 0
+
+	// 50 x 30.000 dari wallet 20 yang hanya punya 1.000.000: tepat 33 yang berhasil
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := service.Transfer(ctx, "20", "21", idr(30000), fmt.Sprint("drain-", i))
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case assert.ErrorIs(t, err, ErrInsufficientFunds):
+				insufficient++
+			}
+		}(i)
+	}
+
+	// transfer berlawanan arah antara wallet 1 dan 2 (kasus klasik deadlock jika urutan lock berbeda)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			from, to := "1", "2"
+			if i%2 == 1 {
+				from, to = to, from
+			}
+			_, err := service.Transfer(ctx, from, to, idr(1000), fmt.Sprint("swap-", i))
+			assert.Nil(t, err)
+		}(i)
+	}
+
+	// request yang sama dikirim berkali-kali: hanya satu yang memindahkan saldo
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.Transfer(ctx, "2", "1", idr(5000), "duplicate")
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 33, succeeded)
+	assert.Equal(t, 17, insufficient)
+	assert.Equal(t, 10000, walletBalance(t, service, "20"))
+	assert.Equal(t, 1990000, walletBalance(t, service, "21"))
+	assert.Equal(t, 1005000, walletBalance(t, service, "1"))
+	assert.Equal(t, 995000, walletBalance(t, service, "2"))
+
+	var count int64
+	assert.Nil(t, db.Model(&WalletTransaction{}).Count(&count).Error)
+	assert.Equal(t, int64((33+20+1)*2), count)
+}
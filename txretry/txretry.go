@@ -0,0 +1,215 @@
+// Package txretry menjalankan ulang seluruh transaction jika database membatalkannya karena bentrok
+// dengan transaction lain: deadlock, lock wait timeout, serialization failure atau database terkunci (SQLite).
+//
+//	runner := txretry.New(db, txretry.DefaultPolicy)
+//	err := runner.Transaction(ctx, func(tx *gorm.DB) error {
+//		// SELECT ... FOR UPDATE, UPDATE, ... seperti biasa
+//	})
+//
+// Fungsi transaction bisa dijalankan lebih dari sekali, jadi jangan mengubah state di luar database
+// di dalamnya (kirim email dan sejenisnya lewat uow.AfterCommit). Error lain dikembalikan apa adanya.
+package txretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"learn-golang-gorm/dberrors"
+	"learn-golang-gorm/uow"
+
+	"gorm.io/gorm"
+)
+
+// ErrRetriesExhausted dibungkus bersama error terakhir jika semua percobaan gagal
+var ErrRetriesExhausted = errors.New("txretry: transaction still conflicting after all attempts")
+
+// Alasan retry, dipakai sebagai key Stats.Retries
+const (
+	ReasonDeadlock      = "deadlock"
+	ReasonLockTimeout   = "lock_timeout" // termasuk SQLITE_BUSY / SQLITE_LOCKED
+	ReasonSerialization = "serialization_failure"
+)
+
+// Classify mengembalikan alasan retry untuk error dari driver mysql, postgres atau sqlite
+// (diterjemahkan oleh dberrors), dan false jika error tersebut tidak layak di-retry
+func Classify(err error) (reason string, retryable bool) {
+	err = dberrors.Translate(err)
+	switch {
+	case errors.Is(err, dberrors.ErrDeadlock):
+		return ReasonDeadlock, true
+	case errors.Is(err, dberrors.ErrLockTimeout):
+		return ReasonLockTimeout, true
+	case errors.Is(err, dberrors.ErrSerialization):
+		return ReasonSerialization, true
+	}
+	return "", false
+}
+
+// Policy mengatur berapa kali dan seberapa lama transaction diulang
+type Policy struct {
+	MaxAttempts int           // jumlah percobaan termasuk yang pertama, minimal 1
+	BaseDelay   time.Duration // jeda sebelum retry pertama, lalu dikali 2 setiap retry
+	MaxDelay    time.Duration // batas atas jeda
+	// Retryable menentukan error yang di-retry, default Classify
+	Retryable func(err error) (reason string, retryable bool)
+}
+
+var DefaultPolicy = Policy{
+	MaxAttempts: 5,
+	BaseDelay:   20 * time.Millisecond,
+	MaxDelay:    time.Second,
+}
+
+// backoff adalah jeda sebelum percobaan ke-(retry+1): exponential dengan jitter antara 50% dan 100%,
+// supaya transaction yang bentrok tidak mencoba lagi pada saat yang sama
+func (p Policy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay << (retry - 1)
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// Stats adalah ringkasan metrics sebuah Runner
+type Stats struct {
+	Transactions int64            // jumlah pemanggilan Transaction / Do
+	Attempts     int64            // jumlah percobaan, termasuk retry
+	Retries      map[string]int64 // jumlah retry per alasan
+	Exhausted    int64            // transaction yang tetap gagal setelah MaxAttempts
+}
+
+// TotalRetries menjumlahkan retry dari semua alasan
+func (s Stats) TotalRetries() int64 {
+	var total int64
+	for _, n := range s.Retries {
+		total += n
+	}
+	return total
+}
+
+// Runner menjalankan transaction dengan retry. Aman dipakai bersamaan dari banyak goroutine.
+type Runner struct {
+	db     *gorm.DB
+	policy Policy
+	// OnRetry dipanggil sebelum menunggu backoff, misalnya untuk log
+	OnRetry func(ctx context.Context, attempt int, reason string, err error, delay time.Duration)
+
+	mu    sync.Mutex
+	stats Stats
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func New(db *gorm.DB, policy Policy) *Runner {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = Classify
+	}
+	return &Runner{db: db, policy: policy, stats: Stats{Retries: map[string]int64{}}, sleep: sleep}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Transaction menjalankan fn di dalam db.Transaction dan mengulangnya jika gagal karena bentrok.
+// Jika ctx sudah membawa transaction dari uow, fn dijalankan di savepoint tanpa retry:
+// transaction terluar sudah dibatalkan database, jadi hanya pemanggil terluar yang bisa mengulang.
+func (r *Runner) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.run(ctx, func() error {
+		return uow.DB(ctx, r.db).Transaction(fn)
+	})
+}
+
+// Do sama seperti Transaction, dengan transaction yang dibawa lewat ctx (uow.UnitOfWork)
+func (r *Runner) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	work := uow.New(r.db)
+	return r.run(ctx, func() error {
+		return work.Do(ctx, fn)
+	})
+}
+
+func (r *Runner) run(ctx context.Context, attempt func() error) error {
+	r.record(func(s *Stats) { s.Transactions++ })
+	maxAttempts := r.policy.MaxAttempts
+	if uow.InTransaction(ctx) {
+		maxAttempts = 1
+	}
+
+	for i := 1; ; i++ {
+		r.record(func(s *Stats) { s.Attempts++ })
+		err := attempt()
+		if err == nil {
+			return nil
+		}
+		reason, retryable := r.policy.Retryable(err)
+		if !retryable {
+			return err
+		}
+		if i >= maxAttempts {
+			if maxAttempts == 1 {
+				return err // tidak pernah di-retry, biarkan pemanggil terluar yang mengulang
+			}
+			r.record(func(s *Stats) { s.Exhausted++ })
+			return fmt.Errorf("%w (%d attempts): %w", ErrRetriesExhausted, i, err)
+		}
+
+		delay := r.policy.backoff(i)
+		r.record(func(s *Stats) { s.Retries[reason]++ })
+		if r.OnRetry != nil {
+			r.OnRetry(ctx, i, reason, err, delay)
+		}
+		if err := r.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *Runner) record(update func(s *Stats)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	update(&r.stats)
+}
+
+// Stats mengembalikan salinan metrics sejak Runner dibuat
+func (r *Runner) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.stats
+	stats.Retries = make(map[string]int64, len(r.stats.Retries))
+	for reason, n := range r.stats.Retries {
+		stats.Retries[reason] = n
+	}
+	return stats
+}
+
+// String menulis metrics dalam satu baris, misalnya untuk log berkala
+func (s Stats) String() string {
+	reasons := make([]string, 0, len(s.Retries))
+	for reason := range s.Retries {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	text := fmt.Sprintf("transactions=%d attempts=%d exhausted=%d", s.Transactions, s.Attempts, s.Exhausted)
+	for _, reason := range reasons {
+		text += fmt.Sprintf(" retries.%s=%d", reason, s.Retries[reason])
+	}
+	return text
+}
@@ -0,0 +1,227 @@
+package txretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"learn-golang-gorm/internal/testdb"
+	"learn-golang-gorm/uow"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type item struct {
+	ID   int `gorm:"primaryKey"`
+	Name string
+}
+
+func count(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var n int64
+	assert.Nil(t, db.Model(&item{}).Count(&n).Error)
+	return n
+}
+
+// newRunner tidak benar-benar menunggu, jeda backoff dicatat di delays
+func newRunner(db *gorm.DB, policy Policy, delays *[]time.Duration) *Runner {
+	runner := New(db, policy)
+	runner.sleep = func(ctx context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return ctx.Err()
+	}
+	return runner
+}
+
+var deadlock = &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock; try restarting transaction"}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		err       error
+		reason    string
+		retryable bool
+	}{
+		{deadlock, ReasonDeadlock, true},
+		{&mysql.MySQLError{Number: 1205}, ReasonLockTimeout, true},
+		{&mysql.MySQLError{Number: 1062}, "", false}, // duplicate entry
+		{&pgconn.PgError{Code: "40P01"}, ReasonDeadlock, true},
+		{&pgconn.PgError{Code: "40001"}, ReasonSerialization, true},
+		{&pgconn.PgError{Code: "55P03"}, ReasonLockTimeout, true},
+		{&pgconn.PgError{Code: "23505"}, "", false},
+		{sqlite3.Error{Code: sqlite3.ErrBusy}, ReasonLockTimeout, true},
+		{sqlite3.Error{Code: sqlite3.ErrLocked}, ReasonLockTimeout, true},
+		{sqlite3.Error{Code: sqlite3.ErrConstraint}, "", false},
+		{fmt.Errorf("wallet 1: %w", deadlock), ReasonDeadlock, true}, // error yang dibungkus
+		{gorm.ErrRecordNotFound, "", false},
+		{nil, "", false},
+	}
+	for _, test := range tests {
+		reason, retryable := Classify(test.err)
+		assert.Equal(t, test.reason, reason, "%v", test.err)
+		assert.Equal(t, test.retryable, retryable, "%v", test.err)
+	}
+}
+
+func TestRetryUntilSuccess(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Memory(t, &item{})
+	var delays []time.Duration
+	runner := newRunner(db, Policy{MaxAttempts: 5, BaseDelay: 10 * time.Millisecond, MaxDelay: 25 * time.Millisecond}, &delays)
+	var retried []string
+	runner.OnRetry = func(ctx context.Context, attempt int, reason string, err error, delay time.Duration) {
+		retried = append(retried, fmt.Sprintf("%d:%s", attempt, reason))
+	}
+
+	attempts := 0
+	err := runner.Transaction(ctx, func(tx *gorm.DB) error {
+		attempts++
+		if err := tx.Create(&item{ID: attempts, Name: "item"}).Error; err != nil {
+			return err
+		}
+		switch attempts {
+		case 1:
+			return deadlock
+		case 2:
+			return &pgconn.PgError{Code: "55P03"}
+		case 3:
+			return fmt.Errorf("lock wallet: %w", deadlock)
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, attempts)
+	assert.Equal(t, int64(1), count(t, db)) // percobaan yang gagal di-rollback
+	assert.Equal(t, []string{"1:deadlock", "2:lock_timeout", "3:deadlock"}, retried)
+
+	// jeda 10ms, 20ms lalu dibatasi 25ms, masing-masing dengan jitter 50% - 100%
+	for i, max := range []time.Duration{10, 20, 25} {
+		max *= time.Millisecond
+		assert.True(t, delays[i] >= max/2 && delays[i] <= max, "delay %d: %s", i, delays[i])
+	}
+
+	stats := runner.Stats()
+	assert.Equal(t, int64(1), stats.Transactions)
+	assert.Equal(t, int64(4), stats.Attempts)
+	assert.Equal(t, int64(3), stats.TotalRetries())
+	assert.Equal(t, map[string]int64{ReasonDeadlock: 2, ReasonLockTimeout: 1}, stats.Retries)
+	assert.Equal(t, "transactions=1 attempts=4 exhausted=0 retries.deadlock=2 retries.lock_timeout=1", stats.String())
+}
+
+func TestNonRetryableAndExhausted(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Memory(t, &item{})
+	var delays []time.Duration
+	runner := newRunner(db, Policy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, &delays)
+
+	// error lain dikembalikan apa adanya tanpa retry
+	failed := errors.New("insufficient funds")
+	attempts := 0
+	err := runner.Transaction(ctx, func(tx *gorm.DB) error {
+		attempts++
+		return failed
+	})
+	assert.Equal(t, failed, err)
+	assert.Equal(t, 1, attempts)
+
+	attempts = 0
+	err = runner.Transaction(ctx, func(tx *gorm.DB) error {
+		attempts++
+		return deadlock
+	})
+	assert.ErrorIs(t, err, ErrRetriesExhausted)
+	assert.ErrorIs(t, err, deadlock)
+	var mysqlErr *mysql.MySQLError
+	assert.True(t, errors.As(err, &mysqlErr))
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 2, len(delays))
+
+	stats := runner.Stats()
+	assert.Equal(t, int64(2), stats.Transactions)
+	assert.Equal(t, int64(4), stats.Attempts)
+	assert.Equal(t, int64(1), stats.Exhausted)
+
+	// Stats adalah salinan
+	stats.Retries[ReasonDeadlock] = 100
+	assert.Equal(t, int64(2), runner.Stats().Retries[ReasonDeadlock])
+}
+
+func TestDoAndNestedUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Memory(t, &item{})
+	var delays []time.Duration
+	runner := newRunner(db, DefaultPolicy, &delays)
+
+	attempts := 0
+	err := runner.Do(ctx, func(ctx context.Context) error {
+		attempts++
+		if err := uow.DB(ctx, db).Create(&item{ID: attempts}).Error; err != nil {
+			return err
+		}
+		if attempts == 1 {
+			return deadlock
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, int64(1), count(t, db))
+
+	// di dalam transaction lain tidak di-retry, error diteruskan ke pemanggil terluar
+	attempts = 0
+	err = uow.New(db).Do(ctx, func(ctx context.Context) error {
+		return runner.Transaction(ctx, func(tx *gorm.DB) error {
+			attempts++
+			return deadlock
+		})
+	})
+	assert.Equal(t, deadlock, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, int64(0), runner.Stats().Exhausted)
+}
+
+func TestContextCanceledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	db := testdb.Memory(t, &item{})
+	runner := New(db, Policy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour})
+	runner.OnRetry = func(context.Context, int, string, error, time.Duration) { cancel() }
+
+	attempts := 0
+	err := runner.Transaction(ctx, func(tx *gorm.DB) error {
+		attempts++
+		return deadlock
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, attempts)
+}
+
+// database SQLite yang sedang ditulis koneksi lain mengembalikan SQLITE_BUSY (tanpa busy timeout)
+func TestRetrySQLiteBusy(t *testing.T) {
+	ctx := context.Background()
+	dsn := "file:" + filepath.Join(t.TempDir(), "busy.db") + "?_txlock=immediate&_busy_timeout=0"
+	db := testdb.Open(t, dsn, &item{})
+	other := testdb.Open(t, dsn, &item{})
+
+	blocking := other.Begin()
+	assert.Nil(t, blocking.Error)
+	assert.Nil(t, blocking.Create(&item{ID: 1}).Error)
+
+	runner := New(db, Policy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	var reasons []string
+	runner.OnRetry = func(ctx context.Context, attempt int, reason string, err error, delay time.Duration) {
+		reasons = append(reasons, reason)
+		blocking.Commit() // koneksi lain selesai, percobaan berikutnya berhasil
+	}
+	err := runner.Transaction(ctx, func(tx *gorm.DB) error {
+		return tx.Create(&item{ID: 2}).Error
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ReasonLockTimeout}, reasons)
+	assert.Equal(t, int64(2), count(t, db))
+}
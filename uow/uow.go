@@ -0,0 +1,131 @@
+// Package uow (unit of work) menjalankan beberapa operasi dalam satu transaction yang dibawa lewat
+// context.Context, jadi repository dan service tidak perlu menerima *gorm.DB transaction secara eksplisit.
+//
+//	work := uow.New(db)
+//	err := work.Do(ctx, func(ctx context.Context) error {
+//		if err := users.Create(ctx, &user); err != nil { // ikut transaction dari ctx
+//			return err
+//		}
+//		uow.AfterCommit(ctx, func(ctx context.Context) { sendWelcomeEmail(user) })
+//		return wallets.Create(ctx, &wallet)
+//	})
+//
+// Do di dalam Do memakai savepoint: error di dalamnya hanya membatalkan perubahan sejak savepoint itu.
+// Callback AfterCommit hanya dijalankan setelah transaction terluar berhasil di-commit.
+//
+// Kode yang memakai database cukup mengambil koneksinya dengan DB(ctx, db): transaction dari ctx jika ada,
+// atau db biasa jika tidak. Satu transaction hanya punya satu koneksi, jadi ctx dari Do tidak boleh
+// dipakai oleh beberapa goroutine sekaligus.
+package uow
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// state adalah transaction yang sedang berjalan beserta callback AfterCommit-nya
+type state struct {
+	tx          *gorm.DB
+	savepoints  int
+	afterCommit []func(ctx context.Context)
+}
+
+func current(ctx context.Context) (*state, bool) {
+	s, ok := ctx.Value(txKey{}).(*state)
+	return s, ok
+}
+
+// UnitOfWork membuka transaction baru dari db, atau savepoint jika ctx sudah membawa transaction
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Do menjalankan fn di dalam transaction. Transaction di-commit jika fn mengembalikan nil,
+// dan di-rollback jika fn mengembalikan error atau panic (panic-nya diteruskan).
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if s, ok := current(ctx); ok {
+		return s.nested(ctx, fn)
+	}
+
+	tx := u.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	s := &state{tx: tx}
+	done := false
+	defer func() {
+		if !done || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{}, s))
+	done = true
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit().Error; err != nil {
+		return err
+	}
+	for _, callback := range s.afterCommit {
+		callback(ctx)
+	}
+	return nil
+}
+
+// nested menjalankan fn di dalam savepoint. Callback AfterCommit yang didaftarkan fn ikut dibatalkan
+// jika savepoint di-rollback.
+func (s *state) nested(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	s.savepoints++
+	name := fmt.Sprintf("uow_%d", s.savepoints)
+	if err := s.tx.SavePoint(name).Error; err != nil {
+		return err
+	}
+	callbacks := len(s.afterCommit)
+	done := false
+	defer func() {
+		if !done || err != nil {
+			s.tx.RollbackTo(name)
+			s.afterCommit = s.afterCommit[:callbacks]
+		}
+	}()
+
+	err = fn(ctx)
+	done = true
+	return err
+}
+
+// DB mengembalikan transaction dari ctx, atau db jika ctx tidak membawa transaction.
+// Keduanya sudah memakai ctx (WithContext).
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if s, ok := current(ctx); ok {
+		return s.tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// InTransaction bernilai true jika ctx berasal dari UnitOfWork.Do
+func InTransaction(ctx context.Context) bool {
+	_, ok := current(ctx)
+	return ok
+}
+
+// AfterCommit mendaftarkan fn untuk dijalankan setelah transaction terluar di-commit
+// (misalnya mengirim email atau event). Tanpa transaction, fn langsung dijalankan.
+// fn menerima ctx tanpa transaction.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	s, ok := current(ctx)
+	if !ok {
+		fn(ctx)
+		return
+	}
+	s.afterCommit = append(s.afterCommit, fn)
+}
@@ -0,0 +1,124 @@
+package uow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"learn-golang-gorm/internal/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type item struct {
+	ID   int `gorm:"primaryKey"`
+	Name string
+}
+
+func openSQLite(t *testing.T) *gorm.DB {
+	return testdb.Memory(t, &item{})
+}
+
+// create memakai koneksi dari ctx, seperti repository
+func create(ctx context.Context, db *gorm.DB, id int) error {
+	return DB(ctx, db).Create(&item{ID: id, Name: "item"}).Error
+}
+
+func ids(t *testing.T, db *gorm.DB) []int {
+	t.Helper()
+	var ids []int
+	assert.Nil(t, db.Model(&item{}).Order("id").Pluck("id", &ids).Error)
+	return ids
+}
+
+func TestCommitAndRollback(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	work := New(db)
+
+	err := work.Do(ctx, func(ctx context.Context) error {
+		assert.True(t, InTransaction(ctx))
+		if err := create(ctx, db, 1); err != nil {
+			return err
+		}
+		return create(ctx, db, 2)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2}, ids(t, db))
+
+	failed := errors.New("failed")
+	err = work.Do(ctx, func(ctx context.Context) error {
+		if err := create(ctx, db, 3); err != nil {
+			return err
+		}
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.Equal(t, []int{1, 2}, ids(t, db))
+	assert.False(t, InTransaction(ctx))
+}
+
+func TestRollbackOnPanic(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		New(db).Do(ctx, func(ctx context.Context) error {
+			create(ctx, db, 1)
+			panic("boom")
+		})
+	})
+	assert.Empty(t, ids(t, db))
+}
+
+func TestNestedSavepoint(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	work := New(db)
+	var committed []string
+
+	err := work.Do(ctx, func(ctx context.Context) error {
+		create(ctx, db, 1)
+		AfterCommit(ctx, func(context.Context) { committed = append(committed, "outer") })
+
+		// savepoint yang gagal hanya membatalkan perubahan dan callback di dalamnya
+		err := work.Do(ctx, func(ctx context.Context) error {
+			create(ctx, db, 2)
+			AfterCommit(ctx, func(context.Context) { committed = append(committed, "rolled back") })
+			return errors.New("inner failed")
+		})
+		assert.NotNil(t, err)
+
+		return work.Do(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func(ctx context.Context) {
+				assert.False(t, InTransaction(ctx))
+				committed = append(committed, "inner")
+			})
+			return create(ctx, db, 3)
+		})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 3}, ids(t, db))
+	assert.Equal(t, []string{"outer", "inner"}, committed)
+}
+
+func TestAfterCommitSkippedOnRollback(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	called := false
+
+	err := New(db).Do(ctx, func(ctx context.Context) error {
+		AfterCommit(ctx, func(context.Context) { called = true })
+		// savepoint berhasil, tetapi transaction terluar gagal
+		New(db).Do(ctx, func(ctx context.Context) error { return create(ctx, db, 1) })
+		return errors.New("outer failed")
+	})
+	assert.NotNil(t, err)
+	assert.False(t, called)
+	assert.Empty(t, ids(t, db))
+
+	// tanpa transaction callback langsung dijalankan
+	AfterCommit(ctx, func(context.Context) { called = true })
+	assert.True(t, called)
+}
@@ -1,6 +1,7 @@
 package learn_golang_gorm
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,9 +11,10 @@ import (
 // kita tidak perlu mendefinisikan nama tabel dan juga nama kolom (opsional).
 // GORM akan secara otomatis menggunakan nama struct sebagai nama tabel dan nama field sebagai nama kolom
 type User struct {
-	ID       string 
+	ID       string 	`gorm:"primaryKey;size:100"`
 	Name     Name 		`gorm:"embedded"`
-	Password string
+	Password string 	`gorm:"size:100" json:"-"` // berisi hash, lihat user_password.go
+	hashed   string 	// hash terakhir yang dibaca / disimpan, untuk mendeteksi perubahan Password
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Wallet   	Wallet 		`gorm:"foreignKey:user_id;references:id"`
@@ -21,17 +23,23 @@ type User struct {
 	// ====================> `gorm:"many2many: nama_table_penghubung; foreignKey: nama_kolom_penghubung; joinForeignKey: nama_kolom_dari_tabel_1; references: nama_kolom_dari_tabel_1; joinReferences: nama_kolom_dari_tabel_2"` 
 }
 
+// ID dibuat oleh generator yang terdaftar di IDGenerators (lihat id.go), password di-hash (lihat user_password.go)
 func (u *User) BeforeCreate(db *gorm.DB) error {
-	if u.ID == "" {
-		u.ID = "user-" + time.Now().Format("20060102150405")
+	if err := u.hashPassword(); err != nil {
+		return err
 	}
-	return nil
+	return assignID(u, &u.ID)
 }
 
 type Name struct{
-	FirstName 	string
-	MiddleName 	string
-	LastName  	string
+	FirstName 	string	`gorm:"size:255"`
+	MiddleName 	string	`gorm:"size:100"`
+	LastName  	string	`gorm:"size:100"`
+}
+
+// Full menggabungkan nama depan, tengah dan belakang yang terisi
+func (n Name) Full() string {
+	return strings.Join(strings.Fields(n.FirstName+" "+n.MiddleName+" "+n.LastName), " ")
 }
 
 // jika ingin mendefinisikan nama tabel
@@ -40,9 +48,12 @@ type Name struct{
 // }
 
 type UserLog struct {
-	ID 			int 	`gorm:"primary_key;autoIncrement"`
-	UserId 		string	`gorm:"column:user_id"`
-	Action 		string
-	CreatedAt 	int64	`gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt 	int64	`gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	ID 			int 	`gorm:"primary_key;autoIncrement" json:"id"`
+	UserId 		string	`gorm:"column:user_id;size:100" json:"user_id"`
+	Action 		string	`gorm:"size:100" json:"action"`
+	Table 		string	`gorm:"column:table_name;size:100" json:"table_name"`	// diisi oleh AuditPlugin
+	RecordId 	string	`gorm:"column:record_id;size:100" json:"record_id"`
+	Changes 	*string	`gorm:"column:changes" json:"changes"`			// JSON, lihat AuditPlugin
+	CreatedAt 	int64	`gorm:"column:created_at;autoCreateTime:milli" json:"created_at"`
+	UpdatedAt 	int64	`gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli" json:"updated_at"`
 }
\ No newline at end of file
@@ -0,0 +1,210 @@
+package learn_golang_gorm
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultUserLogPageSize     = 50
+	MaxUserLogPageSize         = 1000
+	DefaultUserLogArchiveBatch = 500
+)
+
+var ErrInvalidRetention = errors.New("user log retention must keep at least 1 day")
+
+// UserLogFilter memilih UserLog. Field kosong / zero value tidak dipakai sebagai filter.
+type UserLogFilter struct {
+	UserID   string
+	Actions  []string // misalnya AuditUpdate, AuditDelete
+	Table    string
+	RecordID string
+	From     time.Time // created_at >= From
+	To       time.Time // created_at < To
+}
+
+// scope mengubah filter menjadi kondisi query (dipakai lewat db.Scopes)
+func (f UserLogFilter) scope(db *gorm.DB) *gorm.DB {
+	if f.UserID != "" {
+		db = db.Where("user_id = ?", f.UserID)
+	}
+	if len(f.Actions) > 0 {
+		db = db.Where("action IN ?", f.Actions)
+	}
+	if f.Table != "" {
+		db = db.Where("table_name = ?", f.Table)
+	}
+	if f.RecordID != "" {
+		db = db.Where("record_id = ?", f.RecordID)
+	}
+	// created_at disimpan dalam epoch milidetik (autoCreateTime:milli)
+	if !f.From.IsZero() {
+		db = db.Where("created_at >= ?", f.From.UnixMilli())
+	}
+	if !f.To.IsZero() {
+		db = db.Where("created_at < ?", f.To.UnixMilli())
+	}
+	return db
+}
+
+// UserLogPage adalah satu halaman hasil Find, paling baru lebih dulu
+type UserLogPage struct {
+	Logs []UserLog
+	// Next dipakai sebagai before untuk halaman berikutnya, 0 jika sudah halaman terakhir
+	Next int
+}
+
+// UserLogRepository membaca tabel user_logs dan menjalankan retention-nya
+type UserLogRepository struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+func NewUserLogRepository(db *gorm.DB) *UserLogRepository {
+	return &UserLogRepository{db: db, now: time.Now}
+}
+
+// Find mengembalikan log yang cocok dengan filter, paling baru lebih dulu.
+// Halaman berikutnya diambil dengan before = page.Next (keyset pagination berdasarkan id,
+// jadi tetap cepat di halaman yang jauh dan tidak bergeser saat ada log baru).
+func (r *UserLogRepository) Find(ctx context.Context, filter UserLogFilter, limit int, before int) (*UserLogPage, error) {
+	switch {
+	case limit <= 0:
+		limit = DefaultUserLogPageSize
+	case limit > MaxUserLogPageSize:
+		limit = MaxUserLogPageSize
+	}
+
+	query := r.db.WithContext(ctx).Scopes(filter.scope)
+	if before > 0 {
+		query = query.Where("id < ?", before)
+	}
+	page := &UserLogPage{}
+	// ambil satu baris lebih untuk mengetahui apakah masih ada halaman berikutnya
+	if err := query.Order("id DESC").Limit(limit + 1).Find(&page.Logs).Error; err != nil {
+		return nil, err
+	}
+	if len(page.Logs) > limit {
+		page.Logs = page.Logs[:limit]
+		page.Next = page.Logs[limit-1].ID
+	}
+	return page, nil
+}
+
+// Count menghitung log yang cocok dengan filter
+func (r *UserLogRepository) Count(ctx context.Context, filter UserLogFilter) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&UserLog{}).Scopes(filter.scope).Count(&count).Error
+	return count, err
+}
+
+// Archive menulis log yang lebih lama dari before ke w (JSON Lines, dikompres gzip), lalu menghapusnya.
+// Log diproses per batch: setiap batch ditulis dan di-flush lebih dulu (termasuk Sync jika w adalah file),
+// baru dihapus di transaction tersendiri, jadi log tidak pernah terhapus sebelum masuk ke arsip dan tabel
+// tidak terkunci lama. Jika gagal di tengah jalan, gzip tetap ditutup sehingga arsip batch yang sudah
+// dihapus tetap bisa dibaca; batch terakhir bisa ikut tertulis meskipun belum dihapus.
+func (r *UserLogRepository) Archive(ctx context.Context, w io.Writer, before time.Time, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultUserLogArchiveBatch
+	}
+	zw := gzip.NewWriter(w)
+	archived, err := r.archiveBatches(r.db.WithContext(ctx), w, zw, before, batchSize)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	return archived, err
+}
+
+func (r *UserLogRepository) archiveBatches(db *gorm.DB, w io.Writer, zw *gzip.Writer, before time.Time, batchSize int) (int64, error) {
+	out := bufio.NewWriter(zw)
+	encoder := json.NewEncoder(out)
+	syncer, _ := w.(interface{ Sync() error })
+
+	var archived int64
+	for {
+		var logs []UserLog
+		err := db.Where("created_at < ?", before.UnixMilli()).Order("id").Limit(batchSize).Find(&logs).Error
+		if err != nil {
+			return archived, err
+		}
+		if len(logs) == 0 {
+			return archived, nil
+		}
+
+		ids := make([]int, len(logs))
+		for i := range logs {
+			if err := encoder.Encode(&logs[i]); err != nil {
+				return archived, err
+			}
+			ids[i] = logs[i].ID
+		}
+		if err := out.Flush(); err != nil {
+			return archived, err
+		}
+		if err := zw.Flush(); err != nil {
+			return archived, err
+		}
+		// tanpa Sync, batch yang sudah dihapus bisa hilang jika server mati sebelum page cache ditulis ke disk
+		if syncer != nil {
+			if err := syncer.Sync(); err != nil {
+				return archived, err
+			}
+		}
+
+		result := db.Delete(&UserLog{}, ids)
+		if result.Error != nil {
+			return archived, fmt.Errorf("delete archived user logs: %w", result.Error)
+		}
+		archived += result.RowsAffected
+	}
+}
+
+// RetentionResult adalah hasil ApplyRetention
+type RetentionResult struct {
+	Archived int64
+	File     string // kosong jika tidak ada log yang diarsipkan
+}
+
+// ApplyRetention mengarsipkan log yang lebih lama dari days hari ke file baru di dir
+// (user_logs_<waktu batas>.jsonl.gz), lalu menghapusnya. Cocok dijalankan sebagai cron job.
+// Jika gagal setelah file dibuat, result tetap dikembalikan bersama error, berisi path file arsip
+// (sebagian) dan jumlah log yang sudah diarsipkan dan dihapus.
+func (r *UserLogRepository) ApplyRetention(ctx context.Context, dir string, days, batchSize int) (*RetentionResult, error) {
+	if days < 1 {
+		return nil, ErrInvalidRetention
+	}
+	before := r.now().AddDate(0, 0, -days)
+	pending, err := r.Count(ctx, UserLogFilter{To: before})
+	if err != nil || pending == 0 {
+		return &RetentionResult{}, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "user_logs_"+before.UTC().Format("20060102T150405Z")+".jsonl.gz")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := r.Archive(ctx, file, before, batchSize)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	result := &RetentionResult{Archived: archived, File: path}
+	if err != nil {
+		// file tetap disimpan: isinya log yang sudah terhapus dari database
+		return result, fmt.Errorf("archive user logs to %s: %w", path, err)
+	}
+	return result, nil
+}
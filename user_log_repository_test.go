@@ -0,0 +1,189 @@
+package learn_golang_gorm
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// loadUserLogs membuat 10 log, satu per hari mulai 10 hari yang lalu, bergantian milik user 1 dan 2
+func loadUserLogs(t *testing.T, db *gorm.DB, now time.Time) {
+	t.Helper()
+	for i := 0; i < 10; i++ {
+		log := UserLog{UserId: "1", Action: AuditUpdate, Table: "wallets", RecordId: "1",
+			CreatedAt: now.AddDate(0, 0, i-10).UnixMilli()}
+		if i%2 == 1 {
+			log.UserId, log.Action = "2", AuditDelete
+		}
+		assert.Nil(t, db.Create(&log).Error)
+	}
+}
+
+func readArchive(t *testing.T, data []byte) []UserLog {
+	t.Helper()
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	assert.Nil(t, err)
+	var logs []UserLog
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var log UserLog
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &log))
+		logs = append(logs, log)
+	}
+	assert.Nil(t, scanner.Err())
+	return logs
+}
+
+func TestUserLogRepositoryFind(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	now := time.Now()
+	loadUserLogs(t, db, now)
+	repository := NewUserLogRepository(db)
+
+	page, err := repository.Find(ctx, UserLogFilter{UserID: "1"}, 2, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(page.Logs))
+	assert.True(t, page.Logs[0].ID > page.Logs[1].ID) // paling baru lebih dulu
+	assert.NotZero(t, page.Next)
+
+	// halaman berikutnya sampai habis
+	seen := len(page.Logs)
+	for page.Next != 0 {
+		page, err = repository.Find(ctx, UserLogFilter{UserID: "1"}, 2, page.Next)
+		assert.Nil(t, err)
+		seen += len(page.Logs)
+	}
+	assert.Equal(t, 5, seen)
+
+	page, err = repository.Find(ctx, UserLogFilter{Actions: []string{AuditDelete}, From: now.AddDate(0, 0, -5)}, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(page.Logs)) // hari -5, -3, -1
+	assert.Zero(t, page.Next)
+	for _, log := range page.Logs {
+		assert.Equal(t, "2", log.UserId)
+	}
+
+	count, err := repository.Count(ctx, UserLogFilter{Table: "wallets", RecordID: "1", To: now.AddDate(0, 0, -7)})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count) // hari -10, -9, -8
+	count, err = repository.Count(ctx, UserLogFilter{Table: "users"})
+	assert.Nil(t, err)
+	assert.Zero(t, count)
+}
+
+func TestUserLogRetention(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	now := time.Now()
+	loadUserLogs(t, db, now)
+	repository := NewUserLogRepository(db)
+	repository.now = func() time.Time { return now }
+	dir := t.TempDir()
+
+	_, err := repository.ApplyRetention(ctx, dir, 0, 0)
+	assert.ErrorIs(t, err, ErrInvalidRetention)
+
+	// log lebih lama dari 3 hari diarsipkan per 2 baris, lalu dihapus
+	result, err := repository.ApplyRetention(ctx, dir, 3, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), result.Archived)
+	data, err := os.ReadFile(result.File)
+	assert.Nil(t, err)
+	archived := readArchive(t, data)
+	assert.Equal(t, 7, len(archived))
+	assert.Equal(t, "wallets", archived[0].Table)
+	assert.Equal(t, now.AddDate(0, 0, -10).UnixMilli(), archived[0].CreatedAt)
+
+	remaining, err := repository.Count(ctx, UserLogFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), remaining)
+
+	// tidak ada lagi yang perlu diarsipkan, tidak ada file baru
+	result, err = repository.ApplyRetention(ctx, dir, 3, 2)
+	assert.Nil(t, err)
+	assert.Zero(t, result.Archived)
+	assert.Empty(t, result.File)
+	files, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+}
+
+func TestUserLogArchiveToWriter(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	now := time.Now()
+	loadUserLogs(t, db, now)
+
+	var out bytes.Buffer
+	archived, err := NewUserLogRepository(db).Archive(ctx, &out, now, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), archived)
+	assert.Equal(t, 10, len(readArchive(t, out.Bytes())))
+}
+
+// syncBuffer mencatat berapa kali Sync dipanggil, seperti *os.File
+type syncBuffer struct {
+	bytes.Buffer
+	syncs int
+}
+
+func (b *syncBuffer) Sync() error {
+	b.syncs++
+	return nil
+}
+
+func TestUserLogArchiveSyncsBeforeDelete(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	now := time.Now()
+	loadUserLogs(t, db, now)
+
+	var out syncBuffer
+	var syncedAtDelete []int
+	assert.Nil(t, db.Callback().Delete().Before("gorm:delete").Register("test:synced", func(tx *gorm.DB) {
+		syncedAtDelete = append(syncedAtDelete, out.syncs)
+	}))
+	archived, err := NewUserLogRepository(db).Archive(ctx, &out, now, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), archived)
+	assert.Equal(t, []int{1, 2, 3}, syncedAtDelete)
+}
+
+func TestUserLogRetentionKeepsPartialArchive(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	now := time.Now()
+	loadUserLogs(t, db, now)
+	repository := NewUserLogRepository(db)
+	repository.now = func() time.Time { return now }
+
+	// batch kedua gagal dihapus
+	var deletes int
+	assert.Nil(t, db.Callback().Delete().Before("gorm:delete").Register("test:fail_second", func(tx *gorm.DB) {
+		if deletes++; deletes == 2 {
+			tx.AddError(errors.New("disk full"))
+		}
+	}))
+	result, err := repository.ApplyRetention(ctx, t.TempDir(), 3, 2)
+	assert.NotNil(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, int64(2), result.Archived)
+
+	// gzip ditutup dengan benar: batch yang terhapus dan batch yang gagal tetap terbaca
+	data, err := os.ReadFile(result.File)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(readArchive(t, data)))
+	remaining, err := repository.Count(ctx, UserLogFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8), remaining)
+}
@@ -0,0 +1,144 @@
+package learn_golang_gorm
+
+import (
+	"errors"
+	"fmt"
+
+	"learn-golang-gorm/password"
+
+	"gorm.io/gorm"
+)
+
+// PasswordHasher dipakai untuk meng-hash User.Password sebelum disimpan.
+// Bisa diganti, misalnya password.Bcrypt{Cost: 12}, atau cost minimal saat test.
+// Hash lama tetap bisa diverifikasi dan otomatis diperbarui lewat VerifyPasswordAndRehash.
+var PasswordHasher password.Hasher = password.DefaultArgon2id
+
+// ErrPasswordNotString dikembalikan jika kolom password di-update dengan nilai selain string
+var ErrPasswordNotString = errors.New("password must be a string")
+
+// BeforeSave meng-hash password baru yang dikirim lewat Update("password", ...) atau Updates(map / struct).
+// Password dianggap berubah menurut Statement.Changed, bukan dari bentuk nilainya: teks yang kebetulan
+// mirip hash tetap di-hash. Create dan Save(&user) ditangani BeforeCreate (user.go) dan BeforeUpdate.
+// UpdateColumn(s) melewati hook, jadi jangan dipakai untuk kolom password.
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	switch dest := tx.Statement.Dest.(type) {
+	case map[string]interface{}: // Update("password", ...) atau Updates(map)
+		for key, value := range dest {
+			field := tx.Statement.Schema.LookUpField(key)
+			if field == nil || field.Name != "Password" {
+				continue
+			}
+			plain, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("%w, got %T", ErrPasswordNotString, value)
+			}
+			if plain == "" || !tx.Statement.Changed("Password") {
+				continue
+			}
+			hash, err := PasswordHasher.Hash(plain)
+			if err != nil {
+				return err
+			}
+			dest[key] = hash
+		}
+	case User: // Model(&user).Updates(User{...})
+		return setPasswordColumn(tx, dest.Password)
+	case *User:
+		if dest != u {
+			return setPasswordColumn(tx, dest.Password)
+		}
+	}
+	return nil
+}
+
+func setPasswordColumn(tx *gorm.DB, plain string) error {
+	if plain == "" || !tx.Statement.Changed("Password") {
+		return nil
+	}
+	hash, err := PasswordHasher.Hash(plain)
+	if err != nil {
+		return err
+	}
+	tx.Statement.SetColumn("Password", hash)
+	return nil
+}
+
+// BeforeUpdate meng-hash password pada Save(&user) dan Updates(&user) jika berbeda dari hash lama
+func (u *User) BeforeUpdate(tx *gorm.DB) error {
+	switch dest := tx.Statement.Dest.(type) {
+	case map[string]interface{}, User:
+		return nil // sudah ditangani BeforeSave
+	case *User:
+		if dest != u {
+			return nil
+		}
+	}
+	if u.Password == "" || u.Password == u.hashed {
+		return nil
+	}
+	if u.hashed == "" && u.ID != "" {
+		// user tidak dibaca lewat Find (misalnya hasil Joins), jadi hash lamanya dibaca dari database
+		var stored string
+		err := tx.Session(&gorm.Session{NewDB: true}).Model(&User{}).Select("password").Where("id = ?", u.ID).Scan(&stored).Error
+		if err != nil {
+			return err
+		}
+		if stored == u.Password {
+			u.hashed = stored
+			return nil
+		}
+	}
+	return u.hashPassword()
+}
+
+// hashPassword meng-hash u.Password jika berbeda dari hash yang terakhir diketahui
+func (u *User) hashPassword() error {
+	if u.Password == "" || u.Password == u.hashed {
+		return nil
+	}
+	hash, err := PasswordHasher.Hash(u.Password)
+	if err != nil {
+		return err
+	}
+	u.Password, u.hashed = hash, hash
+	return nil
+}
+
+// AfterFind dan AfterSave mencatat hash yang tersimpan, sehingga Save berikutnya tidak meng-hash ulang
+func (u *User) AfterFind(tx *gorm.DB) error {
+	u.hashed = u.Password
+	return nil
+}
+
+func (u *User) AfterSave(tx *gorm.DB) error {
+	u.hashed = u.Password
+	return nil
+}
+
+// VerifyPassword memeriksa apakah plain sama dengan password user
+func (u *User) VerifyPassword(plain string) bool {
+	ok, err := password.Verify(u.Password, plain)
+	return err == nil && ok
+}
+
+// VerifyPasswordAndRehash sama seperti VerifyPassword, tetapi jika password benar dan hash-nya dibuat
+// dengan algoritma / parameter lama, hash baru langsung disimpan ke database (cocok dipanggil saat login)
+func (u *User) VerifyPasswordAndRehash(db *gorm.DB, plain string) (bool, error) {
+	if !u.VerifyPassword(plain) {
+		return false, nil
+	}
+	if !PasswordHasher.NeedsRehash(u.Password) {
+		return true, nil
+	}
+	hash, err := PasswordHasher.Hash(plain)
+	if err != nil {
+		return true, err
+	}
+	// nilainya sama dengan u.Password, jadi BeforeSave tidak menganggapnya sebagai password baru
+	u.Password, u.hashed = hash, hash
+	if err := db.Model(u).Update("password", hash).Error; err != nil {
+		return true, err
+	}
+	return true, nil
+}
@@ -0,0 +1,154 @@
+package learn_golang_gorm
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"testing"
+
+	"learn-golang-gorm/password"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// storedPassword membaca kolom password langsung dari database
+func storedPassword(t *testing.T, db *gorm.DB, id string) string {
+	t.Helper()
+	var stored string
+	assert.Nil(t, db.Table("users").Select("password").Where("id = ?", id).Scan(&stored).Error)
+	return stored
+}
+
+func TestPasswordIsHashedOnSave(t *testing.T) {
+	db := newTestDB(t)
+
+	user := User{ID: "1", Name: Name{FirstName: "Lev"}, Password: "secret"}
+	assert.Nil(t, db.Create(&user).Error)
+	hash := storedPassword(t, db, "1")
+	assert.True(t, password.IsHash(hash))
+	assert.Equal(t, hash, user.Password)
+	assert.True(t, user.VerifyPassword("secret"))
+	assert.False(t, user.VerifyPassword("wrong"))
+
+	// password tidak berubah: hash tidak dibuat ulang
+	user.Name.LastName = "Vex"
+	assert.Nil(t, db.Save(&user).Error)
+	assert.Equal(t, hash, storedPassword(t, db, "1"))
+
+	// semua cara update meng-hash password baru
+	updates := []struct {
+		name   string
+		plain  string
+		update func(plain string) error
+	}{
+		{"update", "secret1", func(plain string) error {
+			return db.Model(&User{}).Where("id = ?", "1").Update("password", plain).Error
+		}},
+		{"updates map", "secret2", func(plain string) error {
+			return db.Model(&user).Updates(map[string]interface{}{"password": plain}).Error
+		}},
+		{"updates struct", "secret3", func(plain string) error {
+			return db.Model(&user).Updates(User{Password: plain}).Error
+		}},
+		{"updates pointer", "secret4", func(plain string) error {
+			return db.Where("id = ?", "1").Updates(&User{Password: plain}).Error
+		}},
+	}
+	for _, tt := range updates {
+		assert.Nil(t, tt.update(tt.plain), tt.name)
+		var found User
+		assert.Nil(t, db.Take(&found, "id = ?", "1").Error, tt.name)
+		assert.True(t, password.IsHash(found.Password), tt.name)
+		assert.True(t, found.VerifyPassword(tt.plain), tt.name)
+	}
+}
+
+func TestPasswordChangeIsTrackedNotGuessed(t *testing.T) {
+	db := newTestDB(t)
+
+	// teks yang mirip hash bcrypt tetap di-hash: jika tidak, siapa pun yang memilih "password" ini
+	// bisa login dengan teks asli dari hash tersebut
+	lookalike, err := password.Bcrypt{Cost: bcrypt.MinCost}.Hash("other")
+	assert.Nil(t, err)
+	user := User{ID: "1", Name: Name{FirstName: "Lev"}, Password: lookalike}
+	assert.Nil(t, db.Create(&user).Error)
+	assert.NotEqual(t, lookalike, storedPassword(t, db, "1"))
+	assert.True(t, user.VerifyPassword(lookalike))
+	assert.False(t, user.VerifyPassword("other"))
+
+	assert.Nil(t, db.Model(&User{}).Where("id = ?", "1").Update("password", lookalike).Error)
+	assert.NotEqual(t, lookalike, storedPassword(t, db, "1"))
+
+	// nilai selain string ditolak, bukan disimpan sebagai password kosong
+	err = db.Model(&User{}).Where("id = ?", "1").Update("password", 123).Error
+	assert.ErrorIs(t, err, ErrPasswordNotString)
+
+	// user yang dibaca lewat Joins tidak melewati AfterFind: hash lamanya dibaca dari database
+	assert.Nil(t, db.Create(&Wallet{ID: "w1", UserId: "1", Balance: idr(0)}).Error)
+	var wallet Wallet
+	assert.Nil(t, db.Joins("User").Take(&wallet, "wallets.id = ?", "w1").Error)
+	hash := storedPassword(t, db, "1")
+	assert.Equal(t, hash, wallet.User.Password)
+	wallet.User.Name.LastName = "Vex"
+	assert.Nil(t, db.Save(&wallet.User).Error)
+	assert.Equal(t, hash, storedPassword(t, db, "1"))
+
+	// Save setelah Find juga tidak meng-hash ulang
+	var found User
+	assert.Nil(t, db.Take(&found, "id = ?", "1").Error)
+	found.Name.MiddleName = "X"
+	assert.Nil(t, db.Save(&found).Error)
+	assert.Equal(t, hash, storedPassword(t, db, "1"))
+}
+
+func TestPasswordIsHiddenFromJSONAndLogs(t *testing.T) {
+	db := newTestDB(t)
+
+	var buffer bytes.Buffer
+	verbose := db.Session(&gorm.Session{Logger: password.RedactLogger(
+		logger.New(log.New(&buffer, "", 0), logger.Config{LogLevel: logger.Info}),
+	)})
+
+	user := User{ID: "1", Name: Name{FirstName: "Lev"}, Password: "secret"}
+	assert.Nil(t, verbose.Create(&user).Error)
+	assert.Nil(t, verbose.Take(&User{}, "id = ?", "1").Error)
+
+	assert.Contains(t, buffer.String(), "INSERT INTO")
+	assert.Contains(t, buffer.String(), "[REDACTED]")
+	assert.NotContains(t, buffer.String(), user.Password)
+	assert.NotContains(t, buffer.String(), "secret")
+
+	body, err := json.Marshal(user)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(body), "assword")
+	assert.NotContains(t, string(body), user.Password)
+}
+
+func TestVerifyPasswordAndRehash(t *testing.T) {
+	db := newTestDB(t)
+
+	user := User{ID: "1", Name: Name{FirstName: "Lev"}, Password: "secret"}
+	assert.Nil(t, db.Create(&user).Error)
+	oldHash := user.Password
+
+	// cost dinaikkan: hash lama tetap valid, lalu diganti saat login berikutnya
+	previous := PasswordHasher
+	PasswordHasher = password.Bcrypt{Cost: bcrypt.MinCost + 1}
+	t.Cleanup(func() { PasswordHasher = previous })
+
+	ok, err := user.VerifyPasswordAndRehash(db, "wrong")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, oldHash, storedPassword(t, db, "1"))
+
+	ok, err = user.VerifyPasswordAndRehash(db, "secret")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	newHash := storedPassword(t, db, "1")
+	assert.NotEqual(t, oldHash, newHash)
+	assert.False(t, PasswordHasher.NeedsRehash(newHash))
+	assert.True(t, user.VerifyPassword("secret"))
+}
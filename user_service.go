@@ -0,0 +1,83 @@
+package learn_golang_gorm
+
+import (
+	"context"
+
+	"learn-golang-gorm/pagination"
+	"learn-golang-gorm/uow"
+
+	"gorm.io/gorm"
+)
+
+// UserService berisi operasi user yang ditulis ke lebih dari satu tabel, masing-masing dalam satu
+// transaction (uow). Jika ctx sudah membawa transaction, operasinya ikut transaction tersebut.
+type UserService struct {
+	work  *uow.UnitOfWork
+	users *UserRepository
+}
+
+func NewUserService(db *gorm.DB) *UserService {
+	return &UserService{work: uow.New(db), users: NewUserRepository(db)}
+}
+
+// Create membuat user tanpa wallet. Saldo awal sengaja tidak bisa diisi di sini, wallet baru selalu
+// mulai dari 0 dan saldonya hanya berubah lewat transaksi.
+func (s *UserService) Create(ctx context.Context, user *User) error {
+	return s.users.Create(ctx, user)
+}
+
+// Get mengembalikan user beserta wallet-nya
+func (s *UserService) Get(ctx context.Context, id string) (*User, error) {
+	return s.users.FindWithWallet(ctx, id)
+}
+
+// UserUpdate berisi kolom yang diubah oleh UserService.Update, nil berarti tidak diubah
+type UserUpdate struct {
+	FirstName  *string
+	MiddleName *string
+	LastName   *string
+	Password   *string // di-hash oleh User.BeforeSave
+}
+
+func (u UserUpdate) columns() map[string]interface{} {
+	columns := map[string]interface{}{}
+	for column, value := range map[string]*string{
+		"first_name": u.FirstName, "middle_name": u.MiddleName, "last_name": u.LastName, "password": u.Password,
+	} {
+		if value != nil {
+			columns[column] = *value
+		}
+	}
+	return columns
+}
+
+// Update mengubah kolom yang diisi di update, lalu membaca ulang user beserta wallet-nya di transaction yang sama
+func (s *UserService) Update(ctx context.Context, id string, update UserUpdate) (*User, error) {
+	var user *User
+	err := s.work.Do(ctx, func(ctx context.Context) error {
+		if columns := update.columns(); len(columns) > 0 {
+			if err := s.users.Update(ctx, id, columns); err != nil {
+				return notFound(err, ErrUserNotFound, id)
+			}
+		}
+		var err error
+		user, err = s.users.FindWithWallet(ctx, id)
+		return err
+	})
+	return user, err
+}
+
+// List mengembalikan satu halaman user beserta wallet-nya. query memakai format UserFilter, misalnya
+// "name.first=like:A%25&sort=-created_at". Halaman dibaca dengan cursor (after kosong untuk halaman
+// pertama), jadi sort hanya boleh memakai kolom tabel users.
+func (s *UserService) List(ctx context.Context, query string, size int, after string) (*pagination.Page[User], error) {
+	f, err := UserFilter.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	req, err := f.CursorRequest(size, after, "")
+	if err != nil {
+		return nil, err
+	}
+	return pagination.Cursor[User](ctx, s.users.DB(ctx).Joins("Wallet").Scopes(f.Scope), req)
+}
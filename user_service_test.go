@@ -0,0 +1,80 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"testing"
+
+	"learn-golang-gorm/dberrors"
+	"learn-golang-gorm/filter"
+	"learn-golang-gorm/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserServiceCreate(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewUserService(db)
+
+	// user baru tidak punya wallet, wallet dibuat terpisah dengan saldo 0
+	user := User{ID: "50", Name: Name{FirstName: "Budi"}, Password: "rahasia"}
+	assert.Nil(t, service.Create(ctx, &user))
+	found, err := service.Get(ctx, "50")
+	assert.Nil(t, err)
+	assert.Empty(t, found.Wallet.ID)
+	assert.True(t, found.VerifyPassword("rahasia"))
+
+	assert.ErrorIs(t, service.Create(ctx, &User{ID: "50", Name: Name{FirstName: "Budi"}}), dberrors.ErrDuplicateKey)
+}
+
+func TestUserServiceUpdate(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewUserService(db)
+
+	last, password := "Santoso", "baru"
+	user, err := service.Update(ctx, "1", UserUpdate{LastName: &last, Password: &password})
+	assert.Nil(t, err)
+	assert.Equal(t, "Lev Tempest Santoso", user.Name.Full())
+	assert.Equal(t, "1", user.Wallet.ID)
+	assert.True(t, user.VerifyPassword("baru"))
+
+	// tanpa kolom yang diubah hanya membaca ulang
+	user, err = service.Update(ctx, "1", UserUpdate{})
+	assert.Nil(t, err)
+	assert.Equal(t, "Santoso", user.Name.LastName)
+
+	_, err = service.Update(ctx, "404", UserUpdate{LastName: &last})
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestUserServiceList(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewUserService(db)
+
+	ids := func(page *pagination.Page[User]) []string {
+		var ids []string
+		for _, user := range page.Items {
+			ids = append(ids, user.ID)
+		}
+		return ids
+	}
+
+	page, err := service.List(ctx, "wallet.balance=gte:1000000&sort=-id", 3, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"21", "20", "2"}, ids(page))
+	assert.Equal(t, "1000000", page.Items[0].Wallet.Balance.Decimal())
+	page, err = service.List(ctx, "wallet.balance=gte:1000000&sort=-id", 3, page.Next)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1"}, ids(page))
+	assert.False(t, page.HasMore)
+
+	_, err = service.List(ctx, "sort=-wallet.balance", 3, "")
+	assert.ErrorIs(t, err, filter.ErrInvalidFilter)
+	_, err = service.List(ctx, "", 3, "rusak")
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+}
@@ -1,12 +1,16 @@
 package learn_golang_gorm
 
-import "time"
+import (
+	"time"
+
+	"learn-golang-gorm/money"
+)
 
 type Wallet struct {
-	ID        string    `gorm:"primary_key;column:id"`
-	UserId    string    `gorm:"column:user_id"`
-	Balance   int       `gorm:"column:balance"`
-	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	ID        string      `gorm:"primary_key;column:id;size:100"`
+	UserId    string      `gorm:"column:user_id;size:100;uniqueIndex:idx_wallets_user_id"` // satu user satu wallet (migration 0018)
+	Balance   money.Money `gorm:"embedded;embeddedPrefix:balance_"` // kolom balance_amount dan balance_currency
+	CreatedAt time.Time   `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time   `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
 	User 	*User		`gorm:"foreignKey:user_id;references:id"` // gunakan pointer (*) untuk menghindari cyclic dependency 
 }
\ No newline at end of file
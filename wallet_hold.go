@@ -0,0 +1,304 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"learn-golang-gorm/money"
+	"learn-golang-gorm/txretry"
+	"learn-golang-gorm/uow"
+
+	"gorm.io/gorm"
+)
+
+// Status hold
+const (
+	WalletHoldActive   = "active"
+	WalletHoldCaptured = "captured"
+	WalletHoldReleased = "released"
+	WalletHoldExpired  = "expired"
+)
+
+var (
+	ErrHoldNotFound   = errors.New("wallet hold not found")
+	ErrHoldNotActive  = errors.New("wallet hold is no longer active")
+	ErrHoldExpired    = errors.New("wallet hold has expired")
+	ErrInvalidHoldTTL = errors.New("wallet hold ttl must be greater than zero")
+)
+
+// WalletHold mencadangkan sebagian saldo wallet (tabel wallet_holds).
+// Saldo wallet belum berkurang sampai hold di-capture, tetapi dana yang di-hold tidak bisa dipakai
+// untuk hold lain maupun transfer. Hold yang melewati ExpiresAt tidak lagi mengurangi saldo tersedia.
+type WalletHold struct {
+	ID             string      `gorm:"primary_key;column:id;size:100"`
+	WalletID       string      `gorm:"column:wallet_id;size:100"`
+	Amount         money.Money `gorm:"embedded"` // kolom amount dan currency
+	CapturedAmount int64       `gorm:"column:captured_amount"`
+	Status         string      `gorm:"column:status;size:20"`
+	Reference      string      `gorm:"column:reference;size:100"` // misalnya nomor order
+	ExpiresAt      time.Time   `gorm:"column:expires_at"`
+	ResolvedAt     *time.Time  `gorm:"column:resolved_at"` // waktu capture / release / expire
+	CreatedAt      time.Time   `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time   `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (h *WalletHold) BeforeCreate(db *gorm.DB) error {
+	return assignID(h, &h.ID)
+}
+
+// HoldService membuat dan menyelesaikan hold. Setiap operasi mengunci wallet-nya lebih dulu
+// (SELECT ... FOR UPDATE, sama seperti TestLock dan Transfer), jadi hold, capture dan transfer
+// pada wallet yang sama tidak bisa memakai dana yang sama dua kali.
+// Seperti Transfer, transaction yang bentrok (deadlock, lock wait timeout) diulang otomatis.
+type HoldService struct {
+	db    *gorm.DB
+	now   func() time.Time
+	retry *txretry.Runner
+}
+
+func NewHoldService(db *gorm.DB) *HoldService {
+	return &HoldService{db: db, now: time.Now, retry: txretry.New(db, txretry.DefaultPolicy)}
+}
+
+// RetryStats mengembalikan jumlah operasi hold yang diulang karena bentrok
+func (s *HoldService) RetryStats() txretry.Stats {
+	return s.retry.Stats()
+}
+
+// heldAmount menjumlahkan hold aktif yang belum kedaluwarsa
+func heldAmount(tx *gorm.DB, walletID string, now time.Time) (int64, error) {
+	var held int64
+	err := tx.Model(&WalletHold{}).Select("COALESCE(SUM(amount), 0)").
+		Where("wallet_id = ? AND status = ? AND expires_at > ?", walletID, WalletHoldActive, now).
+		Scan(&held).Error
+	return held, err
+}
+
+// availableBalance adalah saldo wallet dikurangi hold yang masih aktif
+func availableBalance(tx *gorm.DB, wallet *Wallet, now time.Time) (money.Money, error) {
+	held, err := heldAmount(tx, wallet.ID, now)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return wallet.Balance.Sub(money.New(held, wallet.Balance.Currency))
+}
+
+// AvailableBalance mengembalikan saldo wallet yang masih bisa dipakai (Balance dikurangi hold aktif)
+func (s *HoldService) AvailableBalance(ctx context.Context, walletID string) (money.Money, error) {
+	db := uow.DB(ctx, s.db)
+	var wallet Wallet
+	if err := db.Take(&wallet, "id = ?", walletID).Error; err != nil {
+		return money.Money{}, notFound(err, ErrWalletNotFound, walletID)
+	}
+	return availableBalance(db, &wallet, s.now())
+}
+
+// Hold mencadangkan amount dari wallet selama ttl. Gagal dengan ErrInsufficientFunds
+// jika saldo tersedia kurang dari amount.
+func (s *HoldService) Hold(ctx context.Context, walletID string, amount money.Money, reference string, ttl time.Duration) (*WalletHold, error) {
+	switch {
+	case !amount.IsPositive():
+		return nil, ErrInvalidAmount
+	case ttl <= 0:
+		return nil, ErrInvalidHoldTTL
+	}
+
+	var hold *WalletHold
+	err := s.retry.Transaction(ctx, func(tx *gorm.DB) error {
+		wallets, err := lockWallets(tx, walletID)
+		if err != nil {
+			return err
+		}
+		wallet := wallets[walletID]
+		if err := wallet.Balance.SameCurrency(amount); err != nil {
+			return fmt.Errorf("wallet %s: %w", walletID, err)
+		}
+
+		now := s.now()
+		available, err := availableBalance(tx, wallet, now)
+		if err != nil {
+			return err
+		}
+		if cmp, _ := available.Cmp(amount); cmp < 0 {
+			return fmt.Errorf("%w: wallet %s has %s available, need %s", ErrInsufficientFunds, walletID, available, amount)
+		}
+
+		hold = &WalletHold{
+			WalletID:  walletID,
+			Amount:    amount,
+			Status:    WalletHoldActive,
+			Reference: reference,
+			ExpiresAt: now.Add(ttl),
+		}
+		return tx.Create(hold).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// Capture mengambil seluruh dana hold: saldo wallet berkurang dan dicatat di ledger
+func (s *HoldService) Capture(ctx context.Context, holdID string) (*WalletHold, error) {
+	return s.capture(ctx, holdID, nil)
+}
+
+// CapturePartial mengambil sebagian dana hold (tidak boleh lebih dari jumlah hold),
+// sisanya otomatis dilepas
+func (s *HoldService) CapturePartial(ctx context.Context, holdID string, amount money.Money) (*WalletHold, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	return s.capture(ctx, holdID, &amount)
+}
+
+func (s *HoldService) capture(ctx context.Context, holdID string, amount *money.Money) (*WalletHold, error) {
+	var hold *WalletHold
+	err := s.retry.Transaction(ctx, func(tx *gorm.DB) error {
+		var wallet *Wallet
+		var err error
+		if hold, wallet, err = s.lockActiveHold(tx, holdID); err != nil {
+			return err
+		}
+
+		captured := hold.Amount
+		if amount != nil {
+			if cmp, err := amount.Cmp(hold.Amount); err != nil || cmp > 0 {
+				return fmt.Errorf("%w: capture %s from hold of %s", ErrInvalidAmount, amount, hold.Amount)
+			}
+			captured = *amount
+		}
+
+		balance, err := wallet.Balance.Sub(captured)
+		if err != nil {
+			return err
+		}
+		if balance.IsNegative() {
+			return fmt.Errorf("%w: wallet %s has %s, need %s", ErrInsufficientFunds, wallet.ID, wallet.Balance, captured)
+		}
+		wallet.Balance = balance
+		if err := tx.Model(wallet).Update("balance_amount", wallet.Balance.Amount).Error; err != nil {
+			return err
+		}
+		_, err = PostJournal(tx, LedgerJournalCapture, hold.ID,
+			WalletPosting(wallet.ID, -captured.Amount),
+			AccountPosting(LedgerAccountCapture, captured.Amount),
+		)
+		if err != nil {
+			return err
+		}
+
+		hold.CapturedAmount = captured.Amount
+		return s.resolve(tx, hold, WalletHoldCaptured)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// Release melepas hold tanpa mengubah saldo, dananya kembali tersedia
+func (s *HoldService) Release(ctx context.Context, holdID string) (*WalletHold, error) {
+	var hold *WalletHold
+	err := s.retry.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		if hold, _, err = s.lockActiveHold(tx, holdID); err != nil {
+			return err
+		}
+		return s.resolve(tx, hold, WalletHoldReleased)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// lockActiveHold mengunci wallet pemilik hold, lalu membaca ulang hold-nya.
+// Perubahan hold selalu dilakukan sambil memegang lock wallet ini.
+func (s *HoldService) lockActiveHold(tx *gorm.DB, holdID string) (*WalletHold, *Wallet, error) {
+	var owner WalletHold
+	if err := tx.Select("wallet_id").Take(&owner, "id = ?", holdID).Error; err != nil {
+		return nil, nil, notFound(err, ErrHoldNotFound, holdID)
+	}
+	wallets, err := lockWallets(tx, owner.WalletID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var hold WalletHold
+	if err := tx.Take(&hold, "id = ?", holdID).Error; err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case hold.Status != WalletHoldActive:
+		return nil, nil, fmt.Errorf("%w: hold %s is %s", ErrHoldNotActive, holdID, hold.Status)
+	case !s.now().Before(hold.ExpiresAt):
+		// statusnya diubah oleh ExpireStale
+		return nil, nil, fmt.Errorf("%w: hold %s expired at %s", ErrHoldExpired, holdID, hold.ExpiresAt)
+	}
+	return &hold, wallets[hold.WalletID], nil
+}
+
+func (s *HoldService) resolve(tx *gorm.DB, hold *WalletHold, status string) error {
+	now := s.now()
+	hold.Status, hold.ResolvedAt = status, &now
+	return tx.Model(hold).Select("status", "captured_amount", "resolved_at").Updates(hold).Error
+}
+
+// ExpireStale mengubah status hold aktif yang sudah melewati ExpiresAt menjadi expired.
+// Wallet dikunci satu per satu, sehingga tidak bentrok dengan capture yang sedang berjalan.
+func (s *HoldService) ExpireStale(ctx context.Context) (int64, error) {
+	db := s.db.WithContext(ctx)
+	now := s.now()
+
+	var walletIDs []string
+	err := db.Model(&WalletHold{}).Distinct("wallet_id").
+		Where("status = ? AND expires_at <= ?", WalletHoldActive, now).
+		Order("wallet_id").Pluck("wallet_id", &walletIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var expired int64
+	for _, walletID := range walletIDs {
+		var affected int64
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if _, err := lockWallets(tx, walletID); err != nil {
+				return err
+			}
+			result := tx.Model(&WalletHold{}).
+				Where("wallet_id = ? AND status = ? AND expires_at <= ?", walletID, WalletHoldActive, now).
+				Updates(map[string]interface{}{"status": WalletHoldExpired, "resolved_at": now})
+			affected = result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			return expired, fmt.Errorf("expire holds of wallet %s: %w", walletID, err)
+		}
+		// baru dihitung setelah commit, transaction yang gagal tidak ikut dihitung
+		expired += affected
+	}
+	return expired, nil
+}
+
+// Sweep menjalankan ExpireStale setiap interval sampai ctx dibatalkan. Jalankan di goroutine sendiri:
+//
+//	go holds.Sweep(ctx, time.Minute)
+//
+// Error dicatat lewat logger GORM dan sweeper tetap berjalan.
+func (s *HoldService) Sweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ExpireStale(ctx); err != nil && ctx.Err() == nil {
+				s.db.Logger.Error(ctx, "wallet hold sweeper: %v", err)
+			}
+		}
+	}
+}
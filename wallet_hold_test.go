@@ -0,0 +1,214 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func availableAmount(t *testing.T, service *HoldService, walletID string) int64 {
+	t.Helper()
+	available, err := service.AvailableBalance(context.Background(), walletID)
+	assert.Nil(t, err)
+	return available.Amount
+}
+
+func TestWalletHold(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewHoldService(db)
+
+	hold, err := service.Hold(ctx, "1", idr(300000), "order-1", 15*time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, WalletHoldActive, hold.Status)
+	assert.Equal(t, int64(700000), availableAmount(t, service, "1"))
+
+	// dana yang di-hold tidak bisa dipakai hold lain maupun transfer
+	_, err = service.Hold(ctx, "1", idr(700001), "order-2", time.Minute)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	_, err = NewTransferService(db).Transfer(ctx, "1", "2", idr(700001), "payout-1")
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// capture sebagian, sisanya kembali tersedia
+	captured, err := service.CapturePartial(ctx, hold.ID, idr(200000))
+	assert.Nil(t, err)
+	assert.Equal(t, WalletHoldCaptured, captured.Status)
+	assert.Equal(t, int64(200000), captured.CapturedAmount)
+	assert.NotNil(t, captured.ResolvedAt)
+	assert.Equal(t, int64(800000), availableAmount(t, service, "1"))
+	var wallet Wallet
+	assert.Nil(t, db.Take(&wallet, "id = ?", "1").Error)
+	assert.Equal(t, idr(800000), wallet.Balance)
+
+	_, err = service.Release(ctx, hold.ID)
+	assert.ErrorIs(t, err, ErrHoldNotActive)
+	_, err = service.Capture(ctx, hold.ID)
+	assert.ErrorIs(t, err, ErrHoldNotActive)
+
+	// release tidak mengubah saldo
+	second, err := service.Hold(ctx, "1", idr(800000), "order-3", time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), availableAmount(t, service, "1"))
+	_, err = service.CapturePartial(ctx, second.ID, idr(800001))
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	released, err := service.Release(ctx, second.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, WalletHoldReleased, released.Status)
+	assert.Equal(t, int64(800000), availableAmount(t, service, "1"))
+
+	report, err := NewLedger(db).Reconcile(ctx, RepairNone)
+	assert.Nil(t, err)
+	assert.True(t, report.OK())
+}
+
+func TestWalletHoldRejected(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewHoldService(db)
+
+	_, err := service.Hold(ctx, "1", idr(0), "zero", time.Minute)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = service.Hold(ctx, "1", idr(10), "no-ttl", 0)
+	assert.ErrorIs(t, err, ErrInvalidHoldTTL)
+	_, err = service.Hold(ctx, "404", idr(10), "missing", time.Minute)
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+	_, err = service.Capture(ctx, "404")
+	assert.ErrorIs(t, err, ErrHoldNotFound)
+	_, err = service.AvailableBalance(ctx, "404")
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+}
+
+func TestWalletHoldExpiry(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewHoldService(db)
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	stale, err := service.Hold(ctx, "1", idr(300000), "order-1", time.Minute)
+	assert.Nil(t, err)
+	fresh, err := service.Hold(ctx, "2", idr(100000), "order-2", time.Hour)
+	assert.Nil(t, err)
+
+	// setelah kedaluwarsa hold tidak lagi mengurangi saldo tersedia, meskipun belum disapu
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, int64(1000000), availableAmount(t, service, "1"))
+	_, err = service.Capture(ctx, stale.ID)
+	assert.ErrorIs(t, err, ErrHoldExpired)
+
+	expired, err := service.ExpireStale(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), expired)
+
+	var holds []WalletHold
+	assert.Nil(t, db.Order("wallet_id").Find(&holds).Error)
+	assert.Equal(t, WalletHoldExpired, holds[0].Status)
+	assert.NotNil(t, holds[0].ResolvedAt)
+	assert.Equal(t, WalletHoldActive, holds[1].Status)
+	_, err = service.Release(ctx, stale.ID)
+	assert.ErrorIs(t, err, ErrHoldNotActive)
+
+	_, err = service.Capture(ctx, fresh.ID)
+	assert.Nil(t, err)
+	expired, err = service.ExpireStale(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), expired)
+}
+
+func TestWalletHoldExpiryCountsCommittedOnly(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewHoldService(db)
+
+	for _, walletID := range []string{"1", "2"} {
+		_, err := service.Hold(ctx, walletID, idr(100000), "order-"+walletID, time.Minute)
+		assert.Nil(t, err)
+	}
+	// update hold milik wallet kedua berhasil, tetapi transaction-nya gagal dan di-rollback
+	var updates int
+	assert.Nil(t, db.Callback().Update().After("gorm:update").Register("test:fail_second", func(tx *gorm.DB) {
+		if tx.Statement.Table == "wallet_holds" {
+			if updates++; updates == 2 {
+				tx.AddError(errors.New("commit refused"))
+			}
+		}
+	}))
+
+	future := time.Now().Add(time.Hour)
+	service.now = func() time.Time { return future }
+	expired, err := service.ExpireStale(ctx)
+	assert.NotNil(t, err)
+	assert.Equal(t, int64(1), expired)
+
+	var active int64
+	assert.Nil(t, db.Model(&WalletHold{}).Where("status = ?", WalletHoldActive).Count(&active).Error)
+	assert.Equal(t, int64(1), active)
+}
+
+func TestWalletHoldSweeper(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewHoldService(db)
+
+	hold, err := service.Hold(ctx, "1", idr(300000), "order-1", time.Minute)
+	assert.Nil(t, err)
+
+	future := time.Now().Add(time.Hour)
+	service.now = func() time.Time { return future }
+	done := make(chan struct{})
+	go func() {
+		service.Sweep(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		var status string
+		db.Model(&WalletHold{}).Select("status").Where("id = ?", hold.ID).Scan(&status)
+		return status == WalletHoldExpired
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestConcurrentWalletHolds(t *testing.T) {
+	ctx := context.Background()
+	db := newConcurrentTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewHoldService(db)
+
+	// saldo 1000000 hanya cukup untuk 3 hold 300000
+	var wg sync.WaitGroup
+	var succeeded, rejected atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.Hold(ctx, "1", idr(300000), "checkout", time.Minute)
+			switch {
+			case err == nil:
+				succeeded.Add(1)
+			case errors.Is(err, ErrInsufficientFunds):
+				rejected.Add(1)
+			default:
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(3), succeeded.Load())
+	assert.Equal(t, int32(7), rejected.Load())
+	assert.Equal(t, int64(100000), availableAmount(t, service, "1"))
+}
@@ -0,0 +1,122 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"learn-golang-gorm/idempotency"
+	"learn-golang-gorm/money"
+	"learn-golang-gorm/pagination"
+	"learn-golang-gorm/uow"
+
+	"gorm.io/gorm"
+)
+
+// WalletService berisi operasi saldo wallet yang berasal dari luar (selain transfer antar wallet)
+type WalletService struct {
+	db   *gorm.DB
+	keys *idempotency.Store
+}
+
+func NewWalletService(db *gorm.DB) *WalletService {
+	return &WalletService{db: db, keys: idempotency.New(db)}
+}
+
+// TopUpResult adalah hasil TopUp. Replayed bernilai true jika hasil diambil dari request sebelumnya.
+type TopUpResult struct {
+	WalletID  string
+	Amount    money.Money
+	Balance   money.Money // saldo setelah top up
+	JournalID string
+	Replayed  bool `json:"-"`
+}
+
+// TopUp menambah saldo wallet. Retry dengan idempotencyKey yang sama tidak menambah saldo lagi.
+// Mata uang amount harus sama dengan mata uang wallet.
+func (s *WalletService) TopUp(ctx context.Context, walletID string, amount money.Money, idempotencyKey string) (*TopUpResult, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	request := struct {
+		WalletID string
+		Amount   money.Money
+	}{walletID, amount}
+	result, replayed, err := idempotency.Do(ctx, s.keys, "wallet_top_up", idempotencyKey, request,
+		func(tx *gorm.DB) (TopUpResult, error) {
+			wallets, err := lockWallets(tx, walletID)
+			if err != nil {
+				return TopUpResult{}, err
+			}
+			wallet := wallets[walletID]
+			if wallet.Balance, err = wallet.Balance.Add(amount); err != nil {
+				return TopUpResult{}, fmt.Errorf("wallet %s: %w", walletID, err)
+			}
+			if err := tx.Model(wallet).Update("balance_amount", wallet.Balance.Amount).Error; err != nil {
+				return TopUpResult{}, err
+			}
+			journal, err := PostJournal(tx, LedgerJournalTopUp, idempotencyKey,
+				WalletPosting(walletID, amount.Amount),
+				AccountPosting(LedgerAccountTopUp, -amount.Amount),
+			)
+			if err != nil {
+				return TopUpResult{}, err
+			}
+			return TopUpResult{WalletID: walletID, Amount: amount, Balance: wallet.Balance, JournalID: journal.ID}, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	result.Replayed = replayed
+	return &result, nil
+}
+
+// WalletBalance adalah saldo wallet pada satu saat, Available = Balance - Held (lihat wallet_hold.go)
+type WalletBalance struct {
+	WalletID  string
+	UserID    string
+	Balance   money.Money
+	Held      money.Money
+	Available money.Money
+}
+
+// Balance membaca wallet dan hold aktifnya dalam satu transaction, jadi Balance, Held dan Available konsisten
+func (s *WalletService) Balance(ctx context.Context, walletID string) (*WalletBalance, error) {
+	var result *WalletBalance
+	err := uow.DB(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		var wallet Wallet
+		if err := tx.Take(&wallet, "id = ?", walletID).Error; err != nil {
+			return notFound(err, ErrWalletNotFound, walletID)
+		}
+		held, err := heldAmount(tx, walletID, time.Now())
+		if err != nil {
+			return err
+		}
+		result = &WalletBalance{
+			WalletID: wallet.ID, UserID: wallet.UserId, Balance: wallet.Balance,
+			Held: money.New(held, wallet.Balance.Currency),
+		}
+		result.Available, err = wallet.Balance.Sub(result.Held)
+		return err
+	})
+	return result, err
+}
+
+// Transactions mengembalikan mutasi wallet (wallet_transactions), terbaru lebih dulu, per halaman dengan
+// cursor: after kosong untuk halaman pertama, lalu Page.Next dari halaman sebelumnya
+func (s *WalletService) Transactions(ctx context.Context, walletID string, size int, after string) (*pagination.Page[WalletTransaction], error) {
+	exists, err := NewWalletRepository(s.db).Exists(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, notFound(gorm.ErrRecordNotFound, ErrWalletNotFound, walletID)
+	}
+	query := uow.DB(ctx, s.db).Where("wallet_id = ?", walletID)
+	return pagination.Cursor[WalletTransaction](ctx, query, pagination.CursorRequest{
+		Sort:  []pagination.Order{{Column: "created_at", Desc: true}},
+		Size:  size,
+		After: after,
+	})
+}
@@ -0,0 +1,115 @@
+package learn_golang_gorm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"learn-golang-gorm/idempotency"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopUp(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewWalletService(db)
+
+	result, err := service.TopUp(ctx, "1", idr(50000), "topup-1")
+	assert.Nil(t, err)
+	assert.False(t, result.Replayed)
+	assert.Equal(t, idr(1050000), result.Balance)
+
+	// retry dari client tidak menambah saldo lagi
+	replay, err := service.TopUp(ctx, "1", idr(50000), "topup-1")
+	assert.Nil(t, err)
+	assert.True(t, replay.Replayed)
+	assert.Equal(t, result.JournalID, replay.JournalID)
+	assert.Equal(t, idr(1050000), replay.Balance)
+
+	_, err = service.TopUp(ctx, "1", idr(70000), "topup-1")
+	assert.ErrorIs(t, err, idempotency.ErrConflict)
+	_, err = service.TopUp(ctx, "1", idr(0), "topup-2")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = service.TopUp(ctx, "404", idr(10), "topup-3")
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+
+	// top up yang gagal tidak menghabiskan key-nya
+	assert.Nil(t, db.Create(&Wallet{ID: "404", UserId: "3"}).Error)
+	_, err = service.TopUp(ctx, "404", idr(10), "topup-3")
+	assert.Nil(t, err)
+
+	var wallet Wallet
+	assert.Nil(t, db.Take(&wallet, "id = ?", "1").Error)
+	assert.Equal(t, idr(1050000), wallet.Balance)
+
+	// saldo tetap cocok dengan ledger
+	report, err := NewLedger(db).Reconcile(ctx, RepairNone)
+	assert.Nil(t, err)
+	assert.True(t, report.OK())
+}
+
+func TestConcurrentTopUpRetries(t *testing.T) {
+	ctx := context.Background()
+	db := newConcurrentTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewWalletService(db)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := service.TopUp(ctx, "1", idr(50000), "topup-1")
+			assert.Nil(t, err)
+			assert.Equal(t, idr(1050000), result.Balance)
+		}()
+	}
+	wg.Wait()
+
+	var wallet Wallet
+	assert.Nil(t, db.Take(&wallet, "id = ?", "1").Error)
+	assert.Equal(t, idr(1050000), wallet.Balance)
+}
+
+func TestWalletBalanceAndTransactions(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	loadFixtures(t, db, "users", "wallet_owners", "wallets")
+	service := NewWalletService(db)
+
+	_, err := NewHoldService(db).Hold(ctx, "1", idr(100000), "order-1", time.Hour)
+	assert.Nil(t, err)
+	balance, err := service.Balance(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, WalletBalance{
+		WalletID: "1", UserID: "1", Balance: idr(1000000), Held: idr(100000), Available: idr(900000),
+	}, *balance)
+	_, err = service.Balance(ctx, "404")
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+
+	transfers := NewTransferService(db)
+	for i, to := range []string{"2", "20", "21"} {
+		_, err := transfers.Transfer(ctx, "1", to, idr(int64(i+1)*1000), "trx-"+to)
+		assert.Nil(t, err)
+	}
+
+	// terbaru lebih dulu
+	page, err := service.Transactions(ctx, "1", 2, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(page.Items))
+	assert.True(t, page.HasMore)
+	page, err = service.Transactions(ctx, "1", 2, page.Next)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(page.Items))
+	assert.Equal(t, "2", page.Items[0].CounterpartyWalletID)
+	assert.Equal(t, WalletTransactionDebit, page.Items[0].Type)
+
+	page, err = service.Transactions(ctx, "2", 0, "")
+	assert.Nil(t, err)
+	assert.Equal(t, WalletTransactionCredit, page.Items[0].Type)
+	_, err = service.Transactions(ctx, "404", 0, "")
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+}
@@ -0,0 +1,30 @@
+package learn_golang_gorm
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	WalletTransactionDebit  = "debit"
+	WalletTransactionCredit = "credit"
+)
+
+// WalletTransaction mencatat setiap perubahan saldo wallet (tabel wallet_transactions).
+// Satu transfer menghasilkan 2 baris dengan TransferID yang sama: debit dan credit.
+type WalletTransaction struct {
+	ID                   string    `gorm:"primary_key;column:id;size:100"`
+	TransferID           string    `gorm:"column:transfer_id;size:100"`
+	WalletID             string    `gorm:"column:wallet_id;size:100"`
+	CounterpartyWalletID string    `gorm:"column:counterparty_wallet_id;size:100"`
+	Type                 string    `gorm:"column:type;size:10"`
+	Amount               int64     `gorm:"column:amount"`
+	BalanceAfter         int64     `gorm:"column:balance_after"`
+	IdempotencyKey       string    `gorm:"column:idempotency_key;size:100"`
+	CreatedAt            time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (w *WalletTransaction) BeforeCreate(db *gorm.DB) error {
+	return assignID(w, &w.ID)
+}